@@ -0,0 +1,175 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+)
+
+// maxCacheEntriesLimit 缓存条目列表单页最多返回的条目数
+const maxCacheEntriesLimit = 1000
+
+// cacheExportPath 缓存快照导出接口路径，快照本身已压缩，该接口的响应不再压缩
+const cacheExportPath = "/api/admin/cache/export"
+
+// RevalidationStatusHandler 查看后台链接复检的进度和计数
+func RevalidationStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, model.NewSuccessResponse(service.GetRevalidationService().Status()))
+}
+
+// RevalidationRunHandler 立即执行一轮后台链接复检
+func RevalidationRunHandler(c *gin.Context) {
+	revalidation := service.GetRevalidationService()
+	if !revalidation.Trigger() {
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "复检正在进行中"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(revalidation.Status()))
+}
+
+// WarmupStatusHandler 查看缓存预热的进度和各关键词的预热结果
+func WarmupStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, model.NewSuccessResponse(service.GetWarmupService().Status()))
+}
+
+// WarmupRunHandler 立即执行一轮缓存预热
+func WarmupRunHandler(c *gin.Context) {
+	warmup := service.GetWarmupService()
+	if !warmup.Trigger(searchService) {
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "预热正在进行中"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(warmup.Status()))
+}
+
+// CacheStatsHandler 查看两级缓存、分片磁盘缓存和缓存写入管理器的统计信息
+func CacheStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, model.NewSuccessResponse(service.CacheStats()))
+}
+
+// CacheEntriesHandler 列出搜索缓存条目，支持按关键词、来源类型和插件筛选
+func CacheEntriesHandler(c *gin.Context) {
+	if !service.CacheAvailable() {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, "缓存未启用"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的offset参数"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的limit参数"))
+		return
+	}
+	if limit > maxCacheEntriesLimit {
+		limit = maxCacheEntriesLimit
+	}
+
+	list := service.ListCacheEntries(service.CacheEntryFilter{
+		Keyword:    c.Query("keyword"),
+		SourceType: c.Query("source_type"),
+		Plugin:     c.Query("plugin"),
+		Offset:     offset,
+		Limit:      limit,
+	})
+	c.JSON(http.StatusOK, model.NewSuccessResponse(list))
+}
+
+// CacheEntryHandler 查看解码后的搜索缓存条目
+func CacheEntryHandler(c *gin.Context) {
+	if !service.CacheAvailable() {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, "缓存未启用"))
+		return
+	}
+
+	entry, ok, err := service.GetCacheEntry(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "缓存条目不存在或已过期"))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(entry))
+}
+
+// CacheInvalidateHandler 按缓存键、关键词或插件删除搜索缓存
+func CacheInvalidateHandler(c *gin.Context) {
+	if !service.CacheAvailable() {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, "缓存未启用"))
+		return
+	}
+
+	var req model.CacheInvalidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的请求参数: "+err.Error()))
+		return
+	}
+	if req.Key == "" && strings.TrimSpace(req.Keyword) == "" && req.Plugin == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "key、keyword、plugin至少指定一个"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(service.InvalidateCache(req)))
+}
+
+// CacheExportHandler 以流的方式导出所有未过期搜索缓存的压缩快照
+func CacheExportHandler(c *gin.Context) {
+	if !service.CacheAvailable() {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, "缓存未启用"))
+		return
+	}
+
+	// 导出时长取决于缓存大小，不受服务器写入超时限制
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("pansou-cache-%s.snapshot", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	stats, err := service.ExportCacheSnapshot(c.Writer)
+	if err != nil {
+		// 响应已开始发送，只能中断连接，客户端会收到不完整的快照
+		fmt.Printf("[缓存管理] 导出快照失败: %v\n", err)
+		return
+	}
+	fmt.Printf("[缓存管理] 已导出快照: %d 个缓存项，%d 字节\n", stats.Entries, stats.Bytes)
+}
+
+// CacheImportHandler 从请求体中的快照恢复搜索缓存
+// 快照已压缩，请求体大小不超过磁盘缓存容量（CACHE_MAX_SIZE）
+func CacheImportHandler(c *gin.Context) {
+	if !service.CacheAvailable() {
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(503, "缓存未启用"))
+		return
+	}
+
+	_ = http.NewResponseController(c.Writer).SetReadDeadline(time.Time{})
+
+	maxBytes := int64(config.AppConfig.CacheMaxSizeMB) * 1024 * 1024
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+	stats, err := service.ImportCacheSnapshot(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(413, fmt.Sprintf("快照超过%dMB", config.AppConfig.CacheMaxSizeMB)))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(stats))
+}
@@ -1,24 +1,18 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
-	"sync"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"pansou/config"
 	"pansou/model"
 	"pansou/service"
 )
 
-var (
-	checkService     *service.CheckService
-	checkServiceOnce sync.Once
-)
-
 func getCheckService() *service.CheckService {
-	checkServiceOnce.Do(func() {
-		checkService = service.NewCheckService()
-	})
-	return checkService
+	return service.GetCheckService()
 }
 
 func CheckHandler(c *gin.Context) {
@@ -33,6 +27,79 @@ func CheckHandler(c *gin.Context) {
 		return
 	}
 
+	if maxBatchSize := config.AppConfig.CheckMaxBatchSize; len(req.Items) > maxBatchSize {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, fmt.Sprintf("单次最多检测%d个链接", maxBatchSize)))
+		return
+	}
+
+	if !authorizeViewToken(c, req.ViewToken, req.Items) {
+		return
+	}
+	sanitizeCheckSources(req.Items)
+
 	response := getCheckService().Check(req.Items)
 	c.JSON(http.StatusOK, response)
 }
+
+// sanitizeCheckSources 忽略检测请求中未知的链接来源，只有已注册的插件和默认频道计入按来源的统计
+func sanitizeCheckSources(items []model.CheckItem) {
+	for i := range items {
+		if items[i].Source != "" && !service.KnownCheckSource(items[i].Source) {
+			items[i].Source = ""
+		}
+	}
+}
+
+// ShareInspectHandler 查看分享链接的顶层文件列表
+func ShareInspectHandler(c *gin.Context) {
+	var req model.ShareInspectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的请求: "+err.Error()))
+		return
+	}
+
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "items不能为空"))
+		return
+	}
+
+	if maxBatchSize := config.AppConfig.CheckMaxBatchSize; len(req.Items) > maxBatchSize {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, fmt.Sprintf("单次最多查看%d个链接", maxBatchSize)))
+		return
+	}
+
+	response := getCheckService().Inspect(req.Items)
+	c.JSON(http.StatusOK, response)
+}
+
+// CheckStatsHandler 按网盘类型和链接来源汇总的检测统计
+func CheckStatsHandler(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的days参数"))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的limit参数"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(getCheckService().Stats(days, limit)))
+}
+
+// CheckHistoryHandler 查询单个链接的检测状态变化历史
+func CheckHistoryHandler(c *gin.Context) {
+	item := model.CheckItem{
+		DiskType: c.Query("disk_type"),
+		URL:      c.Query("url"),
+		Password: c.Query("password"),
+	}
+	if item.DiskType == "" || item.URL == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "disk_type和url不能为空"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(getCheckService().History(item)))
+}
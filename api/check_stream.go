@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/service"
+	jsonutil "pansou/util/json"
+)
+
+// checkStreamPath 流式检测接口路径前缀，该前缀下的响应不压缩
+const checkStreamPath = "/api/check/stream"
+
+// checkStreamHeartbeat 没有新结果时发送心跳的间隔，避免连接被代理断开
+const checkStreamHeartbeat = 15 * time.Second
+
+// CheckStreamHandler 创建流式检测任务，每完成一个链接立即返回一条结果，最后返回汇总
+// 默认返回NDJSON，format=sse或Accept为text/event-stream时返回SSE
+func CheckStreamHandler(c *gin.Context) {
+	var req model.CheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的检测请求: "+err.Error()))
+		return
+	}
+
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "items不能为空"))
+		return
+	}
+
+	if maxItems := config.AppConfig.CheckStreamMaxItems; len(req.Items) > maxItems {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, fmt.Sprintf("单个检测任务最多包含%d个链接", maxItems)))
+		return
+	}
+
+	sanitizeCheckSources(req.Items)
+
+	job, err := getCheckService().StartJob(req.Items)
+	if err != nil {
+		c.JSON(http.StatusTooManyRequests, model.NewErrorResponse(429, err.Error()))
+		return
+	}
+	streamCheckJob(c, job, 0)
+}
+
+// CheckStreamResumeHandler 断线重连，从from（已收到的结果数）开始继续返回检测任务的结果
+// SSE客户端自动重连时携带的Last-Event-ID与from含义相同
+func CheckStreamResumeHandler(c *gin.Context) {
+	job, ok := getCheckService().GetJob(c.Param("job_id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "检测任务不存在或已过期"))
+		return
+	}
+
+	fromValue := c.Query("from")
+	if fromValue == "" {
+		fromValue = c.GetHeader("Last-Event-ID")
+	}
+	from := 0
+	if fromValue != "" {
+		var err error
+		from, err = strconv.Atoi(fromValue)
+		if err != nil || from < 0 {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的from参数"))
+			return
+		}
+	}
+
+	streamCheckJob(c, job, from)
+}
+
+// streamCheckJob 先返回任务信息，再逐条返回序号从from开始的结果，任务完成后返回汇总
+// 客户端断开不影响任务继续执行
+func streamCheckJob(c *gin.Context, job *service.CheckJob, from int) {
+	writer := newCheckStreamWriter(c)
+
+	// 流式响应的时长取决于任务大小，不受服务器写入超时限制
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	if err := writer.write("job", 0, job.Info()); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(checkStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		results, done, updated := job.Results(from)
+		for _, result := range results {
+			if err := writer.write("result", result.Seq+1, result); err != nil {
+				return
+			}
+			from = result.Seq + 1
+		}
+		if done {
+			_ = writer.write("summary", 0, job.Summary())
+			return
+		}
+
+		select {
+		case <-updated:
+		case <-heartbeat.C:
+			if err := writer.heartbeat(); err != nil {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// checkStreamLine NDJSON格式的一行事件
+type checkStreamLine struct {
+	Event string `json:"event"`
+	Data  any    `json:"data"`
+}
+
+// checkStreamWriter 按SSE或NDJSON格式写出事件
+type checkStreamWriter struct {
+	c   *gin.Context
+	sse bool
+}
+
+func newCheckStreamWriter(c *gin.Context) *checkStreamWriter {
+	sse := c.Query("format") == "sse" || strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+
+	if sse {
+		c.Header("Content-Type", "text/event-stream; charset=utf-8")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	return &checkStreamWriter{c: c, sse: sse}
+}
+
+// write 写出一个事件并立即发送，id大于0时作为SSE事件ID
+func (w *checkStreamWriter) write(event string, id int, data any) error {
+	var payload []byte
+	var err error
+	if w.sse {
+		payload, err = jsonutil.Marshal(data)
+		if err != nil {
+			return err
+		}
+		var frame strings.Builder
+		frame.WriteString("event: " + event + "\n")
+		if id > 0 {
+			frame.WriteString("id: " + strconv.Itoa(id) + "\n")
+		}
+		frame.WriteString("data: ")
+		frame.Write(payload)
+		frame.WriteString("\n\n")
+		payload = []byte(frame.String())
+	} else {
+		payload, err = jsonutil.Marshal(checkStreamLine{Event: event, Data: data})
+		if err != nil {
+			return err
+		}
+		payload = append(payload, '\n')
+	}
+
+	if _, err := w.c.Writer.Write(payload); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// heartbeat 写出心跳：SSE使用注释行，NDJSON使用ping事件
+func (w *checkStreamWriter) heartbeat() error {
+	if w.sse {
+		if _, err := w.c.Writer.Write([]byte(": ping\n\n")); err != nil {
+			return err
+		}
+		w.c.Writer.Flush()
+		return nil
+	}
+	return w.write("ping", 0, gin.H{"time": time.Now().UnixMilli()})
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"pansou/util/netdisk"
+)
+
+// normalizeCloudTypes 校验cloud_types参数，并统一为网盘注册表中的网盘类型
+// 支持使用网盘类型或显示名称（如"百度网盘"），遇到未注册的类型时返回错误
+func normalizeCloudTypes(cloudTypes []string) ([]string, error) {
+	if len(cloudTypes) == 0 {
+		return cloudTypes, nil
+	}
+
+	normalized := make([]string, 0, len(cloudTypes))
+	seen := make(map[string]bool, len(cloudTypes))
+	for _, cloudType := range cloudTypes {
+		cloudType = strings.TrimSpace(cloudType)
+		if cloudType == "" {
+			continue
+		}
+
+		name := strings.ToLower(cloudType)
+		if name != netdisk.OthersType {
+			provider, ok := netdisk.Get(cloudType)
+			if !ok {
+				return nil, fmt.Errorf("不支持的网盘类型: %s，支持的类型: %s", cloudType, strings.Join(append(netdisk.Names(), netdisk.OthersType), ", "))
+			}
+			name = provider.Name
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+
+	return normalized, nil
+}
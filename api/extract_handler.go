@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/util"
+)
+
+// maxExtractContentSize 单次提取的内容最大长度（字节）
+const maxExtractContentSize = 1 << 20
+
+// maxExtractRequestSize 请求体最大长度（字节），JSON转义会使请求体比内容本身更长
+const maxExtractRequestSize = 2 * maxExtractContentSize
+
+// LinkExtractHandler 从任意文本或HTML中提取网盘链接，可选同时检测链接有效性
+func LinkExtractHandler(c *gin.Context) {
+	// 读取请求体前限制长度，避免超长内容被完整读入内存
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxExtractRequestSize)
+
+	var req model.LinkExtractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(413, "内容过长，最多1MB"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的请求: "+err.Error()))
+		return
+	}
+
+	if len(req.Content) > maxExtractContentSize {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "内容过长，最多1MB"))
+		return
+	}
+
+	title, links := util.ExtractLinksFromContent(req.Content)
+	response := model.LinkExtractResponse{
+		Title: title,
+		Total: len(links),
+		Links: make([]model.ExtractedLink, len(links)),
+	}
+	for i, link := range links {
+		normalized, _ := plugin.NormalizeShareLink(link.URL, link.Password)
+		response.Links[i] = model.ExtractedLink{
+			Type:          link.Type,
+			URL:           link.URL,
+			NormalizedURL: normalized,
+			Password:      link.Password,
+			WorkTitle:     link.WorkTitle,
+		}
+	}
+
+	if req.Check {
+		checkExtractedLinks(response.Links)
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(response))
+}
+
+// checkExtractedLinks 检测提取到的链接，只检测支持的网盘，最多CHECK_MAX_BATCH_SIZE个
+func checkExtractedLinks(links []model.ExtractedLink) {
+	checker := getCheckService()
+
+	var items []model.CheckItem
+	var positions []int
+	for i, link := range links {
+		if len(items) >= config.AppConfig.CheckMaxBatchSize {
+			break
+		}
+		if !checker.Supports(link.Type) {
+			continue
+		}
+		items = append(items, model.CheckItem{DiskType: link.Type, URL: link.URL, Password: link.Password})
+		positions = append(positions, i)
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	response := checker.Check(items)
+	for i, result := range response.Results {
+		result := result
+		links[positions[i]].Check = &result
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	jsonutil "pansou/util/json"
+)
+
+func newTestExtractRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/links/extract", LinkExtractHandler)
+	return router
+}
+
+func TestLinkExtractHandlerCheckManyLinks(t *testing.T) {
+	setupTestLinkCheck(t, "quark")
+
+	var content strings.Builder
+	for i := 0; i < 120; i++ {
+		fmt.Fprintf(&content, "第%d集 https://pan.quark.cn/s/ext%d%04d\n", i, time.Now().UnixNano(), i)
+	}
+	body, _ := jsonutil.Marshal(model.LinkExtractRequest{Content: content.String(), Check: true})
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/links/extract", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		newTestExtractRouter().ServeHTTP(w, req)
+		done <- w
+	}()
+
+	select {
+	case w := <-done:
+		if w.Code != http.StatusOK {
+			t.Fatalf("状态码 = %d，期望 200: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Data model.LinkExtractResponse `json:"data"`
+		}
+		if err := jsonutil.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		checked := 0
+		for _, link := range resp.Data.Links {
+			if link.Check != nil {
+				checked++
+			}
+		}
+		if resp.Data.Total != 120 || checked != config.AppConfig.CheckMaxBatchSize {
+			t.Fatalf("提取 %d 个链接，检测 %d 个，期望提取 120 个，检测 %d 个", resp.Data.Total, checked, config.AppConfig.CheckMaxBatchSize)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("提取并检测120个链接超时未返回")
+	}
+}
+
+func TestLinkExtractHandlerRejectsOversizedBody(t *testing.T) {
+	body := append([]byte(`{"content":"`), bytes.Repeat([]byte("a"), maxExtractRequestSize)...)
+	body = append(body, []byte(`"}`)...)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/links/extract", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	newTestExtractRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("状态码 = %d，期望 413", w.Code)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"pansou/model"
+	"pansou/util"
 	"strings"
 )
 
@@ -11,15 +12,15 @@ func applyResultFilter(response model.SearchResponse, filter *model.FilterConfig
 		return response
 	}
 
-	// 预处理关键词（转小写）
+	// 预处理关键词（标准化大小写、繁简、全半角和标点）
 	includeKeywords := make([]string, len(filter.Include))
 	for i, kw := range filter.Include {
-		includeKeywords[i] = strings.ToLower(kw)
+		includeKeywords[i] = util.NormalizeText(kw)
 	}
 	
 	excludeKeywords := make([]string, len(filter.Exclude))
 	for i, kw := range filter.Exclude {
-		excludeKeywords[i] = strings.ToLower(kw)
+		excludeKeywords[i] = util.NormalizeText(kw)
 	}
 
 	// 根据结果类型决定过滤策略
@@ -113,11 +114,11 @@ func filterResults(results []model.SearchResult, includeKeywords, excludeKeyword
 
 // matchFilter 检查文本是否匹配过滤条件
 func matchFilter(text string, includeKeywords, excludeKeywords []string) bool {
-	lowerText := strings.ToLower(text)
+	normalizedText := util.NormalizeText(text)
 	
-	// 检查 exclude（任一匹配则排除）
+	// 检查 exclude（任一匹配则排除，不使用拼音匹配以免误排除）
 	for _, kw := range excludeKeywords {
-		if strings.Contains(lowerText, kw) {
+		if kw != "" && strings.Contains(normalizedText, kw) {
 			return false
 		}
 	}
@@ -126,7 +127,7 @@ func matchFilter(text string, includeKeywords, excludeKeywords []string) bool {
 	if len(includeKeywords) > 0 {
 		matched := false
 		for _, kw := range includeKeywords {
-			if strings.Contains(lowerText, kw) {
+			if util.ContainsNormalized(normalizedText, kw) {
 				matched = true
 				break
 			}
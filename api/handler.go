@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"pansou/config"
 	"pansou/model"
+	"pansou/plugin"
 	"pansou/service"
 	jsonutil "pansou/util/json"
 	"pansou/util"
@@ -142,6 +143,20 @@ func SearchHandler(c *gin.Context) {
 			}
 		}
 
+		// 处理结构化资源信息参数
+		withMeta := c.Query("meta") == "true"
+		check := c.Query("check")
+		checkFilter := c.Query("check_filter") == "true"
+		debug := c.Query("debug") == "true"
+		metaFilter := model.MetaFilter{
+			MinResolution: util.StringToInt(c.Query("min_resolution")),
+			HDR:           c.Query("hdr") == "true",
+			Codec:         strings.TrimSpace(c.Query("codec")),
+			Year:          util.StringToInt(c.Query("year")),
+			Completed:     c.Query("completed") == "true",
+			Season:        util.StringToInt(c.Query("season")),
+		}
+
 		req = model.SearchRequest{
 			Keyword:      keyword,
 			Channels:     channels,
@@ -153,6 +168,11 @@ func SearchHandler(c *gin.Context) {
 			CloudTypes:   cloudTypes, // 添加cloud_types到请求中
 			Ext:          ext,
 			Filter:       filter,
+			Meta:         withMeta,
+			MetaFilter:   metaFilter,
+			Check:        check,
+			CheckFilter:  checkFilter,
+			Debug:        debug,
 		}
 	} else {
 		// POST方式：从请求体获取
@@ -198,6 +218,20 @@ func SearchHandler(c *gin.Context) {
 		}
 	}
 	
+	// 校验check参数
+	switch req.Check {
+	case "", checkModeNone, checkModeCached, checkModeFresh:
+	default:
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "无效的check参数，支持：none、cached、fresh"))
+		return
+	}
+
+	// 校验cloud_types参数，统一为网盘注册表中的网盘类型
+	if req.CloudTypes, err = normalizeCloudTypes(req.CloudTypes); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	
 	// 可选：启用调试输出（生产环境建议注释掉）
 	// fmt.Printf("🔧 [调试] 搜索参数: keyword=%s, channels=%v, concurrency=%d, refresh=%v, resultType=%s, sourceType=%s, plugins=%v, cloudTypes=%v, ext=%v\n", 
 	//	req.Keyword, req.Channels, req.Concurrency, req.ForceRefresh, req.ResultType, req.SourceType, req.Plugins, req.CloudTypes, req.Ext)
@@ -217,6 +251,20 @@ func SearchHandler(c *gin.Context) {
 		result = applyResultFilter(result, req.Filter, req.ResultType)
 	}
 
+	// 提取结构化资源信息并按资源信息过滤
+	result = applyReleaseMeta(result, req.Meta, &req.MetaFilter, req.ResultType)
+
+	// 标注链接有效性并按检测结果过滤
+	result = applyLinkCheck(result, req.Check, req.CheckFilter, req.ResultType)
+
+	// 启用认证时附带视图令牌，供匿名访客检测本次返回的链接
+	result = attachViewToken(result)
+
+	// 调试模式下附带插件对该关键词的失败退避状态，其中包含插件的原始错误信息，只返回给携带管理令牌的请求
+	if req.Debug && isAdminRequest(c) {
+		result.Debug = &model.SearchDebug{SourceBackoffs: plugin.SourceBackoffs(req.Keyword)}
+	}
+
 	// 包装SearchResponse到标准响应格式中
 	response := model.NewSuccessResponse(result)
 	jsonData, _ := jsonutil.Marshal(response)
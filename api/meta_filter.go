@@ -0,0 +1,111 @@
+package api
+
+import (
+	"strings"
+
+	"pansou/model"
+	"pansou/util"
+)
+
+// applyReleaseMeta 为搜索响应提取结构化资源信息，并按资源信息过滤
+// withMeta为true时将提取结果写入响应；过滤条件不为空时，无法满足条件的结果会被移除
+func applyReleaseMeta(response model.SearchResponse, withMeta bool, filter *model.MetaFilter, resultType string) model.SearchResponse {
+	if !withMeta && filter.IsEmpty() {
+		return response
+	}
+
+	if response.MergedByType != nil {
+		filtered := make(model.MergedLinks)
+		for linkType, links := range response.MergedByType {
+			filteredLinks := make([]model.MergedLink, 0, len(links))
+			for _, link := range links {
+				meta := util.ParseReleaseMeta(link.Note)
+				if !matchMetaFilter(meta, filter) {
+					continue
+				}
+				if withMeta {
+					link.Meta = meta
+				}
+				filteredLinks = append(filteredLinks, link)
+			}
+			if len(filteredLinks) > 0 {
+				filtered[linkType] = filteredLinks
+			}
+		}
+		response.MergedByType = filtered
+	}
+
+	if response.Results != nil {
+		filtered := make([]model.SearchResult, 0, len(response.Results))
+		for _, result := range response.Results {
+			// 标题信息不全时（如大小、集数只出现在正文中），结合正文一起解析
+			meta := util.ParseReleaseMeta(result.Title + "\n" + result.Content)
+			if !matchMetaFilter(meta, filter) {
+				continue
+			}
+			if withMeta {
+				result.Meta = meta
+			}
+			filtered = append(filtered, result)
+		}
+		response.Results = filtered
+	}
+
+	// 与applyResultFilter保持一致，按结果类型重新计算total
+	if !filter.IsEmpty() {
+		if resultType == "merged_by_type" || resultType == "" {
+			total := 0
+			for _, links := range response.MergedByType {
+				total += len(links)
+			}
+			response.Total = total
+		} else {
+			response.Total = len(response.Results)
+		}
+	}
+
+	return response
+}
+
+// matchMetaFilter 检查资源信息是否满足过滤条件
+// 设置了某项条件而资源信息中缺少该项时，视为不满足
+func matchMetaFilter(meta *model.ReleaseMeta, filter *model.MetaFilter) bool {
+	if filter.IsEmpty() {
+		return true
+	}
+	if meta == nil {
+		return false
+	}
+
+	if filter.MinResolution > 0 && meta.Resolution < filter.MinResolution {
+		return false
+	}
+	if filter.HDR && !meta.HDR && !meta.DolbyVision {
+		return false
+	}
+	if filter.Codec != "" && !strings.EqualFold(normalizeCodec(filter.Codec), meta.Codec) {
+		return false
+	}
+	if filter.Year > 0 && meta.Year != filter.Year {
+		return false
+	}
+	if filter.Completed && !meta.Completed {
+		return false
+	}
+	if filter.Season > 0 && (meta.SeasonStart == 0 || filter.Season < meta.SeasonStart || filter.Season > meta.SeasonEnd) {
+		return false
+	}
+
+	return true
+}
+
+// normalizeCodec 将请求中的编码别名统一为解析器输出的写法
+func normalizeCodec(codec string) string {
+	switch strings.ToLower(strings.ReplaceAll(codec, ".", "")) {
+	case "h265", "x265", "hevc":
+		return "H265"
+	case "h264", "x264", "avc":
+		return "H264"
+	}
+	return strings.ToUpper(codec)
+}
@@ -1,14 +1,26 @@
 package api
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"pansou/config"
+	"pansou/model"
 	"pansou/util"
+	"pansou/util/peer"
+)
+
+const (
+	// adminPathPrefix 管理接口路径前缀，由AdminMiddleware校验管理令牌
+	adminPathPrefix = "/api/admin/"
+
+	// AdminTokenHeader 管理接口请求携带管理令牌的请求头
+	AdminTokenHeader = "X-Admin-Token"
 )
 
 // CORSMiddleware 跨域中间件
@@ -16,7 +28,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Admin-Token")
 		
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -92,6 +104,17 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		// 检查当前路径是否是公开接口
 		path := c.Request.URL.Path
+
+		// 配置了实例令牌时，实例组接口由接口自身校验令牌
+		if path == peer.FillPath && config.AppConfig.CachePeerToken != "" {
+			c.Next()
+			return
+		}
+		// 管理接口由AdminMiddleware校验管理令牌
+		if strings.HasPrefix(path, adminPathPrefix) {
+			c.Next()
+			return
+		}
 		for _, p := range publicPaths {
 			if strings.HasPrefix(path, p) {
 				c.Next()
@@ -101,6 +124,12 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		// 获取Authorization头
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && viewTokenPaths[path] {
+			// 未携带JWT时交给接口校验视图令牌
+			c.Set(viewTokenOnlyKey, true)
+			c.Next()
+			return
+		}
 		if authHeader == "" {
 			c.JSON(401, gin.H{
 				"error": "未授权：缺少认证令牌",
@@ -138,4 +167,34 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("username", claims.Username)
 		c.Next()
 	}
-} 
\ No newline at end of file
+} 
+
+// isAdminRequest 请求是否携带有效的ADMIN_TOKEN管理令牌，未设置ADMIN_TOKEN时总是false
+func isAdminRequest(c *gin.Context) bool {
+	token := config.AppConfig.AdminToken
+	if token == "" {
+		return false
+	}
+	provided := c.GetHeader(AdminTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
+
+// AdminMiddleware 管理接口认证中间件
+// 管理接口只接受ADMIN_TOKEN管理令牌，无论是否启用AUTH_ENABLED都需要校验；未设置ADMIN_TOKEN时管理接口不可用
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.AppConfig.AdminToken == "" {
+			c.JSON(http.StatusForbidden, model.NewErrorResponse(http.StatusForbidden, "管理接口未启用：未设置ADMIN_TOKEN"))
+			c.Abort()
+			return
+		}
+
+		if !isAdminRequest(c) {
+			c.JSON(http.StatusUnauthorized, model.NewErrorResponse(http.StatusUnauthorized, "管理令牌无效"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
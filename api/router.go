@@ -6,6 +6,7 @@ import (
 	"pansou/plugin"
 	"pansou/service"
 	"pansou/util"
+	"pansou/util/peer"
 )
 
 // SetupRouter 设置路由
@@ -22,7 +23,7 @@ func SetupRouter(searchService *service.SearchService) *gin.Engine {
 	// 添加中间件
 	r.Use(CORSMiddleware())
 	r.Use(LoggerMiddleware())
-	r.Use(util.GzipMiddleware()) // 添加压缩中间件
+	r.Use(util.GzipMiddleware(checkStreamPath, cacheExportPath)) // 添加压缩中间件，流式检测和缓存导出接口不压缩
 	r.Use(AuthMiddleware())      // 添加认证中间件
 	
 	// 定义API路由组
@@ -40,6 +41,27 @@ func SetupRouter(searchService *service.SearchService) *gin.Engine {
 		api.POST("/search", SearchHandler)
 		api.GET("/search", SearchHandler) // 添加GET方式支持
 		api.POST("/check/links", CheckHandler)
+		api.POST("/check/stream", CheckStreamHandler)
+		api.GET("/check/stream/:job_id", CheckStreamResumeHandler)
+		api.GET("/check/stats", CheckStatsHandler)
+		api.GET("/check/history", CheckHistoryHandler)
+		api.POST("/share/inspect", ShareInspectHandler)
+		api.POST("/links/extract", LinkExtractHandler)
+
+		// 管理接口，需要携带ADMIN_TOKEN管理令牌
+		admin := api.Group("/admin", AdminMiddleware())
+		{
+			admin.GET("/revalidation", RevalidationStatusHandler)
+			admin.POST("/revalidation/run", RevalidationRunHandler)
+			admin.GET("/warmup", WarmupStatusHandler)
+			admin.POST("/warmup/run", WarmupRunHandler)
+			admin.GET("/cache/stats", CacheStatsHandler)
+			admin.GET("/cache/entries", CacheEntriesHandler)
+			admin.GET("/cache/entries/:key", CacheEntryHandler)
+			admin.POST("/cache/invalidate", CacheInvalidateHandler)
+			admin.GET("/cache/export", CacheExportHandler)
+			admin.POST("/cache/import", CacheImportHandler)
+		}
 		
 		// 健康检查接口
 		api.GET("/health", func(c *gin.Context) {
@@ -73,11 +95,27 @@ func SetupRouter(searchService *service.SearchService) *gin.Engine {
 				response["plugin_count"] = pluginCount
 				response["plugins"] = pluginNames
 			}
+
+			// 启用缓存预热时返回预热进度
+			if config.AppConfig.WarmupEnabled {
+				warmup := service.GetWarmupService().Status()
+				response["warmup"] = gin.H{
+					"running":          warmup.Running,
+					"rounds":           warmup.Rounds,
+					"last_finished_at": warmup.LastFinishedAt,
+					"progress":         warmup.Progress,
+				}
+			}
 			
 			c.JSON(200, response)
 		})
 	}
 	
+	// 实例组内其他实例请求搜索结果的接口，由实例令牌校验
+	if group := service.GetPeerGroup(); group != nil {
+		r.POST(peer.FillPath, gin.WrapH(group))
+	}
+	
 	// 注册插件的Web路由（如果插件实现了PluginWithWebHandler接口）
 	// 只有当插件功能启用且插件在启用列表中时才注册路由
 	if config.AppConfig.AsyncPluginEnabled && searchService != nil && searchService.GetPluginManager() != nil {
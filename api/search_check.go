@@ -0,0 +1,125 @@
+package api
+
+import (
+	"sort"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+)
+
+// 搜索结果的链接检测模式
+const (
+	checkModeNone   = "none"   // 不标注
+	checkModeCached = "cached" // 仅使用检测缓存，不发起网盘请求
+	checkModeFresh  = "fresh"  // 未命中缓存的链接实时检测
+)
+
+// 检测状态
+const (
+	checkStateBad       = plugin.CheckStateBad
+	checkStateUncertain = plugin.CheckStateUncertain
+)
+
+// linkCheckRef 待检测链接在响应中的位置
+type linkCheckRef struct {
+	linkType string
+	index    int
+}
+
+// applyLinkCheck 为按类型合并的链接标注检测状态
+// cached模式只读取检测缓存；fresh模式对未缓存的链接实时检测，单次最多检测CHECK_MAX_BATCH_SIZE个，其余仍只读取缓存。
+// checkFilter为true时移除失效链接，并将状态不确定的链接排到同类型列表的最后
+func applyLinkCheck(response model.SearchResponse, mode string, checkFilter bool, resultType string) model.SearchResponse {
+	if mode == "" || mode == checkModeNone || len(response.MergedByType) == 0 {
+		return response
+	}
+
+	checker := getCheckService()
+	annotated := make(model.MergedLinks, len(response.MergedByType))
+	pendingByType := make(map[string][]int) // 网盘类型 -> 未命中缓存的链接下标
+	linkTypes := make([]string, 0, len(response.MergedByType))
+
+	for linkType, links := range response.MergedByType {
+		linkTypes = append(linkTypes, linkType)
+		annotatedLinks := make([]model.MergedLink, len(links))
+		copy(annotatedLinks, links)
+		annotated[linkType] = annotatedLinks
+
+		if !checker.Supports(linkType) {
+			continue
+		}
+		for i, link := range annotatedLinks {
+			item := model.CheckItem{DiskType: linkType, URL: link.URL, Password: link.Password, Source: link.Source}
+			if result, ok := checker.Lookup(item); ok {
+				annotatedLinks[i].CheckState = result.State
+				annotatedLinks[i].CheckedAt = result.CheckedAt
+			} else if mode == checkModeFresh {
+				pendingByType[linkType] = append(pendingByType[linkType], i)
+			}
+		}
+	}
+
+	if len(pendingByType) > 0 {
+		// 各类型轮流取链接，数量超过上限时优先检测每种类型排在前面的链接
+		sort.Strings(linkTypes)
+		maxBatchSize := config.AppConfig.CheckMaxBatchSize
+		var pending []model.CheckItem
+		var pendingRefs []linkCheckRef
+		for rank := 0; len(pending) < maxBatchSize; rank++ {
+			added := false
+			for _, linkType := range linkTypes {
+				indexes := pendingByType[linkType]
+				if rank >= len(indexes) || len(pending) >= maxBatchSize {
+					continue
+				}
+				link := annotated[linkType][indexes[rank]]
+				pending = append(pending, model.CheckItem{DiskType: linkType, URL: link.URL, Password: link.Password, Source: link.Source})
+				pendingRefs = append(pendingRefs, linkCheckRef{linkType: linkType, index: indexes[rank]})
+				added = true
+			}
+			if !added {
+				break
+			}
+		}
+
+		checked := checker.Check(pending)
+		for i, result := range checked.Results {
+			ref := pendingRefs[i]
+			annotated[ref.linkType][ref.index].CheckState = result.State
+			annotated[ref.linkType][ref.index].CheckedAt = result.CheckedAt
+		}
+	}
+
+	if checkFilter {
+		for linkType, links := range annotated {
+			filtered := make([]model.MergedLink, 0, len(links))
+			for _, link := range links {
+				if link.CheckState != checkStateBad {
+					filtered = append(filtered, link)
+				}
+			}
+			// 稳定排序，其余链接保持原有顺序
+			sort.SliceStable(filtered, func(i, j int) bool {
+				return filtered[i].CheckState != checkStateUncertain && filtered[j].CheckState == checkStateUncertain
+			})
+
+			if len(filtered) > 0 {
+				annotated[linkType] = filtered
+			} else {
+				delete(annotated, linkType)
+			}
+		}
+
+		if resultType == "merged_by_type" || resultType == "" {
+			total := 0
+			for _, links := range annotated {
+				total += len(links)
+			}
+			response.Total = total
+		}
+	}
+
+	response.MergedByType = annotated
+	return response
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+)
+
+// testLinkChecker 直接判定链接有效的检测器，不发起网盘请求
+type testLinkChecker struct {
+	name string
+}
+
+func (c testLinkChecker) Name() string { return c.name }
+
+func (testLinkChecker) Normalize(rawURL, password string) (string, string) {
+	return rawURL, password
+}
+
+func (testLinkChecker) Check(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
+	return plugin.CheckStateOK, "", nil
+}
+
+// setupTestLinkCheck 使用不限速的配置，并为diskTypes注册测试检测器
+func setupTestLinkCheck(t *testing.T, diskTypes ...string) {
+	t.Helper()
+	// 检测服务的持久化缓存写在当前目录下
+	t.Chdir(t.TempDir())
+	t.Setenv("CHECK_PROVIDER_RATE", "0")
+	config.Init()
+	for _, diskType := range diskTypes {
+		plugin.RegisterLinkChecker(testLinkChecker{name: diskType})
+	}
+}
+
+func TestApplyLinkCheckFreshMaxBatchSize(t *testing.T) {
+	setupTestLinkCheck(t, "testdisk")
+
+	count := config.AppConfig.CheckMaxBatchSize
+	links := make([]model.MergedLink, count)
+	for i := range links {
+		links[i] = model.MergedLink{URL: fmt.Sprintf("https://testdisk.example.com/s/%d-%d", time.Now().UnixNano(), i)}
+	}
+	response := model.SearchResponse{Total: count, MergedByType: model.MergedLinks{"testdisk": links}}
+
+	done := make(chan model.SearchResponse, 1)
+	go func() { done <- applyLinkCheck(response, checkModeFresh, false, "merged_by_type") }()
+
+	select {
+	case checked := <-done:
+		for i, link := range checked.MergedByType["testdisk"] {
+			if link.CheckState != plugin.CheckStateOK {
+				t.Fatalf("第%d个链接的检测状态 = %q，期望 %q", i, link.CheckState, plugin.CheckStateOK)
+			}
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("实时检测 %d 个链接超时未返回", count)
+	}
+}
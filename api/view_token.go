@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"pansou/config"
+	"pansou/model"
+	"pansou/util"
+)
+
+// viewTokenOnlyKey 请求未携带JWT、只能凭视图令牌访问时，认证中间件在上下文中设置的标记
+const viewTokenOnlyKey = "view_token_only"
+
+// viewTokenPaths 未携带JWT时允许凭视图令牌访问的接口
+var viewTokenPaths = map[string]bool{
+	"/api/check/links": true,
+}
+
+// attachViewToken 启用认证时为搜索响应生成视图令牌，令牌绑定响应中所有支持检测的链接
+func attachViewToken(result model.SearchResponse) model.SearchResponse {
+	if !config.AppConfig.AuthEnabled {
+		return result
+	}
+
+	checker := getCheckService()
+	var links []util.ViewTokenLink
+	for _, item := range result.Results {
+		for _, link := range item.Links {
+			if checker.Supports(link.Type) {
+				links = append(links, util.ViewTokenLink{URL: link.URL, Password: link.Password})
+			}
+		}
+	}
+	for linkType, merged := range result.MergedByType {
+		if !checker.Supports(linkType) {
+			continue
+		}
+		for _, link := range merged {
+			links = append(links, util.ViewTokenLink{URL: link.URL, Password: link.Password})
+		}
+	}
+	if len(links) == 0 {
+		return result
+	}
+
+	token, expiresAt, err := util.GenerateViewToken(links, config.AppConfig.AuthJWTSecret, config.AppConfig.ViewTokenTTL)
+	if err != nil {
+		return result
+	}
+	result.ViewToken = token
+	result.ViewTokenExpiresAt = expiresAt.UnixMilli()
+	return result
+}
+
+// authorizeViewToken 校验只凭视图令牌访问的检测请求，所有链接和提取码都必须属于令牌绑定的链接集合
+// 校验失败时写入错误响应并返回false
+func authorizeViewToken(c *gin.Context, viewToken string, items []model.CheckItem) bool {
+	if !c.GetBool(viewTokenOnlyKey) {
+		return true
+	}
+
+	if viewToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "未授权：缺少认证令牌",
+			"code":  "AUTH_TOKEN_MISSING",
+		})
+		return false
+	}
+
+	token, err := util.ParseViewToken(viewToken, config.AppConfig.AuthJWTSecret)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "未授权：视图令牌无效或已过期",
+			"code":  "VIEW_TOKEN_INVALID",
+		})
+		return false
+	}
+
+	for _, item := range items {
+		if !token.Allows(item.URL, item.Password) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "禁止访问：链接不属于视图令牌对应的搜索结果",
+				"code":  "VIEW_TOKEN_LINK_DENIED",
+			})
+			return false
+		}
+	}
+	return true
+}
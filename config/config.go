@@ -20,10 +20,23 @@ type Config struct {
 	HTTPProxyURL       string
 	HTTPSProxyURL      string
 	// 缓存相关配置
-	CacheEnabled    bool
-	CachePath       string
-	CacheMaxSizeMB  int
-	CacheTTLMinutes int
+	CacheEnabled         bool
+	CachePath            string
+	CacheMaxSizeMB       int
+	CacheTTLMinutes      int
+	CacheSoftTTLMinutes  int            // TG频道缓存的软过期时间，超过后返回缓存并在后台刷新
+	CacheBackend         string         // 二级缓存存储后端：file（每项一个文件）、bolt（单个bbolt文件）或redis（多实例共享）
+	CacheCompactInterval time.Duration  // bbolt缓存文件压缩检查间隔
+	CacheTTLPolicy       CacheTTLPolicy // 按来源、空结果和热门关键词的缓存有效期策略
+	CacheRedisAddr       string         // redis后端的服务地址，兼容任何Redis协议服务
+	CacheRedisPassword   string         // redis后端的密码
+	CacheRedisDB         int            // redis后端的数据库编号
+	CacheRedisPrefix     string         // redis后端的缓存键前缀，共享同一服务的实例需使用相同前缀
+	CachePeers           []string       // 实例组所有实例的地址，缓存键按一致性哈希分配给其中一个实例
+	CachePeerSelf        string         // 本实例在实例组中的地址
+	CachePeerToken       string         // 实例之间请求使用的令牌
+	CachePeerTimeout     time.Duration  // 等待所属实例返回搜索结果的超时时间
+	CachePeerLocalTTL    time.Duration  // 来自所属实例的结果在本实例内存缓存中保留的时长，0表示不保留
 	// 压缩相关配置
 	EnableCompression bool
 	MinSizeToCompress int // 最小压缩大小（字节）
@@ -31,8 +44,10 @@ type Config struct {
 	GCPercent      int  // GC触发阈值百分比
 	OptimizeMemory bool // 是否启用内存优化
 	// 插件相关配置
-	PluginTimeoutSeconds int           // 插件超时时间（秒）
-	PluginTimeout        time.Duration // 插件超时时间（Duration）
+	PluginTimeoutSeconds    int           // 插件超时时间（秒）
+	PluginTimeout           time.Duration // 插件超时时间（Duration）
+	PluginFailureBackoff    time.Duration // 插件对关键词搜索失败后首次暂停请求的时长，连续失败时翻倍，0表示不启用
+	PluginFailureBackoffMax time.Duration // 插件搜索失败暂停请求时长的上限
 	// 异步插件相关配置
 	AsyncPluginEnabled        bool          // 是否启用异步插件
 	EnabledPlugins            []string      // 启用的具体插件列表（空表示启用所有）
@@ -52,12 +67,105 @@ type Config struct {
 	AuthUsers       map[string]string // 用户名:密码映射
 	AuthTokenExpiry time.Duration     // Token有效期
 	AuthJWTSecret   string            // JWT签名密钥
+	ViewTokenTTL    time.Duration     // 搜索响应中视图令牌的有效期
+	AdminToken      string            // 管理接口令牌，与AUTH_ENABLED无关，未设置时管理接口不可用
+	// 关键词匹配相关配置
+	PinyinMatchEnabled bool // 是否启用拼音首字母匹配
+	// 网盘注册表相关配置
+	NetdiskProvidersFile string // 自定义网盘定义文件路径（JSON），为空时只使用内置网盘
+	// 链接检测相关配置
+	CheckMaxBatchSize        int                // 单次检测请求最多包含的链接数
+	CheckConcurrency         int                // 单次检测请求的并发数
+	CheckProviderConcurrency map[string]int     // 按网盘类型限制同时进行的检测数，键"*"为默认值
+	CheckProviderRate        map[string]float64 // 按网盘类型限制每秒发起的检测数，键"*"为默认值，0表示不限制
+	CheckStreamMaxItems      int                // 流式检测任务最多包含的链接数
+	CheckJobRetention        time.Duration      // 流式检测任务完成后保留结果的时长，期间可断线重连
+	CheckMaxActiveJobs       int                // 同时执行的流式检测任务数上限
+	CheckMaxJobs             int                // 保留的流式检测任务数上限（包括执行中的任务）
+	// 后台链接复检相关配置
+	RevalidateEnabled   bool          // 是否定期复检热门搜索缓存中的链接
+	RevalidateInterval  time.Duration // 复检间隔
+	RevalidateTopKeys   int           // 每轮复检访问最多的缓存条目数
+	RevalidateMaxChecks int           // 每轮最多实际发起的检测数（命中检测缓存的不计入）
+	// 缓存预热相关配置
+	WarmupEnabled      bool          // 是否在启动时和定期预先执行搜索
+	WarmupKeywordsFile string        // 预热关键词文件路径，每行一个关键词
+	WarmupTopKeywords  int           // 每轮额外预热访问次数最多的关键词数
+	WarmupInterval     time.Duration // 预热间隔
+	WarmupConcurrency  int           // 预热搜索与进行中的用户搜索共享的并发预算
 
 }
 
 // 全局配置实例
 var AppConfig *Config
 
+// CacheTTLPolicy 搜索缓存的有效期策略
+// 优先级：空结果 > 频道/插件 > 热门关键词 > 来源类型 > CACHE_TTL，一个缓存条目包含多个频道或插件时取其中最短的有效期
+// 搜索全部频道或插件时，非热门关键词的有效期不超过所有单独配置中最短的，热门关键词不受此限制
+type CacheTTLPolicy struct {
+	SourceTypes map[string]time.Duration // 来源类型（tg、plugin）的有效期
+	Channels    map[string]time.Duration // TG频道的有效期，键为小写频道名
+	Plugins     map[string]time.Duration // 插件的有效期，键为小写插件名，同时用作该插件API响应缓存的有效期
+	EmptyTTL    time.Duration            // 空结果的有效期，0表示不单独设置
+	HotAccesses int64                    // 访问次数达到该值的缓存条目视为热门关键词，0表示不启用
+	HotTTL      time.Duration            // 热门关键词的有效期
+}
+
+// PluginTTL 返回插件单独配置的有效期
+func (p CacheTTLPolicy) PluginTTL(name string) (time.Duration, bool) {
+	ttl, ok := p.Plugins[strings.ToLower(name)]
+	return ttl, ok
+}
+
+// Resolve 计算搜索缓存条目的有效期
+// sourceType为tg或plugin，sources为条目包含的频道或插件（为空表示全部），accesses为条目的访问次数，没有匹配的策略时返回fallback
+func (p CacheTTLPolicy) Resolve(sourceType string, sources []string, resultCount int, accesses int64, fallback time.Duration) time.Duration {
+	if resultCount == 0 && p.EmptyTTL > 0 {
+		return p.EmptyTTL
+	}
+
+	base := fallback
+	if ttl, ok := p.SourceTypes[sourceType]; ok {
+		base = ttl
+	}
+	hot := p.HotAccesses > 0 && accesses >= p.HotAccesses
+	if hot {
+		base = p.HotTTL
+	}
+
+	var rules map[string]time.Duration
+	switch sourceType {
+	case "tg":
+		rules = p.Channels
+	case "plugin":
+		rules = p.Plugins
+	}
+	if len(sources) == 0 {
+		// 未指定频道或插件时搜索全部，取所有单独配置中更短的有效期；热门关键词优先于这一限制
+		if hot {
+			return base
+		}
+		for _, ttl := range rules {
+			if ttl < base {
+				base = ttl
+			}
+		}
+		return base
+	}
+
+	var result time.Duration
+	for i, source := range sources {
+		ttl, ok := rules[strings.ToLower(source)]
+		if !ok {
+			ttl = base
+		}
+		if i == 0 || ttl < result {
+			result = ttl
+		}
+	}
+	return result
+}
+
 // 初始化配置
 func Init() {
 	proxyURL := getProxyURL()
@@ -73,10 +181,23 @@ func Init() {
 		HTTPProxyURL:       getHTTPProxyURL(),
 		HTTPSProxyURL:      getHTTPSProxyURL(),
 		// 缓存相关配置
-		CacheEnabled:    getCacheEnabled(),
-		CachePath:       getCachePath(),
-		CacheMaxSizeMB:  getCacheMaxSize(),
-		CacheTTLMinutes: getCacheTTL(),
+		CacheEnabled:         getCacheEnabled(),
+		CachePath:            getCachePath(),
+		CacheMaxSizeMB:       getCacheMaxSize(),
+		CacheTTLMinutes:      getCacheTTL(),
+		CacheSoftTTLMinutes:  getCacheSoftTTL(),
+		CacheBackend:         getCacheBackend(),
+		CacheCompactInterval: getCacheCompactInterval(),
+		CacheTTLPolicy:       getCacheTTLPolicy(),
+		CacheRedisAddr:       getCacheRedisAddr(),
+		CacheRedisPassword:   os.Getenv("CACHE_REDIS_PASSWORD"),
+		CacheRedisDB:         getCacheRedisDB(),
+		CacheRedisPrefix:     getCacheRedisPrefix(),
+		CachePeers:           getCachePeers(),
+		CachePeerSelf:        strings.TrimSpace(os.Getenv("CACHE_PEER_SELF")),
+		CachePeerToken:       os.Getenv("CACHE_PEER_TOKEN"),
+		CachePeerTimeout:     getCachePeerTimeout(pluginTimeoutSeconds),
+		CachePeerLocalTTL:    getCachePeerLocalTTL(),
 		// 压缩相关配置
 		EnableCompression: getEnableCompression(),
 		MinSizeToCompress: getMinSizeToCompress(),
@@ -84,8 +205,10 @@ func Init() {
 		GCPercent:      getGCPercent(),
 		OptimizeMemory: getOptimizeMemory(),
 		// 插件相关配置
-		PluginTimeoutSeconds: pluginTimeoutSeconds,
-		PluginTimeout:        time.Duration(pluginTimeoutSeconds) * time.Second,
+		PluginTimeoutSeconds:    pluginTimeoutSeconds,
+		PluginTimeout:           time.Duration(pluginTimeoutSeconds) * time.Second,
+		PluginFailureBackoff:    getPluginFailureBackoff(),
+		PluginFailureBackoffMax: getPluginFailureBackoffMax(),
 		// 异步插件相关配置
 		AsyncPluginEnabled:        getAsyncPluginEnabled(),
 		EnabledPlugins:            getEnabledPlugins(),
@@ -105,6 +228,32 @@ func Init() {
 		AuthUsers:       getAuthUsers(),
 		AuthTokenExpiry: getAuthTokenExpiry(),
 		AuthJWTSecret:   getAuthJWTSecret(),
+		ViewTokenTTL:    getViewTokenTTL(),
+		AdminToken:      os.Getenv("ADMIN_TOKEN"),
+		// 关键词匹配相关配置
+		PinyinMatchEnabled: getPinyinMatchEnabled(),
+		// 网盘注册表相关配置
+		NetdiskProvidersFile: getNetdiskProvidersFile(),
+		// 链接检测相关配置
+		CheckMaxBatchSize:        getCheckMaxBatchSize(),
+		CheckConcurrency:         getCheckConcurrency(),
+		CheckProviderConcurrency: getCheckProviderConcurrency(),
+		CheckProviderRate:        getCheckProviderRate(),
+		CheckStreamMaxItems:      getCheckStreamMaxItems(),
+		CheckJobRetention:        getCheckJobRetention(),
+		CheckMaxActiveJobs:       getCheckMaxActiveJobs(),
+		CheckMaxJobs:             getCheckMaxJobs(),
+		// 后台链接复检相关配置
+		RevalidateEnabled:   getRevalidateEnabled(),
+		RevalidateInterval:  getRevalidateInterval(),
+		RevalidateTopKeys:   getRevalidateTopKeys(),
+		RevalidateMaxChecks: getRevalidateMaxChecks(),
+		// 缓存预热相关配置
+		WarmupEnabled:      getWarmupEnabled(),
+		WarmupKeywordsFile: getWarmupKeywordsFile(),
+		WarmupTopKeywords:  getWarmupTopKeywords(),
+		WarmupInterval:     getWarmupInterval(),
+		WarmupConcurrency:  getWarmupConcurrency(),
 
 	}
 	
@@ -261,6 +410,150 @@ func getCacheTTL() int {
 	return ttl
 }
 
+// 从环境变量获取TG频道缓存软过期时间(分钟)，默认为缓存有效期的一半，不超过缓存有效期
+func getCacheSoftTTL() int {
+	hardTTL := getCacheTTL()
+	defaultTTL := hardTTL / 2
+	if defaultTTL < 1 {
+		defaultTTL = 1
+	}
+
+	ttlEnv := os.Getenv("CACHE_SOFT_TTL")
+	if ttlEnv == "" {
+		return defaultTTL
+	}
+	ttl, err := strconv.Atoi(ttlEnv)
+	if err != nil || ttl <= 0 {
+		return defaultTTL
+	}
+	if ttl > hardTTL {
+		return hardTTL
+	}
+	return ttl
+}
+
+// 从环境变量获取二级缓存存储后端，默认file
+func getCacheBackend() string {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("CACHE_BACKEND")))
+	if backend == "bolt" || backend == "redis" {
+		return backend
+	}
+	return "file"
+}
+
+// 从环境变量获取redis后端的服务地址，默认127.0.0.1:6379
+func getCacheRedisAddr() string {
+	addr := strings.TrimSpace(os.Getenv("CACHE_REDIS_ADDR"))
+	if addr == "" {
+		return "127.0.0.1:6379"
+	}
+	return addr
+}
+
+// 从环境变量获取redis后端的数据库编号，默认0
+func getCacheRedisDB() int {
+	db, err := strconv.Atoi(os.Getenv("CACHE_REDIS_DB"))
+	if err != nil || db < 0 {
+		return 0
+	}
+	return db
+}
+
+// 从环境变量获取实例组所有实例的地址，逗号分隔
+func getCachePeers() []string {
+	var peers []string
+	for _, peer := range strings.Split(os.Getenv("CACHE_PEERS"), ",") {
+		if peer = strings.TrimSpace(peer); peer != "" {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// 从环境变量获取等待所属实例返回搜索结果的超时时间(秒)，默认为插件超时时间加5秒
+func getCachePeerTimeout(pluginTimeoutSeconds int) time.Duration {
+	timeout, err := strconv.Atoi(os.Getenv("CACHE_PEER_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		timeout = pluginTimeoutSeconds + 5
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// 从环境变量获取来自所属实例的结果在本实例内存缓存中保留的时长（秒），默认60秒，0表示不保留
+func getCachePeerLocalTTL() time.Duration {
+	ttlEnv := os.Getenv("CACHE_PEER_LOCAL_TTL")
+	if ttlEnv == "" {
+		return 60 * time.Second
+	}
+	ttl, err := strconv.Atoi(ttlEnv)
+	if err != nil || ttl < 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// 从环境变量获取redis后端的缓存键前缀，默认pansou:
+func getCacheRedisPrefix() string {
+	if prefix, ok := os.LookupEnv("CACHE_REDIS_PREFIX"); ok {
+		return prefix
+	}
+	return "pansou:"
+}
+
+// 从环境变量获取bbolt缓存文件压缩检查间隔(分钟)，默认360分钟，设置为0禁用压缩
+func getCacheCompactInterval() time.Duration {
+	intervalEnv := os.Getenv("CACHE_COMPACT_INTERVAL")
+	if intervalEnv == "" {
+		return 360 * time.Minute
+	}
+	interval, err := strconv.Atoi(intervalEnv)
+	if err != nil || interval < 0 {
+		return 360 * time.Minute
+	}
+	return time.Duration(interval) * time.Minute
+}
+
+// 从环境变量获取缓存有效期策略（分钟），格式：来源类型:分钟,channel:频道名:分钟,plugin:插件名:分钟,empty:分钟,hot:访问次数:分钟
+// 例如 tg:30,channel:dailydrama:5,plugin:javdb:1440,empty:2,hot:50:180，无效的项直接忽略
+func getCacheTTLPolicy() CacheTTLPolicy {
+	policy := CacheTTLPolicy{
+		SourceTypes: make(map[string]time.Duration),
+		Channels:    make(map[string]time.Duration),
+		Plugins:     make(map[string]time.Duration),
+	}
+
+	for _, part := range strings.Split(os.Getenv("CACHE_TTL_POLICY"), ",") {
+		fields := strings.Split(part, ":")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		minutes, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil || minutes <= 0 {
+			continue
+		}
+		ttl := time.Duration(minutes) * time.Minute
+
+		kind := strings.ToLower(fields[0])
+		switch {
+		case len(fields) == 2 && (kind == "tg" || kind == "plugin"):
+			policy.SourceTypes[kind] = ttl
+		case len(fields) == 2 && kind == "empty":
+			policy.EmptyTTL = ttl
+		case len(fields) == 3 && kind == "channel" && fields[1] != "":
+			policy.Channels[strings.ToLower(fields[1])] = ttl
+		case len(fields) == 3 && kind == "plugin" && fields[1] != "":
+			policy.Plugins[strings.ToLower(fields[1])] = ttl
+		case len(fields) == 3 && kind == "hot":
+			accesses, err := strconv.ParseInt(fields[1], 10, 64)
+			if err == nil && accesses > 0 {
+				policy.HotAccesses = accesses
+				policy.HotTTL = ttl
+			}
+		}
+	}
+	return policy
+}
+
 // 从环境变量获取是否启用压缩，如果未设置则默认禁用
 func getEnableCompression() bool {
 	enabled := os.Getenv("ENABLE_COMPRESSION")
@@ -318,6 +611,32 @@ func getPluginTimeout() int {
 	return timeout
 }
 
+// 从环境变量获取插件搜索失败后首次暂停请求的时长（秒），默认5秒，设置为0不启用
+func getPluginFailureBackoff() time.Duration {
+	backoffEnv := os.Getenv("PLUGIN_FAILURE_BACKOFF")
+	if backoffEnv == "" {
+		return 5 * time.Second
+	}
+	backoff, err := strconv.Atoi(backoffEnv)
+	if err != nil || backoff < 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(backoff) * time.Second
+}
+
+// 从环境变量获取插件搜索失败暂停请求时长的上限（秒），默认300秒
+func getPluginFailureBackoffMax() time.Duration {
+	maxEnv := os.Getenv("PLUGIN_FAILURE_BACKOFF_MAX")
+	if maxEnv == "" {
+		return 300 * time.Second
+	}
+	max, err := strconv.Atoi(maxEnv)
+	if err != nil || max <= 0 {
+		return 300 * time.Second
+	}
+	return time.Duration(max) * time.Second
+}
+
 // 从环境变量获取是否启用异步插件，如果未设置则默认启用
 func getAsyncPluginEnabled() bool {
 	enabled := os.Getenv("ASYNC_PLUGIN_ENABLED")
@@ -585,6 +904,252 @@ func getAuthJWTSecret() string {
 	return secret
 }
 
+// 从环境变量获取视图令牌有效期（分钟），默认30分钟
+func getViewTokenTTL() time.Duration {
+	ttlEnv := os.Getenv("VIEW_TOKEN_TTL_MINUTES")
+	if ttlEnv == "" {
+		return 30 * time.Minute
+	}
+	ttl, err := strconv.Atoi(ttlEnv)
+	if err != nil || ttl <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(ttl) * time.Minute
+}
+
+// 从环境变量获取是否启用拼音首字母匹配，如果未设置则默认关闭
+func getPinyinMatchEnabled() bool {
+	enabled := os.Getenv("PINYIN_MATCH_ENABLED")
+	return enabled == "true" || enabled == "1"
+}
+
+// 从环境变量获取自定义网盘定义文件路径，如果未设置则不加载
+func getNetdiskProvidersFile() string {
+	return strings.TrimSpace(os.Getenv("NETDISK_PROVIDERS_FILE"))
+}
+
+// 从环境变量获取单次检测请求最多包含的链接数，如果未设置则使用默认值
+func getCheckMaxBatchSize() int {
+	sizeEnv := os.Getenv("CHECK_MAX_BATCH_SIZE")
+	if sizeEnv == "" {
+		return 100 // 默认100个
+	}
+	size, err := strconv.Atoi(sizeEnv)
+	if err != nil || size <= 0 {
+		return 100
+	}
+	return size
+}
+
+// 从环境变量获取单次检测请求的并发数，如果未设置则使用默认值
+func getCheckConcurrency() int {
+	concurrencyEnv := os.Getenv("CHECK_CONCURRENCY")
+	if concurrencyEnv == "" {
+		return 16 // 默认16
+	}
+	concurrency, err := strconv.Atoi(concurrencyEnv)
+	if err != nil || concurrency <= 0 {
+		return 16
+	}
+	return concurrency
+}
+
+// 从环境变量获取按网盘类型的检测并发数，格式：4,baidu:2,quark:6（不带类型的值为默认值）
+func getCheckProviderConcurrency() map[string]int {
+	limits := parseProviderLimits(os.Getenv("CHECK_PROVIDER_CONCURRENCY"), "4")
+	result := make(map[string]int, len(limits))
+	for diskType, limit := range limits {
+		if limit >= 1 {
+			result[diskType] = int(limit)
+		}
+	}
+	if _, ok := result["*"]; !ok {
+		result["*"] = 4
+	}
+	return result
+}
+
+// 从环境变量获取按网盘类型的每秒检测数，格式：5,baidu:2（不带类型的值为默认值，0表示不限制）
+func getCheckProviderRate() map[string]float64 {
+	return parseProviderLimits(os.Getenv("CHECK_PROVIDER_RATE"), "5,baidu:2")
+}
+
+// 从环境变量获取流式检测任务最多包含的链接数，默认10000
+func getCheckStreamMaxItems() int {
+	sizeEnv := os.Getenv("CHECK_STREAM_MAX_ITEMS")
+	if sizeEnv == "" {
+		return 10000
+	}
+	size, err := strconv.Atoi(sizeEnv)
+	if err != nil || size <= 0 {
+		return 10000
+	}
+	return size
+}
+
+// 从环境变量获取流式检测任务结果的保留时长（分钟），默认30分钟
+func getCheckJobRetention() time.Duration {
+	retentionEnv := os.Getenv("CHECK_JOB_RETENTION_MINUTES")
+	if retentionEnv == "" {
+		return 30 * time.Minute
+	}
+	retention, err := strconv.Atoi(retentionEnv)
+	if err != nil || retention <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(retention) * time.Minute
+}
+
+// 从环境变量获取同时执行的流式检测任务数上限，默认4
+func getCheckMaxActiveJobs() int {
+	limitEnv := os.Getenv("CHECK_MAX_ACTIVE_JOBS")
+	if limitEnv == "" {
+		return 4
+	}
+	limit, err := strconv.Atoi(limitEnv)
+	if err != nil || limit <= 0 {
+		return 4
+	}
+	return limit
+}
+
+// 从环境变量获取保留的流式检测任务数上限，默认50
+func getCheckMaxJobs() int {
+	limitEnv := os.Getenv("CHECK_MAX_JOBS")
+	if limitEnv == "" {
+		return 50
+	}
+	limit, err := strconv.Atoi(limitEnv)
+	if err != nil || limit <= 0 {
+		return 50
+	}
+	return limit
+}
+
+// 从环境变量获取是否启用后台链接复检，默认不启用
+func getRevalidateEnabled() bool {
+	enabled := os.Getenv("REVALIDATE_ENABLED")
+	return enabled == "true" || enabled == "1"
+}
+
+// 从环境变量获取后台链接复检间隔（分钟），默认60分钟
+func getRevalidateInterval() time.Duration {
+	intervalEnv := os.Getenv("REVALIDATE_INTERVAL_MINUTES")
+	if intervalEnv == "" {
+		return 60 * time.Minute
+	}
+	interval, err := strconv.Atoi(intervalEnv)
+	if err != nil || interval <= 0 {
+		return 60 * time.Minute
+	}
+	return time.Duration(interval) * time.Minute
+}
+
+// 从环境变量获取每轮复检的缓存条目数，默认50
+func getRevalidateTopKeys() int {
+	topKeysEnv := os.Getenv("REVALIDATE_TOP_KEYS")
+	if topKeysEnv == "" {
+		return 50
+	}
+	topKeys, err := strconv.Atoi(topKeysEnv)
+	if err != nil || topKeys <= 0 {
+		return 50
+	}
+	return topKeys
+}
+
+// 从环境变量获取每轮复检最多发起的检测数，默认200
+func getRevalidateMaxChecks() int {
+	maxChecksEnv := os.Getenv("REVALIDATE_MAX_CHECKS")
+	if maxChecksEnv == "" {
+		return 200
+	}
+	maxChecks, err := strconv.Atoi(maxChecksEnv)
+	if err != nil || maxChecks <= 0 {
+		return 200
+	}
+	return maxChecks
+}
+
+// 从环境变量获取是否启用缓存预热，默认不启用
+func getWarmupEnabled() bool {
+	enabled := os.Getenv("WARMUP_ENABLED")
+	return enabled == "true" || enabled == "1"
+}
+
+// 从环境变量获取缓存预热关键词文件路径，如果未设置则只预热热门关键词
+func getWarmupKeywordsFile() string {
+	return strings.TrimSpace(os.Getenv("WARMUP_KEYWORDS_FILE"))
+}
+
+// 从环境变量获取每轮预热的热门关键词数，默认50，0表示只预热关键词文件中的关键词
+func getWarmupTopKeywords() int {
+	topEnv := os.Getenv("WARMUP_TOP_KEYWORDS")
+	if topEnv == "" {
+		return 50
+	}
+	top, err := strconv.Atoi(topEnv)
+	if err != nil || top < 0 {
+		return 50
+	}
+	return top
+}
+
+// 从环境变量获取缓存预热间隔（分钟），默认60分钟
+func getWarmupInterval() time.Duration {
+	intervalEnv := os.Getenv("WARMUP_INTERVAL_MINUTES")
+	if intervalEnv == "" {
+		return 60 * time.Minute
+	}
+	interval, err := strconv.Atoi(intervalEnv)
+	if err != nil || interval <= 0 {
+		return 60 * time.Minute
+	}
+	return time.Duration(interval) * time.Minute
+}
+
+// 从环境变量获取缓存预热的并发预算，默认2
+func getWarmupConcurrency() int {
+	concurrencyEnv := os.Getenv("WARMUP_CONCURRENCY")
+	if concurrencyEnv == "" {
+		return 2
+	}
+	concurrency, err := strconv.Atoi(concurrencyEnv)
+	if err != nil || concurrency <= 0 {
+		return 2
+	}
+	return concurrency
+}
+
+// parseProviderLimits 解析按网盘类型配置的限制值，格式：默认值,类型:值,类型:值
+// 环境变量为空时使用defaultValue，无效的项直接忽略
+func parseProviderLimits(value, defaultValue string) map[string]float64 {
+	if strings.TrimSpace(value) == "" {
+		value = defaultValue
+	}
+
+	limits := make(map[string]float64)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		diskType, limitStr := "*", part
+		if index := strings.Index(part, ":"); index >= 0 {
+			diskType = strings.ToLower(strings.TrimSpace(part[:index]))
+			limitStr = strings.TrimSpace(part[index+1:])
+		}
+
+		limit, err := strconv.ParseFloat(limitStr, 64)
+		if err != nil || limit < 0 || diskType == "" {
+			continue
+		}
+		limits[diskType] = limit
+	}
+	return limits
+}
+
 // 应用GC设置
 func applyGCSettings() {
 	// 设置GC百分比
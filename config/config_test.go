@@ -0,0 +1,119 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacheTTLPolicyResolve(t *testing.T) {
+	policy := CacheTTLPolicy{
+		SourceTypes: map[string]time.Duration{"tg": 30 * time.Minute},
+		Channels:    map[string]time.Duration{"dailydrama": 5 * time.Minute},
+		Plugins:     map[string]time.Duration{"javdb": 1440 * time.Minute},
+		EmptyTTL:    2 * time.Minute,
+		HotAccesses: 50,
+		HotTTL:      180 * time.Minute,
+	}
+	fallback := 60 * time.Minute
+
+	tests := []struct {
+		name        string
+		sourceType  string
+		sources     []string
+		resultCount int
+		accesses    int64
+		want        time.Duration
+	}{
+		{name: "空结果优先", sourceType: "tg", sources: []string{"dailydrama"}, resultCount: 0, accesses: 100, want: 2 * time.Minute},
+		{name: "来源类型", sourceType: "tg", sources: []string{"other"}, resultCount: 1, want: 30 * time.Minute},
+		{name: "没有匹配的策略", sourceType: "plugin", sources: []string{"pansearch"}, resultCount: 1, want: fallback},
+		{name: "频道单独配置", sourceType: "tg", sources: []string{"DailyDrama"}, resultCount: 1, want: 5 * time.Minute},
+		{name: "多个频道取最短", sourceType: "tg", sources: []string{"other", "dailydrama"}, resultCount: 1, want: 5 * time.Minute},
+		{name: "插件单独配置", sourceType: "plugin", sources: []string{"javdb"}, resultCount: 1, want: 1440 * time.Minute},
+		{name: "插件单独配置与默认取最短", sourceType: "plugin", sources: []string{"javdb", "pansearch"}, resultCount: 1, want: fallback},
+		{name: "热门关键词优先于来源类型", sourceType: "tg", sources: []string{"other"}, resultCount: 1, accesses: 50, want: 180 * time.Minute},
+		{name: "频道单独配置优先于热门关键词", sourceType: "tg", sources: []string{"dailydrama"}, resultCount: 1, accesses: 50, want: 5 * time.Minute},
+		{name: "搜索全部频道取单独配置中最短", sourceType: "tg", resultCount: 1, want: 5 * time.Minute},
+		{name: "搜索全部频道的热门关键词不受单独配置限制", sourceType: "tg", resultCount: 1, accesses: 50, want: 180 * time.Minute},
+		{name: "搜索全部插件", sourceType: "plugin", resultCount: 1, want: fallback},
+		{name: "搜索全部插件的热门关键词", sourceType: "plugin", resultCount: 1, accesses: 80, want: 180 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Resolve(tt.sourceType, tt.sources, tt.resultCount, tt.accesses, fallback)
+			if got != tt.want {
+				t.Fatalf("Resolve(%q, %v, %d, %d) = %v，期望 %v", tt.sourceType, tt.sources, tt.resultCount, tt.accesses, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheTTLPolicyResolveWithoutRules(t *testing.T) {
+	var policy CacheTTLPolicy
+	if got := policy.Resolve("tg", nil, 0, 100, time.Hour); got != time.Hour {
+		t.Fatalf("未配置策略时Resolve = %v，期望 %v", got, time.Hour)
+	}
+}
+
+func TestGetCacheTTLPolicy(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  CacheTTLPolicy
+	}{
+		{
+			name:  "未设置",
+			value: "",
+			want:  CacheTTLPolicy{},
+		},
+		{
+			name:  "完整配置",
+			value: "tg:30,channel:DailyDrama:5,plugin:JavDB:1440,empty:2,hot:50:180,plugin:60",
+			want: CacheTTLPolicy{
+				SourceTypes: map[string]time.Duration{"tg": 30 * time.Minute, "plugin": 60 * time.Minute},
+				Channels:    map[string]time.Duration{"dailydrama": 5 * time.Minute},
+				Plugins:     map[string]time.Duration{"javdb": 1440 * time.Minute},
+				EmptyTTL:    2 * time.Minute,
+				HotAccesses: 50,
+				HotTTL:      180 * time.Minute,
+			},
+		},
+		{
+			name:  "忽略首尾空白",
+			value: " tg : 30 , channel : news : 10 ",
+			want: CacheTTLPolicy{
+				SourceTypes: map[string]time.Duration{"tg": 30 * time.Minute},
+				Channels:    map[string]time.Duration{"news": 10 * time.Minute},
+			},
+		},
+		{
+			name:  "忽略无效的项",
+			value: "tg:abc,tg:0,unknown:10,channel::10,hot:0:180,hot:x:180,plugin:a:b:10,empty:-1",
+			want:  CacheTTLPolicy{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CACHE_TTL_POLICY", tt.value)
+			got := getCacheTTLPolicy()
+
+			// 未配置的map与空map等价
+			want := tt.want
+			if want.SourceTypes == nil {
+				want.SourceTypes = map[string]time.Duration{}
+			}
+			if want.Channels == nil {
+				want.Channels = map[string]time.Duration{}
+			}
+			if want.Plugins == nil {
+				want.Plugins = map[string]time.Duration{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("getCacheTTLPolicy(%q) = %+v，期望 %+v", tt.value, got, want)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"pansou/service"
 	"pansou/util"
 	"pansou/util/cache"
+	"pansou/util/netdisk"
 
 	// 以下是插件的空导入，用于触发各插件的init函数，实现自动注册
 	// 添加新插件时，只需在此处添加对应的导入语句即可
@@ -118,6 +119,11 @@ import (
 var globalCacheWriteManager *cache.DelayedBatchWriteManager
 
 func main() {
+	// 缓存快照命令：pansou cache export|import
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		os.Exit(runCacheCommand(os.Args[2:]))
+	}
+
 	// 初始化应用
 	initApp()
 
@@ -133,6 +139,15 @@ func initApp() {
 	// 初始化HTTP客户端
 	util.InitHTTPClient()
 
+	// 加载自定义网盘定义
+	if path := config.AppConfig.NetdiskProvidersFile; path != "" {
+		count, err := netdisk.LoadFile(path)
+		if err != nil {
+			log.Fatalf("网盘定义加载失败: %v", err)
+		}
+		fmt.Printf("已加载自定义网盘定义: %d 个 (%s)\n", count, path)
+	}
+
 	// 初始化缓存写入管理器
 	var err error
 	globalCacheWriteManager, err = cache.NewDelayedBatchWriteManager()
@@ -142,7 +157,7 @@ func initApp() {
 	if err := globalCacheWriteManager.Initialize(); err != nil {
 		log.Fatalf("缓存写入管理器初始化失败: %v", err)
 	}
-	// 将缓存写入管理器注入到service包
+	// 将缓存写入管理器注入到service包，预写日志在创建搜索服务时恢复
 	service.SetGlobalCacheWriteManager(globalCacheWriteManager)
 
 	// 延迟设置主缓存更新函数，确保service初始化完成
@@ -180,6 +195,19 @@ func startServer() {
 	// 初始化搜索服务
 	searchService := service.NewSearchService(pluginManager)
 
+	// 启用实例组：缓存键按一致性哈希分配给组内实例，只有所属实例执行实际搜索
+	service.InitPeerGroup(searchService)
+
+	// 启动后台链接复检
+	if config.AppConfig.RevalidateEnabled {
+		service.GetRevalidationService().Start()
+	}
+
+	// 启动缓存预热
+	if config.AppConfig.WarmupEnabled {
+		service.GetWarmupService().Start(searchService)
+	}
+
 	// 设置路由
 	router := api.SetupRouter(searchService)
 
@@ -241,6 +269,13 @@ func startServer() {
 		}
 	}
 
+	// 保存关键词访问记录，供重启后预热热门关键词
+	if config.AppConfig.WarmupEnabled {
+		if err := service.SaveKeywordAccess(); err != nil {
+			log.Printf("关键词访问记录保存失败: %v", err)
+		}
+	}
+
 	// 额外确保内存缓存也被保存（双重保障）
 	if mainCache := service.GetEnhancedTwoLevelCache(); mainCache != nil {
 		if err := mainCache.FlushMemoryToDisk(); err != nil {
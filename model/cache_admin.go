@@ -0,0 +1,41 @@
+package model
+
+// CacheEntry 搜索缓存条目信息
+type CacheEntry struct {
+	Key          string   `json:"key"`
+	Keyword      string   `json:"keyword"`               // 标准化后的搜索关键词，未登记的缓存键为空
+	SourceType   string   `json:"source_type"`           // tg、plugin，未登记的缓存键为unknown
+	Sources      []string `json:"sources,omitempty"`     // 搜索的频道或插件，为空表示默认全部
+	Size         int      `json:"size"`                  // 缓存数据大小（字节）
+	AgeSeconds   int64    `json:"age_seconds"`           // 距最后写入的秒数
+	TTLRemaining int64    `json:"ttl_remaining_seconds"` // 剩余有效秒数
+	Tier         string   `json:"tier"`                  // memory、disk或memory+disk
+}
+
+// CacheEntryList 缓存条目列表
+type CacheEntryList struct {
+	Total   int          `json:"total"` // 符合筛选条件的条目总数
+	Entries []CacheEntry `json:"entries"`
+}
+
+// CacheEntryDetail 解码后的缓存条目
+type CacheEntryDetail struct {
+	CacheEntry
+	ResultCount int            `json:"result_count"`
+	Results     []SearchResult `json:"results"`
+}
+
+// CacheInvalidateRequest 缓存失效请求，key、keyword、plugin至少指定一个
+// 同时指定plugin和keyword时只删除该插件该关键词的缓存
+type CacheInvalidateRequest struct {
+	Key     string `json:"key,omitempty"`
+	Keyword string `json:"keyword,omitempty"`
+	Plugin  string `json:"plugin,omitempty"`
+}
+
+// CacheInvalidateResult 缓存失效结果
+type CacheInvalidateResult struct {
+	Removed       int      `json:"removed"`        // 删除的搜索缓存条目数
+	PluginRemoved int      `json:"plugin_removed"` // 删除的插件API响应缓存数
+	Keys          []string `json:"keys"`           // 删除的搜索缓存键
+}
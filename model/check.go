@@ -4,6 +4,7 @@ type CheckItem struct {
 	DiskType string `json:"disk_type" binding:"required"`
 	URL      string `json:"url" binding:"required"`
 	Password string `json:"password,omitempty"`
+	Source   string `json:"source,omitempty"` // 链接来源（tg:频道名 或 plugin:插件名），用于按来源统计
 }
 
 type CheckRequest struct {
@@ -25,3 +26,108 @@ type CheckResult struct {
 type CheckResponse struct {
 	Results []CheckResult `json:"results"`
 }
+
+// CheckJob 流式检测任务信息，作为流的第一个事件返回
+type CheckJob struct {
+	JobID string `json:"job_id"`
+	Total int    `json:"total"` // 任务包含的链接数
+}
+
+// CheckStreamResult 流式检测中完成的一条检测结果
+type CheckStreamResult struct {
+	Seq    int         `json:"seq"`   // 结果序号（按完成顺序，从0开始），断线重连时从已收到的结果数继续
+	Index  int         `json:"index"` // 链接在请求items中的位置
+	Result CheckResult `json:"result"`
+}
+
+// CheckJobSummary 流式检测任务汇总，作为流的最后一个事件返回
+type CheckJobSummary struct {
+	JobID      string         `json:"job_id"`
+	Total      int            `json:"total"`
+	Completed  int            `json:"completed"`
+	Counts     map[string]int `json:"counts"` // 各检测状态的链接数
+	StartedAt  int64          `json:"started_at"`
+	FinishedAt int64          `json:"finished_at"`
+}
+
+type ShareInspectRequest struct {
+	Items []CheckItem `json:"items" binding:"required"`
+}
+
+// ShareFile 分享中的顶层文件或文件夹
+type ShareFile struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"` // 文件大小（字节），文件夹通常为0
+	IsDir     bool   `json:"is_dir"`
+	ItemCount int    `json:"item_count,omitempty"` // 文件夹内的条目数（网盘返回时才有）
+}
+
+type ShareInspectResult struct {
+	DiskType      string      `json:"disk_type"`
+	URL           string      `json:"url"`
+	NormalizedURL string      `json:"normalized_url,omitempty"`
+	State         string      `json:"state"`
+	CacheHit      bool        `json:"cache_hit"`
+	CheckedAt     int64       `json:"checked_at"`
+	ExpiresAt     int64       `json:"expires_at"`
+	Summary       string      `json:"summary,omitempty"`
+	Title         string      `json:"title,omitempty"`     // 分享标题
+	FileCount     int         `json:"file_count"`          // 顶层文件数
+	DirCount      int         `json:"dir_count"`           // 顶层文件夹数
+	TotalSize     int64       `json:"total_size"`          // 顶层文件总大小（字节）
+	Files         []ShareFile `json:"files,omitempty"`     // 顶层文件列表
+	Truncated     bool        `json:"truncated,omitempty"` // 顶层条目过多，只返回了部分
+}
+
+type ShareInspectResponse struct {
+	Results []ShareInspectResult `json:"results"`
+}
+
+// CheckHistoryEntry 链接检测状态变化记录
+type CheckHistoryEntry struct {
+	State     string `json:"state"`
+	Summary   string `json:"summary,omitempty"`
+	Source    string `json:"source,omitempty"`
+	CheckedAt int64  `json:"checked_at"`
+}
+
+type CheckHistoryResponse struct {
+	DiskType      string              `json:"disk_type"`
+	URL           string              `json:"url"`
+	NormalizedURL string              `json:"normalized_url,omitempty"`
+	History       []CheckHistoryEntry `json:"history"` // 按时间正序
+}
+
+// CheckStatsCounts 检测次数统计（只统计实际发起的检测，不含缓存命中）
+type CheckStatsCounts struct {
+	Total       int64 `json:"total"`
+	OK          int64 `json:"ok"`
+	Bad         int64 `json:"bad"`
+	Locked      int64 `json:"locked"`
+	Unsupported int64 `json:"unsupported"`
+	Uncertain   int64 `json:"uncertain"`
+	Errors      int64 `json:"errors"` // 检测器请求失败次数（计入uncertain）
+}
+
+// CheckStatsDay 按天统计的检测次数
+type CheckStatsDay struct {
+	Date string `json:"date"`
+	CheckStatsCounts
+}
+
+// CheckStatsGroup 单个网盘或来源的检测统计
+type CheckStatsGroup struct {
+	Name string `json:"name"`
+	CheckStatsCounts
+	OKRatio     float64         `json:"ok_ratio"`
+	BadRatio    float64         `json:"bad_ratio"`
+	LockedRatio float64         `json:"locked_ratio"`
+	ErrorRate   float64         `json:"error_rate"`
+	Daily       []CheckStatsDay `json:"daily"` // 按日期正序
+}
+
+type CheckStatsResponse struct {
+	Days      int               `json:"days"`
+	Providers []CheckStatsGroup `json:"providers"`
+	Sources   []CheckStatsGroup `json:"sources"`
+}
@@ -0,0 +1,24 @@
+package model
+
+// LinkExtractRequest 从文本中提取网盘链接的请求
+type LinkExtractRequest struct {
+	Content string `json:"content" binding:"required"` // 任意文本或HTML
+	Check   bool   `json:"check,omitempty"`            // 是否同时检测提取到的链接
+}
+
+// ExtractedLink 从文本中提取到的链接
+type ExtractedLink struct {
+	Type          string       `json:"type"`
+	URL           string       `json:"url"`
+	NormalizedURL string       `json:"normalized_url,omitempty"`
+	Password      string       `json:"password,omitempty"`
+	WorkTitle     string       `json:"work_title,omitempty"` // 链接附近的作品标题
+	Check         *CheckResult `json:"check,omitempty"`      // 检测结果（仅在请求check为true且支持检测时返回）
+}
+
+// LinkExtractResponse 链接提取结果
+type LinkExtractResponse struct {
+	Title string          `json:"title,omitempty"` // 从内容中识别出的标题
+	Total int             `json:"total"`
+	Links []ExtractedLink `json:"links"`
+}
@@ -0,0 +1,32 @@
+package model
+
+// ReleaseMeta 从标题/备注中提取的结构化资源信息
+type ReleaseMeta struct {
+	Resolution   int    `json:"resolution,omitempty" sonic:"resolution,omitempty"`       // 分辨率（垂直像素），如2160、1080、720
+	HDR          bool   `json:"hdr,omitempty" sonic:"hdr,omitempty"`                     // 是否为HDR（含HDR10/HDR10+）
+	DolbyVision  bool   `json:"dolby_vision,omitempty" sonic:"dolby_vision,omitempty"`   // 是否为杜比视界
+	Codec        string `json:"codec,omitempty" sonic:"codec,omitempty"`                 // 视频编码：H265、H264、AV1、VP9
+	SeasonStart  int    `json:"season_start,omitempty" sonic:"season_start,omitempty"`   // 起始季
+	SeasonEnd    int    `json:"season_end,omitempty" sonic:"season_end,omitempty"`       // 结束季（单季时与起始季相同）
+	EpisodeStart int    `json:"episode_start,omitempty" sonic:"episode_start,omitempty"` // 起始集
+	EpisodeEnd   int    `json:"episode_end,omitempty" sonic:"episode_end,omitempty"`     // 结束集（单集时与起始集相同）
+	Year         int    `json:"year,omitempty" sonic:"year,omitempty"`                   // 年份
+	SizeBytes    int64  `json:"size_bytes,omitempty" sonic:"size_bytes,omitempty"`       // 文件大小（字节）
+	Completed    bool   `json:"completed,omitempty" sonic:"completed,omitempty"`         // 是否已完结
+	UpdatedTo    int    `json:"updated_to,omitempty" sonic:"updated_to,omitempty"`       // 更新至第几集
+}
+
+// MetaFilter 基于结构化资源信息的过滤条件
+type MetaFilter struct {
+	MinResolution int    `json:"min_resolution,omitempty"` // 最低分辨率，如2160
+	HDR           bool   `json:"hdr,omitempty"`            // 仅保留HDR或杜比视界资源
+	Codec         string `json:"codec,omitempty"`          // 指定视频编码
+	Year          int    `json:"year,omitempty"`           // 指定年份
+	Completed     bool   `json:"completed,omitempty"`      // 仅保留已完结资源
+	Season        int    `json:"season,omitempty"`         // 包含指定季
+}
+
+// IsEmpty 判断过滤条件是否为空
+func (f *MetaFilter) IsEmpty() bool {
+	return f == nil || (f.MinResolution <= 0 && !f.HDR && f.Codec == "" && f.Year <= 0 && !f.Completed && f.Season <= 0)
+}
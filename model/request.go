@@ -18,4 +18,9 @@ type SearchRequest struct {
 	Ext          map[string]interface{} `json:"ext"`                         // 扩展参数，用于传递给插件的自定义参数
 	CloudTypes   []string               `json:"cloud_types"`                 // 指定返回的网盘类型列表，不指定则返回所有类型
 	Filter       *FilterConfig          `json:"filter,omitempty"`            // 过滤配置，用于过滤返回结果
+	Meta         bool                   `json:"meta"`                        // 是否在结果中返回结构化资源信息（分辨率、季集、大小等）
+	Check        string                 `json:"check"`                       // 链接有效性标注：none(默认，不标注)、cached(仅使用检测缓存)、fresh(未缓存的链接实时检测)
+	CheckFilter  bool                   `json:"check_filter"`                // 是否按检测结果移除失效链接，并将状态不确定的链接排到最后
+	Debug        bool                   `json:"debug"`                       // 是否在响应中返回调试信息（插件失败退避状态等）
+	MetaFilter                                                                 // 基于结构化资源信息的过滤条件（min_resolution、hdr等）
 } 
\ No newline at end of file
@@ -22,6 +22,8 @@ type SearchResult struct {
 	Links     []Link    `json:"links" sonic:"links"`
 	Tags      []string  `json:"tags,omitempty" sonic:"tags,omitempty"`
 	Images    []string  `json:"images,omitempty" sonic:"images,omitempty"` // TG消息中的图片链接
+	Meta      *ReleaseMeta `json:"meta,omitempty" sonic:"meta,omitempty"` // 结构化资源信息（仅在请求meta=true时返回）
+	Score     float64   `json:"score,omitempty" sonic:"score,omitempty"`   // 与搜索关键词的相关度得分（0-100）
 }
 
 // MergedLink 合并后的网盘链接
@@ -32,6 +34,10 @@ type MergedLink struct {
 	Datetime time.Time `json:"datetime" sonic:"datetime"`
 	Source   string    `json:"source,omitempty" sonic:"source,omitempty"` // 数据来源：tg:频道名 或 plugin:插件名
 	Images   []string  `json:"images,omitempty" sonic:"images,omitempty"`   // TG消息中的图片链接
+	Meta     *ReleaseMeta `json:"meta,omitempty" sonic:"meta,omitempty"`   // 结构化资源信息（仅在请求meta=true时返回）
+	Score    float64   `json:"score,omitempty" sonic:"score,omitempty"`     // 链接标题与搜索关键词的相关度得分（0-100）
+	CheckState string  `json:"check_state,omitempty" sonic:"check_state,omitempty"` // 链接检测状态（仅在请求check不为none时返回）
+	CheckedAt  int64   `json:"checked_at,omitempty" sonic:"checked_at,omitempty"`   // 最近一次检测时间戳（毫秒）
 }
 
 // MergedLinks 按网盘类型分组的合并链接
@@ -42,6 +48,27 @@ type SearchResponse struct {
 	Total        int           `json:"total" sonic:"total"`
 	Results      []SearchResult `json:"results,omitempty" sonic:"results,omitempty"`
 	MergedByType MergedLinks   `json:"merged_by_type,omitempty" sonic:"merged_by_type,omitempty"`
+	ViewToken    string        `json:"view_token,omitempty" sonic:"view_token,omitempty"`       // 视图令牌，可用于匿名检测本次返回的链接（仅在启用认证时返回）
+	ViewTokenExpiresAt int64   `json:"view_token_expires_at,omitempty" sonic:"view_token_expires_at,omitempty"` // 视图令牌过期时间戳（毫秒）
+	CachedAt     int64         `json:"cached_at,omitempty" sonic:"cached_at,omitempty"`         // TG频道结果的缓存写入时间戳（毫秒，仅在结果来自缓存时返回）
+	CacheAge     int64         `json:"cache_age,omitempty" sonic:"cache_age,omitempty"`         // TG频道结果的缓存时长（秒）
+	Debug        *SearchDebug  `json:"debug,omitempty" sonic:"debug,omitempty"`                 // 调试信息（仅在请求debug=true时返回）
+}
+
+// SearchDebug 搜索调试信息
+type SearchDebug struct {
+	SourceBackoffs []SourceBackoff `json:"source_backoffs" sonic:"source_backoffs"` // 对该关键词连续搜索失败的插件
+}
+
+// SourceBackoff 插件对某个关键词连续搜索失败后的退避状态，退避期内不再请求该插件，只返回已缓存的结果
+type SourceBackoff struct {
+	Plugin        string `json:"plugin" sonic:"plugin"`
+	Keyword       string `json:"keyword" sonic:"keyword"`
+	Failures      int    `json:"failures" sonic:"failures"`               // 连续失败次数（包括请求超时）
+	LastError     string `json:"last_error" sonic:"last_error"`           // 最近一次失败的错误信息
+	LastFailureAt int64  `json:"last_failure_at" sonic:"last_failure_at"` // 最近一次失败的时间戳（毫秒）
+	RetryAt       int64  `json:"retry_at" sonic:"retry_at"`               // 退避结束的时间戳（毫秒）
+	InBackoff     bool   `json:"in_backoff" sonic:"in_backoff"`           // 当前是否处于退避期
 }
 
 // Response API通用响应
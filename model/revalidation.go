@@ -0,0 +1,45 @@
+package model
+
+// LinkStateTransition 链接检测状态变化记录
+type LinkStateTransition struct {
+	DiskType string `json:"disk_type"`
+	URL      string `json:"url"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	At       int64  `json:"at"` // 发现状态变化的时间戳（毫秒）
+}
+
+// RevalidationProgress 当前（或最近一轮）复检进度
+type RevalidationProgress struct {
+	EntriesTotal int `json:"entries_total"` // 本轮待复检的缓存条目数
+	EntriesDone  int `json:"entries_done"`  // 已处理的缓存条目数
+	ChecksUsed   int `json:"checks_used"`   // 已发起的检测数
+	CheckBudget  int `json:"check_budget"`  // 本轮最多发起的检测数
+}
+
+// RevalidationCounters 复检累计计数
+type RevalidationCounters struct {
+	EntriesScanned   int64 `json:"entries_scanned"`    // 扫描的缓存条目数
+	EntriesRewritten int64 `json:"entries_rewritten"`  // 移除失效链接后重写的缓存条目数
+	LinksChecked     int64 `json:"links_checked"`      // 实际发起检测的链接数
+	LinksCached      int64 `json:"links_cached"`       // 直接使用检测缓存的链接数
+	LinksSkipped     int64 `json:"links_skipped"`      // 超出检测预算而跳过的链接数
+	DeadLinksRemoved int64 `json:"dead_links_removed"` // 从缓存中移除的失效链接数
+	ResultsRemoved   int64 `json:"results_removed"`    // 链接全部失效而移除的搜索结果数
+	Transitions      int64 `json:"transitions"`        // 检测状态变化次数
+}
+
+// RevalidationStatus 后台链接复检状态
+type RevalidationStatus struct {
+	Enabled           bool                  `json:"enabled"`
+	Running           bool                  `json:"running"`
+	IntervalMinutes   int                   `json:"interval_minutes"`
+	Rounds            int64                 `json:"rounds"`                     // 已完成的复检轮数
+	LastStartedAt     int64                 `json:"last_started_at,omitempty"`  // 最近一轮开始时间戳（毫秒）
+	LastFinishedAt    int64                 `json:"last_finished_at,omitempty"` // 最近一轮结束时间戳（毫秒）
+	NextRunAt         int64                 `json:"next_run_at,omitempty"`      // 下一轮计划开始时间戳（毫秒）
+	Progress          RevalidationProgress  `json:"progress"`
+	Counters          RevalidationCounters  `json:"counters"`
+	TransitionCounts  map[string]int64      `json:"transition_counts"`  // 按“原状态->新状态”统计的变化次数
+	RecentTransitions []LinkStateTransition `json:"recent_transitions"` // 最近的状态变化，按时间倒序
+}
@@ -0,0 +1,38 @@
+package model
+
+// WarmupProgress 当前（或最近一轮）缓存预热进度
+type WarmupProgress struct {
+	KeywordsTotal int `json:"keywords_total"` // 本轮待预热的关键词数
+	KeywordsDone  int `json:"keywords_done"`  // 已处理的关键词数
+	Searched      int `json:"searched"`       // 实际执行搜索的关键词数
+	Cached        int `json:"cached"`         // 已有缓存而跳过的关键词数
+	Failed        int `json:"failed"`         // 搜索失败的关键词数
+}
+
+// WarmupResult 一个关键词的预热结果
+type WarmupResult struct {
+	Keyword     string `json:"keyword"`
+	Source      string `json:"source"`       // file（关键词文件）或top（热门关键词）
+	Cached      bool   `json:"cached"`       // 已有缓存，未执行搜索
+	ResultCount int    `json:"result_count"` // 搜索返回的结果数
+	DurationMs  int64  `json:"duration_ms"`  // 搜索耗时（毫秒）
+	Error       string `json:"error,omitempty"`
+}
+
+// WarmupStatus 缓存预热状态
+type WarmupStatus struct {
+	Enabled         bool           `json:"enabled"`
+	Running         bool           `json:"running"`
+	IntervalMinutes int            `json:"interval_minutes"`
+	Concurrency     int            `json:"concurrency"`                // 与进行中的用户搜索共享的并发预算
+	KeywordsFile    string         `json:"keywords_file,omitempty"`    // 预热关键词文件路径
+	TopKeywords     int            `json:"top_keywords"`               // 每轮预热的热门关键词数
+	Rounds          int64          `json:"rounds"`                     // 已完成的预热轮数
+	LastStartedAt   int64          `json:"last_started_at,omitempty"`  // 最近一轮开始时间戳（毫秒）
+	LastFinishedAt  int64          `json:"last_finished_at,omitempty"` // 最近一轮结束时间戳（毫秒）
+	NextRunAt       int64          `json:"next_run_at,omitempty"`      // 下一轮计划开始时间戳（毫秒）
+	LastError       string         `json:"last_error,omitempty"`       // 最近一轮读取关键词文件的错误
+	Yields          int64          `json:"yields"`                     // 因用户搜索占满并发预算而等待的次数
+	Progress        WarmupProgress `json:"progress"`
+	Results         []WarmupResult `json:"results"` // 当前（或最近一轮）各关键词的预热结果
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"pansou/model"
+	"pansou/util/linkid"
+)
+
+// 链接检测状态
+const (
+	CheckStateOK          = "ok"          // 链接有效
+	CheckStateBad         = "bad"         // 链接失效
+	CheckStateLocked      = "locked"      // 需要提取码或提取码错误
+	CheckStateUnsupported = "unsupported" // 暂不支持检测
+	CheckStateUncertain   = "uncertain"   // 检测失败或结果不确定
+)
+
+// LinkChecker 链接检测器接口
+// 插件可以实现此接口并在init中调用RegisterLinkChecker注册，为自己的链接类型提供有效性检测
+type LinkChecker interface {
+	// Name 返回检测器支持的网盘类型，与链接类型一致（如"pikpak"）
+	Name() string
+
+	// Normalize 规范化分享链接，返回规范URL和最终使用的提取码
+	// 返回空URL表示链接格式无效；一般可直接使用NormalizeShareLink
+	Normalize(rawURL, password string) (normalized string, finalPassword string)
+
+	// Check 检测链接状态，返回检测状态（CheckState*）和状态说明
+	// 返回error时按检测失败处理，结果不会被缓存
+	Check(ctx context.Context, item model.CheckItem, normalized string) (state string, summary string, err error)
+}
+
+// 全局链接检测器注册表
+var (
+	linkCheckerRegistry     = make(map[string]LinkChecker)
+	linkCheckerRegistryLock sync.RWMutex
+)
+
+// RegisterLinkChecker 注册链接检测器，同一网盘类型重复注册时以后注册的为准
+// 插件注册的检测器优先于内置检测器
+func RegisterLinkChecker(checker LinkChecker) {
+	if checker == nil {
+		return
+	}
+
+	name := strings.ToLower(checker.Name())
+	if name == "" {
+		return
+	}
+
+	linkCheckerRegistryLock.Lock()
+	defer linkCheckerRegistryLock.Unlock()
+	linkCheckerRegistry[name] = checker
+}
+
+// GetLinkChecker 根据网盘类型获取插件注册的链接检测器
+func GetLinkChecker(diskType string) (LinkChecker, bool) {
+	linkCheckerRegistryLock.RLock()
+	defer linkCheckerRegistryLock.RUnlock()
+	checker, ok := linkCheckerRegistry[strings.ToLower(diskType)]
+	return checker, ok
+}
+
+// GetRegisteredLinkCheckers 获取所有插件注册的链接检测器
+func GetRegisteredLinkCheckers() []LinkChecker {
+	linkCheckerRegistryLock.RLock()
+	defer linkCheckerRegistryLock.RUnlock()
+	checkers := make([]LinkChecker, 0, len(linkCheckerRegistry))
+	for _, checker := range linkCheckerRegistry {
+		checkers = append(checkers, checker)
+	}
+	return checkers
+}
+
+// NormalizeShareLink 默认的分享链接规范化逻辑
+// 能按网盘注册表解析的链接返回规范URL和链接中携带（或传入）的提取码，其他链接只做通用标准化
+func NormalizeShareLink(rawURL, password string) (string, string) {
+	base := strings.TrimSpace(rawURL)
+	if base == "" {
+		return "", password
+	}
+
+	if id, ok := linkid.ParseWithPassword(base, password); ok {
+		return id.CanonicalURL, id.Password
+	}
+	return linkid.Canonicalize(base), password
+}
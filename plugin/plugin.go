@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"pansou/config"
 	"pansou/model"
+	"pansou/util"
 )
 
 // ============================================================
@@ -81,6 +82,9 @@ var (
 	backgroundWorkerPool chan struct{}
 	backgroundTasksCount int32 = 0
 	
+	// 正在后台刷新的插件缓存键，同一缓存键同时只刷新一次
+	refreshingKeys = sync.Map{}
+	
 	// 统计数据 (仅用于内部监控)
 	cacheHits         int64 = 0
 	cacheMisses       int64 = 0
@@ -97,6 +101,10 @@ var (
 	defaultMaxBackgroundWorkers = 20
 	defaultMaxBackgroundTasks = 100
 	
+	// 后台刷新等待工作槽的最长时间和重试间隔
+	refreshSlotWait = 30 * time.Second
+	workerSlotRetryInterval = 200 * time.Millisecond
+	
 	// 缓存访问频率记录
 	cacheAccessCount = sync.Map{}
 	
@@ -243,28 +251,12 @@ func FilterResultsByKeyword(results []model.SearchResult, keyword string) []mode
 	// 预估过滤后会保留80%的结果
 	filteredResults := make([]model.SearchResult, 0, len(results)*8/10)
 
-	// 将关键词转为小写，用于不区分大小写的比较
-	lowerKeyword := strings.ToLower(keyword)
-
-	// 将关键词按空格分割，用于支持多关键词搜索
-	keywords := strings.Fields(lowerKeyword)
+	// 与Service层使用相同的匹配规则：查询中的多个词顺序不限，字母数字词元容忍少量拼写错误
+	scorer := util.NewRelevanceScorer(keyword, nil)
 
 	for _, result := range results {
-		// 将标题和内容转为小写
-		lowerTitle := strings.ToLower(result.Title)
-		lowerContent := strings.ToLower(result.Content)
-
-		// 检查每个关键词是否在标题或内容中
-		matched := true
-		for _, kw := range keywords {
-			// 对于所有关键词，检查是否在标题或内容中
-			if !strings.Contains(lowerTitle, kw) && !strings.Contains(lowerContent, kw) {
-				matched = false
-				break
-			}
-		}
-
-		if matched {
+		// 每个查询片段命中标题或内容即可
+		if scorer.Match(result.Title + "\n" + result.Content) {
 			filteredResults = append(filteredResults, result)
 		}
 	}
@@ -295,8 +287,9 @@ func cleanupExpiredApiCache() {
 	apiResponseCache.Range(func(key, value interface{}) bool {
 		totalCount++
 		if cached, ok := value.(cachedResponse); ok {
-			// 使用默认TTL + 30分钟宽限期，避免过于激进的清理
-			expireThreshold := defaultCacheTTL + 30*time.Minute
+			// 使用插件的TTL + 30分钟宽限期，避免过于激进的清理
+			pluginName := strings.SplitN(key.(string), ":", 2)[0]
+			expireThreshold := pluginCacheTTL(pluginName, defaultCacheTTL) + 30*time.Minute
 			if now.Sub(cached.Timestamp) > expireThreshold {
 				keyStr := key.(string)
 				apiResponseCache.Delete(key)
@@ -312,6 +305,9 @@ func cleanupExpiredApiCache() {
 		cacheAccessCount.Delete(key)
 	}
 	
+	// 清理早已结束退避的失败记录
+	cleanupSourceFailures(now)
+	
 	lastCleanupTime = now
 	
 	// 记录清理日志（仅在有清理时输出）
@@ -320,6 +316,43 @@ func cleanupExpiredApiCache() {
 	}
 }
 
+// pluginCacheTTL 返回插件API响应缓存的有效期，缓存有效期策略中没有单独配置该插件时返回fallback
+func pluginCacheTTL(pluginName string, fallback time.Duration) time.Duration {
+	if config.AppConfig != nil {
+		if ttl, ok := config.AppConfig.CacheTTLPolicy.PluginTTL(pluginName); ok {
+			return ttl
+		}
+	}
+	return fallback
+}
+
+// InvalidateAsyncCache 删除异步插件的API响应缓存
+// pluginName为空时匹配所有插件，keyword为空时匹配所有关键词（忽略大小写和首尾空白），返回删除的缓存项数
+func InvalidateAsyncCache(pluginName, keyword string) int {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	
+	removed := 0
+	apiResponseCache.Range(func(key, value interface{}) bool {
+		parts := strings.SplitN(key.(string), ":", 2)
+		if len(parts) != 2 {
+			return true
+		}
+		if pluginName != "" && !strings.EqualFold(parts[0], pluginName) {
+			return true
+		}
+		if keyword != "" && strings.ToLower(strings.TrimSpace(parts[1])) != keyword {
+			return true
+		}
+		
+		apiResponseCache.Delete(key)
+		cacheAccessCount.Delete(key)
+		removed++
+		return true
+	})
+	
+	return removed
+}
+
 // initAsyncPlugin 初始化异步插件配置
 func initAsyncPlugin() {
 	initLock.Lock()
@@ -370,6 +403,23 @@ func acquireWorkerSlot() bool {
 	}
 }
 
+// acquireWorkerSlotWithin 在指定时间内等待工作槽，工作池已满时不立即放弃
+func acquireWorkerSlotWithin(wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	
+	for {
+		if acquireWorkerSlot() {
+			return true
+		}
+		select {
+		case <-timer.C:
+			return false
+		case <-time.After(workerSlotRetryInterval):
+		}
+	}
+}
+
 // releaseWorkerSlot 释放工作槽
 func releaseWorkerSlot() {
 	<-backgroundWorkerPool
@@ -518,6 +568,11 @@ func (p *BaseAsyncPlugin) SetMainCacheUpdater(updater func(string, []model.Searc
 	p.mainCacheUpdater = updater
 }
 
+// effectiveCacheTTL 返回API响应缓存的有效期，缓存有效期策略中单独配置了该插件时优先使用
+func (p *BaseAsyncPlugin) effectiveCacheTTL() time.Duration {
+	return pluginCacheTTL(p.name, p.cacheTTL)
+}
+
 // Name 返回插件名称
 func (p *BaseAsyncPlugin) Name() string {
 	return p.name
@@ -562,14 +617,15 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 	// 检查缓存
 	if cachedItems, ok := apiResponseCache.Load(pluginSpecificCacheKey); ok {
 		cachedResult := cachedItems.(cachedResponse)
+		cacheTTL := p.effectiveCacheTTL()
 		
 		// 缓存完全有效（未过期且完整）
-		if time.Since(cachedResult.Timestamp) < p.cacheTTL && cachedResult.Complete {
+		if time.Since(cachedResult.Timestamp) < cacheTTL && cachedResult.Complete {
 			recordCacheHit()
 			recordCacheAccess(pluginSpecificCacheKey)
 			
 			// 如果缓存接近过期（已用时间超过TTL的80%），在后台刷新缓存
-			if time.Since(cachedResult.Timestamp) > (p.cacheTTL * 4 / 5) {
+			if time.Since(cachedResult.Timestamp) > (cacheTTL * 4 / 5) {
 				go p.refreshCacheInBackground(keyword, pluginSpecificCacheKey, searchFunc, cachedResult, mainCacheKey, ext)
 			}
 			
@@ -582,7 +638,7 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 			recordCacheAccess(pluginSpecificCacheKey)
 			
 			// 标记为部分过期
-			if time.Since(cachedResult.Timestamp) >= p.cacheTTL {
+			if time.Since(cachedResult.Timestamp) >= cacheTTL {
 				// 在后台刷新缓存
 				go p.refreshCacheInBackground(keyword, pluginSpecificCacheKey, searchFunc, cachedResult, mainCacheKey, ext)
 				
@@ -595,6 +651,11 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 		}
 	}
 	
+	// 插件近期对该关键词搜索失败，退避期内不再请求（已缓存的结果在上面返回）
+	if until, ok := sourceBackoffUntil(p.name, keyword); ok {
+		return nil, newSourceBackoffError(p.name, until)
+	}
+	
 	recordCacheMiss()
 	
 	// 创建通道
@@ -608,6 +669,7 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 		if !acquireWorkerSlot() {
 			// 工作池已满，使用快速响应客户端直接处理
 			results, err := searchFunc(p.client, keyword, ext)
+			recordSourceOutcome(p.name, keyword, err)
 			if err != nil {
 				select {
 				case errorChan <- err:
@@ -639,6 +701,7 @@ func (p *BaseAsyncPlugin) AsyncSearch(
 		
 		// 执行搜索
 		results, err := searchFunc(p.backgroundClient, keyword, ext)
+		recordSourceOutcome(p.name, keyword, err)
 		
 		// 检查是否已经响应
 		select {
@@ -819,14 +882,15 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 	// 检查缓存
 	if cachedItems, ok := apiResponseCache.Load(pluginSpecificCacheKey); ok {
 		cachedResult := cachedItems.(cachedResponse)
+		cacheTTL := p.effectiveCacheTTL()
 		
 		// 缓存完全有效（未过期且完整）
-		if time.Since(cachedResult.Timestamp) < p.cacheTTL && cachedResult.Complete {
+		if time.Since(cachedResult.Timestamp) < cacheTTL && cachedResult.Complete {
 			recordCacheHit()
 			recordCacheAccess(pluginSpecificCacheKey)
 			
 			// 如果缓存接近过期（已用时间超过TTL的80%），在后台刷新缓存
-			if time.Since(cachedResult.Timestamp) > (p.cacheTTL * 4 / 5) {
+			if time.Since(cachedResult.Timestamp) > (cacheTTL * 4 / 5) {
 				go p.refreshCacheInBackground(keyword, pluginSpecificCacheKey, searchFunc, cachedResult, mainCacheKey, ext)
 			}
 			
@@ -845,7 +909,7 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 			recordCacheAccess(pluginSpecificCacheKey)
 			
 			// 标记为部分过期
-			if time.Since(cachedResult.Timestamp) >= p.cacheTTL {
+			if time.Since(cachedResult.Timestamp) >= cacheTTL {
 				// 在后台刷新缓存
 				go p.refreshCacheInBackground(keyword, pluginSpecificCacheKey, searchFunc, cachedResult, mainCacheKey, ext)
 			}
@@ -860,6 +924,11 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		}
 	}
 	
+	// 插件近期对该关键词搜索失败，退避期内不再请求（已缓存的结果在上面返回）
+	if until, ok := sourceBackoffUntil(p.name, keyword); ok {
+		return model.PluginSearchResult{}, newSourceBackoffError(p.name, until)
+	}
+	
 	recordCacheMiss()
 	
 	// 创建通道
@@ -881,6 +950,7 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		if !acquireWorkerSlot() {
 			// 工作池已满，使用快速响应客户端直接处理
 			results, err := searchFunc(p.client, keyword, ext)
+			recordSourceOutcome(p.name, keyword, err)
 			if err != nil {
 				select {
 				case errorChan <- err:
@@ -899,6 +969,7 @@ func (p *BaseAsyncPlugin) AsyncSearchWithResult(
 		
 		// 使用长超时客户端进行搜索
 		results, err := searchFunc(p.backgroundClient, keyword, ext)
+		recordSourceOutcome(p.name, keyword, err)
 		if err != nil {
 			select {
 			case errorChan <- err:
@@ -994,6 +1065,7 @@ func (p *BaseAsyncPlugin) completeSearchInBackground(
 	
 	// 执行完整搜索
 	results, err := searchFunc(p.backgroundClient, keyword, ext)
+	recordSourceOutcome(p.name, keyword, err)
 	if err != nil {
 		return
 	}
@@ -1034,8 +1106,20 @@ func (p *BaseAsyncPlugin) refreshCacheInBackground(
 	
 	// 注意：这里的cacheKey已经是插件特定的了，因为是从AsyncSearch传入的
 	
-	// 检查是否有足够的工作槽
-	if !acquireWorkerSlot() {
+	// 插件处于失败退避期时不刷新，继续使用旧缓存
+	if _, ok := sourceBackoffUntil(p.name, keyword); ok {
+		return
+	}
+	
+	// 同一缓存键已在刷新中，不重复刷新
+	if _, loaded := refreshingKeys.LoadOrStore(cacheKey, struct{}{}); loaded {
+		return
+	}
+	defer refreshingKeys.Delete(cacheKey)
+	
+	// 工作池已满时等待空闲的工作槽，而不是直接放弃刷新
+	if !acquireWorkerSlotWithin(refreshSlotWait) {
+		fmt.Printf("[%s] 工作池繁忙，放弃后台刷新: %s\n", p.name, cacheKey)
 		return
 	}
 	defer releaseWorkerSlot()
@@ -1045,6 +1129,7 @@ func (p *BaseAsyncPlugin) refreshCacheInBackground(
 	
 	// 执行搜索
 	results, err := searchFunc(p.backgroundClient, keyword, ext)
+	recordSourceOutcome(p.name, keyword, err)
 	if err != nil || len(results) == 0 {
 		return
 	}
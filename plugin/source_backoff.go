@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+)
+
+// 默认的失败退避时长
+const (
+	defaultFailureBackoffBase = 5 * time.Second
+	defaultFailureBackoffMax  = 5 * time.Minute
+)
+
+// errSourceBackoff 插件处于失败退避期，本次没有发起请求
+var errSourceBackoff = errors.New("搜索失败退避中")
+
+// sourceFailure 插件对某个关键词连续搜索失败的记录
+type sourceFailure struct {
+	plugin      string
+	keyword     string
+	failures    int       // 连续失败次数
+	lastError   string    // 最近一次失败的错误信息
+	lastFailure time.Time // 最近一次失败的时间
+	until       time.Time // 退避结束时间，之前不再请求该插件
+}
+
+var (
+	sourceFailures      = make(map[string]*sourceFailure)
+	sourceFailuresMutex sync.Mutex
+	backoffSkips        int64 // 因处于退避期而跳过的搜索次数
+)
+
+// sourceFailureKey 生成失败记录的键，插件名和关键词忽略大小写，关键词忽略首尾空白
+func sourceFailureKey(pluginName, keyword string) string {
+	return strings.ToLower(pluginName) + ":" + strings.ToLower(strings.TrimSpace(keyword))
+}
+
+// failureBackoffSettings 返回首次失败的退避时长和退避时长上限，首次退避时长为0表示不启用
+func failureBackoffSettings() (base, max time.Duration) {
+	if config.AppConfig == nil {
+		return defaultFailureBackoffBase, defaultFailureBackoffMax
+	}
+	return config.AppConfig.PluginFailureBackoff, config.AppConfig.PluginFailureBackoffMax
+}
+
+// recordSourceFailure 记录插件对关键词的一次搜索失败（包括请求超时），退避时长随连续失败次数翻倍
+func recordSourceFailure(pluginName, keyword string, err error) {
+	base, max := failureBackoffSettings()
+	if base <= 0 || errors.Is(err, errSourceBackoff) {
+		return
+	}
+
+	now := time.Now()
+	key := sourceFailureKey(pluginName, keyword)
+
+	sourceFailuresMutex.Lock()
+	defer sourceFailuresMutex.Unlock()
+
+	failure, ok := sourceFailures[key]
+	if !ok {
+		failure = &sourceFailure{plugin: pluginName, keyword: strings.TrimSpace(keyword)}
+		sourceFailures[key] = failure
+	}
+	// 退避期内报告的失败来自退避开始前发起的搜索（同一次搜索也可能在多层调用中重复报告），不再延长退避
+	if now.Before(failure.until) {
+		return
+	}
+
+	failure.failures++
+	failure.lastError = err.Error()
+	failure.lastFailure = now
+
+	backoff := base
+	for i := 1; i < failure.failures && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	failure.until = now.Add(backoff)
+
+	fmt.Printf("[%s] 搜索失败，%v 内不再请求: %s (连续失败: %d, 错误: %v)\n",
+		pluginName, backoff, failure.keyword, failure.failures, err)
+}
+
+// recordSourceSuccess 插件对关键词搜索成功，清除失败记录
+func recordSourceSuccess(pluginName, keyword string) {
+	key := sourceFailureKey(pluginName, keyword)
+
+	sourceFailuresMutex.Lock()
+	delete(sourceFailures, key)
+	sourceFailuresMutex.Unlock()
+}
+
+// recordSourceOutcome 根据搜索结果记录失败或清除失败记录
+func recordSourceOutcome(pluginName, keyword string, err error) {
+	if err != nil {
+		recordSourceFailure(pluginName, keyword, err)
+	} else {
+		recordSourceSuccess(pluginName, keyword)
+	}
+}
+
+// sourceBackoffUntil 检查插件对关键词是否处于退避期，返回退避结束时间
+func sourceBackoffUntil(pluginName, keyword string) (time.Time, bool) {
+	key := sourceFailureKey(pluginName, keyword)
+
+	sourceFailuresMutex.Lock()
+	failure, ok := sourceFailures[key]
+	var until time.Time
+	if ok {
+		until = failure.until
+	}
+	sourceFailuresMutex.Unlock()
+
+	if !ok || !time.Now().Before(until) {
+		return time.Time{}, false
+	}
+	atomic.AddInt64(&backoffSkips, 1)
+	return until, true
+}
+
+// newSourceBackoffError 创建处于退避期时返回的错误
+func newSourceBackoffError(pluginName string, until time.Time) error {
+	return fmt.Errorf("[%s] %w，%v 后重试", pluginName, errSourceBackoff, time.Until(until).Round(time.Second))
+}
+
+// cleanupSourceFailures 删除退避结束后超过退避时长上限仍未再次失败的记录
+func cleanupSourceFailures(now time.Time) {
+	_, max := failureBackoffSettings()
+
+	sourceFailuresMutex.Lock()
+	defer sourceFailuresMutex.Unlock()
+
+	for key, failure := range sourceFailures {
+		if now.Sub(failure.until) > max {
+			delete(sourceFailures, key)
+		}
+	}
+}
+
+// SourceBackoffs 获取插件的失败退避状态，keyword为空时返回所有关键词，按插件名排序
+func SourceBackoffs(keyword string) []model.SourceBackoff {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	now := time.Now()
+
+	sourceFailuresMutex.Lock()
+	backoffs := make([]model.SourceBackoff, 0)
+	for _, failure := range sourceFailures {
+		if keyword != "" && strings.ToLower(failure.keyword) != keyword {
+			continue
+		}
+		backoffs = append(backoffs, model.SourceBackoff{
+			Plugin:        failure.plugin,
+			Keyword:       failure.keyword,
+			Failures:      failure.failures,
+			LastError:     failure.lastError,
+			LastFailureAt: failure.lastFailure.UnixMilli(),
+			RetryAt:       failure.until.UnixMilli(),
+			InBackoff:     now.Before(failure.until),
+		})
+	}
+	sourceFailuresMutex.Unlock()
+
+	sort.Slice(backoffs, func(i, j int) bool {
+		if backoffs[i].Plugin != backoffs[j].Plugin {
+			return backoffs[i].Plugin < backoffs[j].Plugin
+		}
+		return backoffs[i].Keyword < backoffs[j].Keyword
+	})
+	return backoffs
+}
+
+// SourceBackoffStats 获取失败退避的统计信息
+func SourceBackoffStats() map[string]interface{} {
+	now := time.Now()
+
+	sourceFailuresMutex.Lock()
+	active := 0
+	for _, failure := range sourceFailures {
+		if now.Before(failure.until) {
+			active++
+		}
+	}
+	tracked := len(sourceFailures)
+	sourceFailuresMutex.Unlock()
+
+	return map[string]interface{}{
+		"tracked": tracked,
+		"active":  active,
+		"skipped": atomic.LoadInt64(&backoffSkips),
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"pansou/model"
+	"pansou/plugin"
+	"pansou/util/cache"
+)
+
+// CacheEntryFilter 缓存条目筛选条件
+type CacheEntryFilter struct {
+	Keyword    string // 关键词包含该文本（忽略大小写）
+	SourceType string // tg、plugin或unknown
+	Plugin     string // 搜索范围包含该插件
+	Offset     int
+	Limit      int
+}
+
+// CacheAvailable 判断搜索缓存是否已启用
+func CacheAvailable() bool {
+	return cacheInitialized && enhancedTwoLevelCache != nil
+}
+
+// ListCacheEntries 按最后写入时间倒序列出搜索缓存条目
+func ListCacheEntries(filter CacheEntryFilter) model.CacheEntryList {
+	keywordFilter := strings.ToLower(strings.TrimSpace(filter.Keyword))
+
+	var entries []model.CacheEntry
+	for _, item := range enhancedTwoLevelCache.Entries() {
+		entry := newCacheEntry(item)
+		if keywordFilter != "" && !strings.Contains(entry.Keyword, keywordFilter) {
+			continue
+		}
+		if filter.SourceType != "" && entry.SourceType != filter.SourceType {
+			continue
+		}
+		if filter.Plugin != "" {
+			info, ok := cache.LookupCacheKey(item.Key)
+			if !ok || info.SourceType != "plugin" || !info.HasSource(filter.Plugin) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].AgeSeconds != entries[j].AgeSeconds {
+			return entries[i].AgeSeconds < entries[j].AgeSeconds
+		}
+		return entries[i].Key < entries[j].Key
+	})
+
+	list := model.CacheEntryList{Total: len(entries), Entries: []model.CacheEntry{}}
+	if filter.Offset < len(entries) {
+		entries = entries[filter.Offset:]
+		if filter.Limit > 0 && len(entries) > filter.Limit {
+			entries = entries[:filter.Limit]
+		}
+		list.Entries = entries
+	}
+	return list
+}
+
+// GetCacheEntry 读取并解码一个搜索缓存条目
+func GetCacheEntry(key string) (model.CacheEntryDetail, bool, error) {
+	data, _, ok := enhancedTwoLevelCache.Peek(key)
+	if !ok {
+		return model.CacheEntryDetail{}, false, nil
+	}
+
+	var results []model.SearchResult
+	if err := enhancedTwoLevelCache.GetSerializer().Deserialize(data, &results); err != nil {
+		return model.CacheEntryDetail{}, true, fmt.Errorf("缓存数据解码失败: %v", err)
+	}
+
+	item, ok := enhancedTwoLevelCache.Entry(key)
+	if !ok {
+		// 读取后条目恰好过期或被淘汰
+		return model.CacheEntryDetail{}, false, nil
+	}
+
+	return model.CacheEntryDetail{
+		CacheEntry:  newCacheEntry(item),
+		ResultCount: len(results),
+		Results:     results,
+	}, true, nil
+}
+
+// InvalidateCache 按缓存键、关键词或插件删除搜索缓存
+// 插件搜索的缓存条目合并了多个插件的结果，失效时整条删除，并删除相应插件的API响应缓存，使下次搜索重新请求插件
+func InvalidateCache(req model.CacheInvalidateRequest) model.CacheInvalidateResult {
+	keyword := strings.ToLower(strings.TrimSpace(req.Keyword))
+
+	var keys []string
+	switch {
+	case req.Key != "":
+		keys = []string{req.Key}
+	case req.Plugin != "":
+		keys = cache.FindCacheKeys(func(key string, info cache.CacheKeyInfo) bool {
+			return info.SourceType == "plugin" && info.HasSource(req.Plugin) && (keyword == "" || info.Keyword == keyword)
+		})
+	case keyword != "":
+		keys = cache.FindCacheKeys(func(key string, info cache.CacheKeyInfo) bool {
+			return info.Keyword == keyword
+		})
+	}
+
+	result := model.CacheInvalidateResult{Keys: []string{}}
+	for _, key := range keys {
+		info, indexed := cache.LookupCacheKey(key)
+		if removeCacheEntry(key) {
+			result.Removed++
+			result.Keys = append(result.Keys, key)
+		}
+
+		// 按缓存键失效时，同时删除该条目对应的插件API响应缓存
+		if req.Key != "" && indexed && info.SourceType == "plugin" {
+			if len(info.Sources) == 0 {
+				result.PluginRemoved += plugin.InvalidateAsyncCache("", info.Keyword)
+			}
+			for _, name := range info.Sources {
+				result.PluginRemoved += plugin.InvalidateAsyncCache(name, info.Keyword)
+			}
+		}
+	}
+
+	if req.Key == "" && (req.Plugin != "" || keyword != "") {
+		result.PluginRemoved += plugin.InvalidateAsyncCache(req.Plugin, keyword)
+	}
+	return result
+}
+
+// removeCacheEntry 从两级缓存中删除条目，并丢弃尚未写入磁盘的旧数据
+func removeCacheEntry(key string) bool {
+	existed := enhancedTwoLevelCache.Has(key)
+	if err := enhancedTwoLevelCache.Delete(key); err != nil {
+		fmt.Printf("[缓存管理] 删除缓存失败: %s -> %v\n", key, err)
+	}
+	if globalCacheWriteManager != nil {
+		globalCacheWriteManager.Discard(key)
+	}
+	searchCacheAccess.forget(key)
+	return existed
+}
+
+// CacheStats 汇总两级缓存、分片磁盘缓存、缓存写入管理器、实例组和插件失败退避的统计信息
+func CacheStats() map[string]interface{} {
+	stats := map[string]interface{}{
+		"enabled": CacheAvailable(),
+	}
+	if CacheAvailable() {
+		stats["two_level_cache"] = enhancedTwoLevelCache.GetStats()
+	}
+	if globalCacheWriteManager != nil {
+		stats["write_manager"] = globalCacheWriteManager.GetStats()
+	}
+	if peerGroup != nil {
+		stats["peer_group"] = peerGroup.Stats()
+	}
+	stats["plugin_backoff"] = plugin.SourceBackoffStats()
+	return stats
+}
+
+// ExportCacheSnapshot 将所有未过期的搜索缓存写入压缩快照
+func ExportCacheSnapshot(w io.Writer) (cache.SnapshotStats, error) {
+	return enhancedTwoLevelCache.ExportSnapshot(w)
+}
+
+// ImportCacheSnapshot 从快照恢复搜索缓存，跳过已过期和本地已有更新数据的条目
+func ImportCacheSnapshot(r io.Reader) (cache.SnapshotStats, error) {
+	return enhancedTwoLevelCache.ImportSnapshot(r)
+}
+
+// newCacheEntry 根据缓存键索引补充缓存条目的关键词和来源
+func newCacheEntry(item cache.TwoLevelCacheEntry) model.CacheEntry {
+	now := time.Now()
+	entry := model.CacheEntry{
+		Key:        item.Key,
+		SourceType: "unknown",
+		Size:       item.Size,
+		Tier:       cacheTier(item),
+	}
+	if !item.LastModified.IsZero() {
+		entry.AgeSeconds = int64(now.Sub(item.LastModified).Seconds())
+	}
+	if !item.Expiry.IsZero() {
+		entry.TTLRemaining = int64(item.Expiry.Sub(now).Seconds())
+	}
+	if info, ok := cache.LookupCacheKey(item.Key); ok {
+		entry.Keyword = info.Keyword
+		entry.SourceType = info.SourceType
+		entry.Sources = info.Sources
+	}
+	return entry
+}
+
+func cacheTier(item cache.TwoLevelCacheEntry) string {
+	switch {
+	case item.InMemory && item.OnDisk:
+		return "memory+disk"
+	case item.InMemory:
+		return "memory"
+	default:
+		return "disk"
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+)
+
+// ErrTooManyCheckJobs 执行中或保留的流式检测任务数已达上限
+var ErrTooManyCheckJobs = errors.New("检测任务过多，请稍后再试")
+
+// CheckJob 流式检测任务
+// 任务在后台运行，与发起请求的连接无关；客户端断开后可以凭任务ID重新连接，继续获取剩余结果
+type CheckJob struct {
+	ID string
+
+	mu         sync.Mutex
+	total      int
+	results    []model.CheckStreamResult // 按完成顺序保存的检测结果
+	counts     map[string]int
+	startedAt  time.Time
+	finishedAt time.Time
+	done       bool
+	updated    chan struct{} // 有新结果或任务完成时关闭并替换
+}
+
+// Info 任务基本信息
+func (j *CheckJob) Info() model.CheckJob {
+	return model.CheckJob{JobID: j.ID, Total: j.total}
+}
+
+// Results 返回序号从from开始的已完成结果、任务是否已全部完成，以及下次有更新时会关闭的通道
+func (j *CheckJob) Results(from int) ([]model.CheckStreamResult, bool, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var results []model.CheckStreamResult
+	if from < len(j.results) {
+		results = append(results, j.results[from:]...)
+	}
+	return results, j.done, j.updated
+}
+
+// Summary 任务汇总，任务未完成时FinishedAt为0
+func (j *CheckJob) Summary() model.CheckJobSummary {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	counts := make(map[string]int, len(j.counts))
+	for state, count := range j.counts {
+		counts[state] = count
+	}
+	summary := model.CheckJobSummary{
+		JobID:     j.ID,
+		Total:     j.total,
+		Completed: len(j.results),
+		Counts:    counts,
+		StartedAt: j.startedAt.UnixMilli(),
+	}
+	if j.done {
+		summary.FinishedAt = j.finishedAt.UnixMilli()
+	}
+	return summary
+}
+
+func (j *CheckJob) add(index int, result model.CheckResult) {
+	j.mu.Lock()
+	j.results = append(j.results, model.CheckStreamResult{
+		Seq:    len(j.results),
+		Index:  index,
+		Result: result,
+	})
+	j.counts[result.State]++
+	j.notifyLocked()
+	j.mu.Unlock()
+}
+
+func (j *CheckJob) finish() {
+	j.mu.Lock()
+	j.done = true
+	j.finishedAt = time.Now()
+	j.notifyLocked()
+	j.mu.Unlock()
+}
+
+func (j *CheckJob) notifyLocked() {
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+// expired 任务完成后超过保留时长
+func (j *CheckJob) expired(retention time.Duration) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done && time.Since(j.finishedAt) > retention
+}
+
+// finished 返回任务是否已完成及完成时间
+func (j *CheckJob) finished() (bool, time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done, j.finishedAt
+}
+
+// StartJob 创建流式检测任务并在后台开始检测，结果按完成顺序逐条记录
+// 执行中的任务数达到上限时返回ErrTooManyCheckJobs；保留的任务数达到上限时先丢弃最早完成的任务，仍无空位时同样返回该错误
+func (s *CheckService) StartJob(items []model.CheckItem) (*CheckJob, error) {
+	job := &CheckJob{
+		ID:        newCheckJobID(),
+		total:     len(items),
+		results:   make([]model.CheckStreamResult, 0, len(items)),
+		counts:    make(map[string]int),
+		startedAt: time.Now(),
+		updated:   make(chan struct{}),
+	}
+
+	s.jobsMu.Lock()
+	for id, existing := range s.jobs {
+		if existing.expired(config.AppConfig.CheckJobRetention) {
+			delete(s.jobs, id)
+		}
+	}
+	if !s.reserveJobSlotLocked() {
+		s.jobsMu.Unlock()
+		return nil, ErrTooManyCheckJobs
+	}
+	s.jobs[job.ID] = job
+	s.jobsMu.Unlock()
+
+	go s.runJob(job, items)
+	return job, nil
+}
+
+// reserveJobSlotLocked 检查能否再创建一个任务，保留的任务数已满时丢弃最早完成的任务，调用方需持有jobsMu
+func (s *CheckService) reserveJobSlotLocked() bool {
+	active := 0
+	for _, existing := range s.jobs {
+		if done, _ := existing.finished(); !done {
+			active++
+		}
+	}
+	if active >= config.AppConfig.CheckMaxActiveJobs {
+		return false
+	}
+
+	for len(s.jobs) >= config.AppConfig.CheckMaxJobs {
+		oldestID := ""
+		var oldest time.Time
+		for id, existing := range s.jobs {
+			done, finishedAt := existing.finished()
+			if done && (oldestID == "" || finishedAt.Before(oldest)) {
+				oldestID, oldest = id, finishedAt
+			}
+		}
+		if oldestID == "" {
+			return false
+		}
+		delete(s.jobs, oldestID)
+	}
+	return true
+}
+
+// GetJob 获取未过期的流式检测任务
+func (s *CheckService) GetJob(id string) (*CheckJob, bool) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	if job.expired(config.AppConfig.CheckJobRetention) {
+		delete(s.jobs, id)
+		return nil, false
+	}
+	return job, true
+}
+
+// runJob 以单次检测请求的并发数执行任务，网盘请求同样受按网盘类型的限速约束
+func (s *CheckService) runJob(job *CheckJob, items []model.CheckItem) {
+	forEachIndex(len(items), s.concurrency, func(index int) {
+		job.add(index, s.checkOne(items[index]))
+	})
+
+	job.finish()
+}
+
+func newCheckJobID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
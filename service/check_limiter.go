@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// checkLimiter 按网盘类型限制链接检测的并发数和请求速率，避免请求过快被网盘封禁IP
+// 同一CheckService的所有检测请求共用一个限制器
+type checkLimiter struct {
+	mu          sync.Mutex
+	concurrency map[string]int     // 网盘类型 -> 并发数，键"*"为默认值
+	rates       map[string]float64 // 网盘类型 -> 每秒请求数，键"*"为默认值
+	slots       map[string]chan struct{}
+	buckets     map[string]*tokenBucket
+}
+
+// newCheckLimiter 创建检测限制器
+func newCheckLimiter(concurrency map[string]int, rates map[string]float64) *checkLimiter {
+	return &checkLimiter{
+		concurrency: concurrency,
+		rates:       rates,
+		slots:       make(map[string]chan struct{}),
+		buckets:     make(map[string]*tokenBucket),
+	}
+}
+
+// acquire 等待获取指定网盘类型的检测名额，返回的函数用于释放名额
+func (l *checkLimiter) acquire(ctx context.Context, diskType string) (func(), error) {
+	slots, bucket := l.get(diskType)
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if bucket != nil {
+		if err := bucket.wait(ctx); err != nil {
+			<-slots
+			return nil, err
+		}
+	}
+
+	return func() { <-slots }, nil
+}
+
+// get 获取网盘类型对应的并发槽和令牌桶，首次使用时创建
+func (l *checkLimiter) get(diskType string) (chan struct{}, *tokenBucket) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.slots[diskType]
+	if !ok {
+		limit, exists := l.concurrency[diskType]
+		if !exists {
+			limit = l.concurrency["*"]
+		}
+		if limit <= 0 {
+			limit = 1
+		}
+		slots = make(chan struct{}, limit)
+		l.slots[diskType] = slots
+
+		rate, exists := l.rates[diskType]
+		if !exists {
+			rate = l.rates["*"]
+		}
+		if rate > 0 {
+			l.buckets[diskType] = newTokenBucket(rate)
+		}
+	}
+
+	return slots, l.buckets[diskType]
+}
+
+// tokenBucket 令牌桶限速器
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64 // 令牌桶容量
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 创建令牌桶，容量为每秒令牌数（至少为1），初始为满
+func newTokenBucket(rate float64) *tokenBucket {
+	burst := math.Max(1, math.Floor(rate))
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait 等待获取一个令牌
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -15,25 +15,29 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
 
+	"pansou/config"
 	"pansou/model"
+	"pansou/plugin"
 	utiljson "pansou/util/json"
 	"pansou/util"
+	"pansou/util/linkid"
 )
 
 const (
-	checkStateOK          = "ok"
-	checkStateBad         = "bad"
-	checkStateLocked      = "locked"
-	checkStateUnsupported = "unsupported"
-	checkStateUncertain   = "uncertain"
+	checkStateOK          = plugin.CheckStateOK
+	checkStateBad         = plugin.CheckStateBad
+	checkStateLocked      = plugin.CheckStateLocked
+	checkStateUnsupported = plugin.CheckStateUnsupported
+	checkStateUncertain   = plugin.CheckStateUncertain
 	checkCacheBucketName  = "check_results"
+	// checkSweepInterval 定期清理已过期缓存的间隔
+	checkSweepInterval = 30 * time.Minute
 )
 
 type cachedCheckResult struct {
@@ -53,45 +57,143 @@ type cachedCheckDiskEntry struct {
 }
 
 type CheckService struct {
-	mu        sync.Mutex
-	cache     map[string]cachedCheckResult
-	inflight  map[string]*activeCheckCall
-	client    *http.Client
-	cacheFile string
-	cacheDB   *bolt.DB
+	mu          sync.Mutex
+	cache       map[string]cachedCheckResult
+	inflight    map[string]*activeCheckCall
+	client      *http.Client
+	cacheFile   string
+	cacheDB     *bolt.DB
+	concurrency int                           // 单次检测请求的并发数
+	limiter     *checkLimiter                 // 按网盘类型的并发和速率限制
+	checkers    map[string]plugin.LinkChecker // 内置检测器
+
+	inspectCache map[string]model.ShareInspectResult // 分享内容缓存
+	inspectors   map[string]shareInspector           // 支持查看分享内容的网盘
+
+	statsMu sync.Mutex
+	stats   map[string]model.CheckStatsCounts // 检测统计，键为"类别|日期|名称"
+
+	passwordVerifiers map[string]passwordVerifier // 支持确认提取码的网盘
+	passwordMu        sync.Mutex
+	passwords         map[string]resolvedSharePassword // 确认过的提取码，键为链接去重键
+	passwordPending   map[string]bool                  // 正在后台确认提取码的分享
+	passwordLimiter   *checkLimiter                    // 后台确认提取码的并发和速率限制
+
+	jobsMu sync.Mutex
+	jobs   map[string]*CheckJob // 流式检测任务，完成后保留一段时间供断线重连
 }
 
 func NewCheckService() *CheckService {
 	service := &CheckService{
-		cache:     make(map[string]cachedCheckResult),
-		inflight:  make(map[string]*activeCheckCall),
-		client:    util.GetHTTPClient(),
-		cacheFile: filepath.Join(".", "cache", "check_cache.db"),
-	}
+		cache:       make(map[string]cachedCheckResult),
+		inflight:    make(map[string]*activeCheckCall),
+		client:      util.GetHTTPClient(),
+		cacheFile:   filepath.Join(".", "cache", "check_cache.db"),
+		concurrency: config.AppConfig.CheckConcurrency,
+		limiter:     newCheckLimiter(config.AppConfig.CheckProviderConcurrency, config.AppConfig.CheckProviderRate),
+	}
+	service.checkers = service.builtinCheckers()
+	service.inspectors = service.builtinInspectors()
+	service.inspectCache = make(map[string]model.ShareInspectResult)
+	service.stats = make(map[string]model.CheckStatsCounts)
+	service.passwordVerifiers = service.builtinPasswordVerifiers()
+	service.passwords = make(map[string]resolvedSharePassword)
+	service.passwordPending = make(map[string]bool)
+	service.passwordLimiter = newBackgroundPasswordLimiter()
+	service.jobs = make(map[string]*CheckJob)
 	service.openCacheStore()
 	service.pruneExpiredCacheStore()
+	service.loadCheckStats()
+	service.loadResolvedPasswords()
+	go service.sweepLoop()
 	return service
 }
 
-func (s *CheckService) Check(items []model.CheckItem) model.CheckResponse {
-	results := make([]model.CheckResult, 0, len(items))
+var (
+	globalCheckService     *CheckService
+	globalCheckServiceOnce sync.Once
+)
 
-	for _, item := range items {
-		results = append(results, s.checkOne(item))
-	}
+// GetCheckService 获取全局检测服务，检测接口和后台复检共用同一个检测缓存和限速器
+func GetCheckService() *CheckService {
+	globalCheckServiceOnce.Do(func() {
+		globalCheckService = NewCheckService()
+	})
+	return globalCheckService
+}
+
+// Check 并发检测链接，结果顺序与items一致
+// 实际发起的网盘请求受按网盘类型的并发数和速率限制，缓存命中和合并的重复检测不受限制
+func (s *CheckService) Check(items []model.CheckItem) model.CheckResponse {
+	results := make([]model.CheckResult, len(items))
+	forEachIndex(len(items), s.concurrency, func(index int) {
+		results[index] = s.checkOne(items[index])
+	})
 
 	return model.CheckResponse{
 		Results: results,
 	}
 }
 
+// forEachIndex 以最多workers个协程对0到count-1的下标执行fn，全部完成后返回
+// 下标通过无缓冲通道逐个分发，任务数量不受工作协程数限制
+func forEachIndex(count, workers int, fn func(index int)) {
+	if workers > count {
+		workers = count
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				fn(index)
+			}
+		}()
+	}
+
+	for index := 0; index < count; index++ {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// Supports 判断是否支持检测指定网盘类型的链接（内置或插件注册的检测器）
+func (s *CheckService) Supports(diskType string) bool {
+	_, ok := s.getChecker(diskType)
+	return ok
+}
+
+// Lookup 只从检测缓存（内存和磁盘）中查找链接的检测结果，不会发起网盘请求
+func (s *CheckService) Lookup(item model.CheckItem) (model.CheckResult, bool) {
+	checker, _ := s.getChecker(item.DiskType)
+	normalized, cacheKey, _ := s.normalizeShareLink(checker, item.DiskType, item.URL, item.Password)
+	if normalized == "" {
+		return model.CheckResult{}, false
+	}
+
+	cached, ok := s.getCached(cacheKey)
+	if !ok {
+		return model.CheckResult{}, false
+	}
+	cached.CacheHit = true
+	return cached, true
+}
+
 func (s *CheckService) checkOne(item model.CheckItem) model.CheckResult {
-	normalized := s.normalizeShareLink(item.DiskType, item.URL, item.Password)
+	checker, ok := s.getChecker(item.DiskType)
+	normalized, cacheKey, password := s.normalizeShareLink(checker, item.DiskType, item.URL, item.Password)
 	if normalized == "" {
 		return s.buildResult(item, "", checkStateUncertain, false, "链接格式无效")
 	}
+	if !ok {
+		return s.buildResult(item, normalized, checkStateUnsupported, false, "当前平台暂不支持检测")
+	}
+	item.Password = password
 
-	cacheKey := item.DiskType + "|" + normalized
 	if cached, ok := s.getCached(cacheKey); ok {
 		cached.CacheHit = true
 		return cached
@@ -108,8 +210,17 @@ func (s *CheckService) checkOne(item model.CheckItem) model.CheckResult {
 		return result
 	}
 
-	result, err := s.runCheck(item, normalized)
+	ctx := context.Background()
+	release, err := s.limiter.acquire(ctx, item.DiskType)
+	if err != nil {
+		s.finishInflight(cacheKey, call, model.CheckResult{}, err)
+		return s.buildResult(item, normalized, checkStateUncertain, false, "检测失败")
+	}
+	state, summary, err := checker.Check(ctx, item, normalized)
+	release()
+	result := s.buildResult(item, normalized, state, false, summary)
 	s.finishInflight(cacheKey, call, result, err)
+	s.recordCheck(item, cacheKey, result, err)
 
 	if err != nil {
 		return s.buildResult(item, normalized, checkStateUncertain, false, "检测失败")
@@ -190,40 +301,69 @@ func (s *CheckService) finishInflight(key string, call *activeCheckCall, result
 	}
 }
 
-func (s *CheckService) runCheck(item model.CheckItem, normalized string) (model.CheckResult, error) {
-	switch item.DiskType {
-	case "aliyun":
-		return s.checkAliyun(item, normalized)
-	case "quark":
-		return s.checkQuark(item, normalized)
-	case "uc":
-		return s.checkUC(item, normalized)
-	case "baidu":
-		return s.checkBaidu(item, normalized)
-	case "tianyi":
-		return s.checkTianyi(item, normalized)
-	case "123":
-		return s.check123(item, normalized)
-	case "xunlei":
-		return s.checkXunlei(item, normalized)
-	case "115":
-		return s.check115(item, normalized)
-	case "mobile":
-		return s.checkMobile(item, normalized)
-	default:
-		return s.buildResult(item, normalized, checkStateUnsupported, false, "当前平台暂不支持检测"), nil
+// builtinCheckers 内置网盘的链接检测器
+func (s *CheckService) builtinCheckers() map[string]plugin.LinkChecker {
+	checkers := []*builtinLinkChecker{
+		{name: "aliyun", check: s.checkAliyun},
+		{name: "quark", check: s.checkQuark},
+		{name: "uc", check: s.checkUC},
+		{name: "baidu", check: s.checkBaidu},
+		{name: "tianyi", check: s.checkTianyi},
+		{name: "123", check: s.check123},
+		{name: "xunlei", check: s.checkXunlei},
+		{name: "115", check: s.check115},
+		{name: "mobile", check: s.checkMobile},
+	}
+
+	registry := make(map[string]plugin.LinkChecker, len(checkers))
+	for _, checker := range checkers {
+		registry[checker.name] = checker
+	}
+	return registry
+}
+
+// getChecker 获取网盘类型对应的检测器，插件注册的检测器优先于内置检测器
+func (s *CheckService) getChecker(diskType string) (plugin.LinkChecker, bool) {
+	if checker, ok := plugin.GetLinkChecker(diskType); ok {
+		return checker, true
 	}
+	checker, ok := s.checkers[diskType]
+	return checker, ok
+}
+
+// builtinLinkChecker 内置检测器，把CheckService的检测方法适配为LinkChecker接口
+type builtinLinkChecker struct {
+	name  string
+	check func(ctx context.Context, item model.CheckItem, normalized string) (string, string, error)
 }
 
-func (s *CheckService) checkAliyun(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (c *builtinLinkChecker) Name() string {
+	return c.name
+}
+
+func (c *builtinLinkChecker) Normalize(rawURL, password string) (string, string) {
+	return plugin.NormalizeShareLink(rawURL, password)
+}
+
+func (c *builtinLinkChecker) Check(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
+	return c.check(ctx, item, normalized)
+}
+
+func (s *CheckService) checkAliyun(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
 	shareID := extractAliyunShareID(normalized)
 	if shareID == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return checkStateUncertain, "无法解析分享地址", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	_, state, summary, err := s.fetchAliyunShare(ctx, shareID)
+	return state, summary, err
+}
+
+// fetchAliyunShare 匿名获取阿里云盘分享信息，返回分享标题和链接状态
+func (s *CheckService) fetchAliyunShare(ctx context.Context, shareID string) (string, string, string, error) {
 	body, statusCode, err := s.doJSONRequest(ctx, "POST", "https://api.aliyundrive.com/adrive/v3/share_link/get_share_by_anonymous?share_id="+shareID, map[string]string{
 		"share_id": shareID,
 	}, map[string]string{
@@ -233,7 +373,7 @@ func (s *CheckService) checkAliyun(item model.CheckItem, normalized string) (mod
 		"x-canary":     "client=web,app=share,version=v2.3.1",
 	})
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return "", checkStateUncertain, "请求失败", err
 	}
 
 	var parsed struct {
@@ -246,23 +386,56 @@ func (s *CheckService) checkAliyun(item model.CheckItem, normalized string) (mod
 
 	switch {
 	case statusCode == http.StatusOK && (parsed.ShareName != "" || parsed.ShareTitle != ""):
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+		return coalesce(parsed.ShareTitle, parsed.ShareName), checkStateOK, "链接有效", nil
 	case strings.Contains(parsed.Code, "NotFound"), strings.Contains(parsed.Code, "Cancelled"):
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return "", checkStateBad, "链接失效", nil
 	default:
-		return s.buildResult(item, normalized, checkStateUncertain, false, parsed.Message), nil
+		return "", checkStateUncertain, parsed.Message, nil
 	}
 }
 
-func (s *CheckService) checkQuark(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (s *CheckService) checkQuark(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
 	resourceID, password := extractQuarkShareIDAndPassword(normalized)
 	if resourceID == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return checkStateUncertain, "无法解析分享地址", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	stoken, state, summary, err := s.fetchQuarkStoken(ctx, resourceID, password)
+	if state != "" {
+		return state, summary, err
+	}
+
+	detailURL := fmt.Sprintf("https://drive-pc.quark.cn/1/clouddrive/share/sharepage/detail?pwd_id=%s&stoken=%s&ver=2&pr=ucpro", url.QueryEscape(resourceID), url.QueryEscape(stoken))
+	detailBody, _, err := s.doRequest(ctx, "GET", detailURL, nil, map[string]string{
+		"accept":        "application/json, text/plain, */*",
+		"origin":        "https://pan.quark.cn",
+		"referer":       "https://pan.quark.cn/",
+		"cache-control": "no-cache",
+	})
+	if err != nil {
+		return checkStateUncertain, "详情请求失败", err
+	}
+
+	var detailResp struct {
+		Code int `json:"code"`
+		Data struct {
+			List []any `json:"list"`
+		} `json:"data"`
+	}
+	_ = utiljson.Unmarshal(detailBody, &detailResp)
+
+	if detailResp.Code == 0 && len(detailResp.Data.List) > 0 {
+		return checkStateOK, "链接有效", nil
+	}
+
+	return checkStateUncertain, "无法确认链接状态", nil
+}
+
+// fetchQuarkStoken 获取夸克分享的访问令牌，链接无法访问时返回检测状态和说明
+func (s *CheckService) fetchQuarkStoken(ctx context.Context, resourceID, password string) (string, string, string, error) {
 	tokenBody, _, err := s.doJSONRequest(ctx, "POST", "https://drive-h.quark.cn/1/clouddrive/share/sharepage/token", map[string]any{
 		"pwd_id":                            resourceID,
 		"passcode":                          password,
@@ -273,7 +446,7 @@ func (s *CheckService) checkQuark(item model.CheckItem, normalized string) (mode
 		"referer":      "https://pan.quark.cn/",
 	})
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return "", checkStateUncertain, "请求失败", err
 	}
 
 	var tokenResp struct {
@@ -288,86 +461,81 @@ func (s *CheckService) checkQuark(item model.CheckItem, normalized string) (mode
 	switch tokenResp.Code {
 	case 0:
 	case 41008:
-		return s.buildResult(item, normalized, checkStateLocked, false, "需要提取码"), nil
+		return "", checkStateLocked, "需要提取码", nil
 	case 41004, 41010, 41011:
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return "", checkStateBad, "链接失效", nil
 	default:
 		if containsAny(strings.ToLower(tokenResp.Message), []string{"不存在", "失效", "违规", "过期", "取消"}) {
-			return s.buildResult(item, normalized, checkStateBad, false, tokenResp.Message), nil
+			return "", checkStateBad, tokenResp.Message, nil
 		}
 		if containsAny(strings.ToLower(tokenResp.Message), []string{"提取码", "密码"}) {
-			return s.buildResult(item, normalized, checkStateLocked, false, tokenResp.Message), nil
+			return "", checkStateLocked, tokenResp.Message, nil
 		}
-		return s.buildResult(item, normalized, checkStateUncertain, false, tokenResp.Message), nil
+		return "", checkStateUncertain, tokenResp.Message, nil
 	}
 
 	if tokenResp.Data.Stoken == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "访问令牌缺失"), nil
-	}
-
-	detailURL := fmt.Sprintf("https://drive-pc.quark.cn/1/clouddrive/share/sharepage/detail?pwd_id=%s&stoken=%s&ver=2&pr=ucpro", url.QueryEscape(resourceID), url.QueryEscape(tokenResp.Data.Stoken))
-	detailBody, _, err := s.doRequest(ctx, "GET", detailURL, nil, map[string]string{
-		"accept":        "application/json, text/plain, */*",
-		"origin":        "https://pan.quark.cn",
-		"referer":       "https://pan.quark.cn/",
-		"cache-control": "no-cache",
-	})
-	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "详情请求失败"), err
+		return "", checkStateUncertain, "访问令牌缺失", nil
 	}
-
-	var detailResp struct {
-		Code int `json:"code"`
-		Data struct {
-			List []any `json:"list"`
-		} `json:"data"`
-	}
-	_ = utiljson.Unmarshal(detailBody, &detailResp)
-
-	if detailResp.Code == 0 && len(detailResp.Data.List) > 0 {
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
-	}
-
-	return s.buildResult(item, normalized, checkStateUncertain, false, "无法确认链接状态"), nil
+	return tokenResp.Data.Stoken, "", "", nil
 }
 
-func (s *CheckService) checkUC(item model.CheckItem, normalized string) (model.CheckResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+func (s *CheckService) checkUC(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
 	defer cancel()
 
 	body, statusCode, err := s.doRequest(ctx, "GET", normalized, nil, map[string]string{
 		"user-agent": "Mozilla/5.0 (Linux; Android 10; Mobile) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36",
 	})
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return checkStateUncertain, "请求失败", err
 	}
 
 	if statusCode == http.StatusNotFound {
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return checkStateBad, "链接失效", nil
 	}
 
 	pageText := strings.ToLower(string(body))
 	switch {
 	case containsAny(pageText, []string{"失效", "不存在", "违规", "删除", "已过期", "被取消"}):
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return checkStateBad, "链接失效", nil
 	case containsAny(pageText, []string{"提取码", "访问码", "请输入密码"}):
-		return s.buildResult(item, normalized, checkStateLocked, false, "需要提取码"), nil
+		return checkStateLocked, "需要提取码", nil
 	case containsAny(pageText, []string{"文件", "分享", "drive.uc.cn"}):
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+		return checkStateOK, "链接有效", nil
 	default:
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法确认链接状态"), nil
+		return checkStateUncertain, "无法确认链接状态", nil
 	}
 }
 
-func (s *CheckService) checkBaidu(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (s *CheckService) checkBaidu(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 12*time.Second)
+	defer cancel()
+
+	_, state, summary, err := s.fetchBaiduShareList(ctx, normalized, 20)
+	return state, summary, err
+}
+
+// baiduShareList 百度网盘分享根目录列表
+type baiduShareList struct {
+	Errno  int    `json:"errno"`
+	Errmsg string `json:"errmsg"`
+	Title  string `json:"title"`
+	List   []struct {
+		ServerFilename string `json:"server_filename"`
+		Size           any    `json:"size"`
+		Isdir          any    `json:"isdir"`
+	} `json:"list"`
+}
+
+// fetchBaiduShareList 获取百度网盘分享根目录的前num个条目，同时返回链接状态
+func (s *CheckService) fetchBaiduShareList(ctx context.Context, normalized string, num int) (baiduShareList, string, string, error) {
+	var listResp baiduShareList
 	shareID, shortURL, password := extractBaiduShareInfo(normalized)
 	if shareID == "" || shortURL == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return listResp, checkStateUncertain, "无法解析分享地址", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
-	defer cancel()
-
 	var bdclnd string
 	if password != "" {
 		verifyURL := fmt.Sprintf("https://pan.baidu.com/share/verify?surl=%s&pwd=%s", url.QueryEscape(shortURL), url.QueryEscape(password))
@@ -380,7 +548,7 @@ func (s *CheckService) checkBaidu(item model.CheckItem, normalized string) (mode
 			"content-type": "application/x-www-form-urlencoded",
 		})
 		if err != nil {
-			return s.buildResult(item, normalized, checkStateUncertain, false, "验证失败"), err
+			return listResp, checkStateUncertain, "验证失败", err
 		}
 
 		var verifyResp struct {
@@ -394,13 +562,13 @@ func (s *CheckService) checkBaidu(item model.CheckItem, normalized string) (mode
 		case 0:
 			bdclnd = verifyResp.Randsk
 		case -9, -12:
-			return s.buildResult(item, normalized, checkStateLocked, false, "提取码错误或缺失"), nil
+			return listResp, checkStateLocked, "提取码错误或缺失", nil
 		default:
-			return s.buildResult(item, normalized, checkStateUncertain, false, verifyResp.Errmsg), nil
+			return listResp, checkStateUncertain, verifyResp.Errmsg, nil
 		}
 	}
 
-	listURL := fmt.Sprintf("https://pan.baidu.com/share/list?web=1&page=1&num=20&order=time&desc=1&showempty=0&shorturl=%s&root=1&clienttype=0", url.QueryEscape(shortURL))
+	listURL := fmt.Sprintf("https://pan.baidu.com/share/list?web=1&page=1&num=%d&order=time&desc=1&showempty=0&shorturl=%s&root=1&clienttype=0", num, url.QueryEscape(shortURL))
 	headers := map[string]string{
 		"accept":     "application/json, text/plain, */*",
 		"referer":    normalized,
@@ -412,38 +580,33 @@ func (s *CheckService) checkBaidu(item model.CheckItem, normalized string) (mode
 
 	body, _, err := s.doRequest(ctx, "GET", listURL, nil, headers)
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return listResp, checkStateUncertain, "请求失败", err
 	}
 
-	var listResp struct {
-		Errno  int    `json:"errno"`
-		Errmsg string `json:"errmsg"`
-		List   []any  `json:"list"`
-	}
 	_ = utiljson.Unmarshal(body, &listResp)
 
 	switch listResp.Errno {
 	case 0:
 		if len(listResp.List) > 0 {
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return listResp, checkStateOK, "链接有效", nil
 		}
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return listResp, checkStateBad, "链接失效", nil
 	case -9, -12:
-		return s.buildResult(item, normalized, checkStateLocked, false, "需要提取码"), nil
+		return listResp, checkStateLocked, "需要提取码", nil
 	case -7, 105, 115, 117, 145:
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return listResp, checkStateBad, "链接失效", nil
 	default:
-		return s.buildResult(item, normalized, checkStateUncertain, false, listResp.Errmsg), nil
+		return listResp, checkStateUncertain, listResp.Errmsg, nil
 	}
 }
 
-func (s *CheckService) checkTianyi(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (s *CheckService) checkTianyi(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
 	shareCode, password, referer := extractTianyiShareInfo(normalized, item.Password)
 	if shareCode == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return checkStateUncertain, "无法解析分享地址", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	noCache := fmt.Sprintf("%f", rand.New(rand.NewSource(time.Now().UnixNano())).Float64())
@@ -455,7 +618,7 @@ func (s *CheckService) checkTianyi(item model.CheckItem, normalized string) (mod
 	apiURL := "https://cloud.189.cn/api/open/share/getShareInfoByCodeV2.action"
 	targetURL, err := url.Parse(apiURL)
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求地址构造失败"), err
+		return checkStateUncertain, "请求地址构造失败", err
 	}
 
 	query := targetURL.Query()
@@ -468,7 +631,7 @@ func (s *CheckService) checkTianyi(item model.CheckItem, normalized string) (mod
 		"sign-type": "1",
 	})
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return checkStateUncertain, "请求失败", err
 	}
 
 	bodyText := strings.TrimSpace(string(body))
@@ -483,11 +646,11 @@ func (s *CheckService) checkTianyi(item model.CheckItem, normalized string) (mod
 	if err := xml.Unmarshal(body, &shareResponse); err == nil && shareResponse.XMLName.Local == "shareVO" {
 		switch {
 		case shareResponse.ShareID > 0:
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return checkStateOK, "链接有效", nil
 		case shareResponse.FileName != "":
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return checkStateOK, "链接有效", nil
 		case shareResponse.NeedAccessCode == 1:
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return checkStateOK, "链接有效", nil
 		}
 	}
 
@@ -502,48 +665,48 @@ func (s *CheckService) checkTianyi(item model.CheckItem, normalized string) (mod
 
 		switch {
 		case containsAny(messageLower, []string{"accesscode", "访问码", "提取码", "密码"}):
-			return s.buildResult(item, normalized, checkStateLocked, false, message), nil
+			return checkStateLocked, message, nil
 		case containsAny(messageLower, []string{"shareinfonotfound", "sharenotfound", "filenotfound", "shareexpirederror", "shareauditnotpass", "不存在", "失效", "取消", "过期"}):
-			return s.buildResult(item, normalized, checkStateBad, false, message), nil
+			return checkStateBad, message, nil
 		}
-		return s.buildResult(item, normalized, checkStateBad, false, message), nil
+		return checkStateBad, message, nil
 	}
 
 	switch {
 	case statusCode == http.StatusOK && strings.Contains(bodyText, "<shareVO>"):
 		if strings.Contains(bodyText, "<shareId>") || strings.Contains(bodyText, "<fileName>") {
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return checkStateOK, "链接有效", nil
 		}
 		if strings.Contains(bodyText, "<needAccessCode>1</needAccessCode>") {
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return checkStateOK, "链接有效", nil
 		}
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法确认链接状态"), nil
+		return checkStateUncertain, "无法确认链接状态", nil
 	case containsAny(strings.ToLower(bodyText), []string{"erroraccesscode", "needaccesscode", "访问码", "提取码", "密码"}):
-		return s.buildResult(item, normalized, checkStateLocked, false, "需要访问码"), nil
+		return checkStateLocked, "需要访问码", nil
 	case containsAny(strings.ToLower(bodyText), []string{"shareinfonotfound", "sharenotfound", "filenotfound", "shareexpirederror", "shareauditnotpass", "不存在", "失效", "取消", "过期"}):
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return checkStateBad, "链接失效", nil
 	default:
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法确认链接状态"), nil
+		return checkStateUncertain, "无法确认链接状态", nil
 	}
 }
 
-func (s *CheckService) check123(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (s *CheckService) check123(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
 	shareKey := extract123ShareKey(normalized)
 	if shareKey == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return checkStateUncertain, "无法解析分享地址", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
 	defer cancel()
 
 	apiURL := fmt.Sprintf("https://www.123pan.com/api/share/info?shareKey=%s", url.QueryEscape(shareKey))
 	body, statusCode, err := s.doRequest(ctx, "GET", apiURL, nil, nil)
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return checkStateUncertain, "请求失败", err
 	}
 
 	if statusCode == http.StatusForbidden {
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+		return checkStateOK, "链接有效", nil
 	}
 
 	var response struct {
@@ -554,28 +717,28 @@ func (s *CheckService) check123(item model.CheckItem, normalized string) (model.
 		Message string `json:"message"`
 	}
 	if err := utiljson.Unmarshal(body, &response); err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "响应解析失败"), nil
+		return checkStateUncertain, "响应解析失败", nil
 	}
 
 	switch {
 	case response.Code == 0:
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+		return checkStateOK, "链接有效", nil
 	case response.Data.HasPwd:
-		return s.buildResult(item, normalized, checkStateLocked, false, "需要提取码"), nil
+		return checkStateLocked, "需要提取码", nil
 	case response.Message != "":
-		return s.buildResult(item, normalized, checkStateBad, false, response.Message), nil
+		return checkStateBad, response.Message, nil
 	default:
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return checkStateBad, "链接失效", nil
 	}
 }
 
-func (s *CheckService) checkXunlei(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (s *CheckService) checkXunlei(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
 	shareID, password := extractXunleiShareInfo(normalized)
 	if shareID == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return checkStateUncertain, "无法解析分享地址", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 12*time.Second)
 	defer cancel()
 
 	captchaToken, _ := s.fetchXunleiCaptchaToken(ctx)
@@ -599,13 +762,13 @@ func (s *CheckService) checkXunlei(item model.CheckItem, normalized string) (mod
 
 	body, statusCode, err := s.doRequest(ctx, "GET", apiURL, nil, headers)
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return checkStateUncertain, "请求失败", err
 	}
 
 	body, _ = decompressResponseBody(body, headers["accept-encoding"], "")
 
 	if statusCode == http.StatusNotFound || statusCode == http.StatusForbidden {
-		return s.buildResult(item, normalized, checkStateBad, false, "链接失效"), nil
+		return checkStateBad, "链接失效", nil
 	}
 
 	var response struct {
@@ -620,50 +783,50 @@ func (s *CheckService) checkXunlei(item model.CheckItem, normalized string) (mod
 		ShareStatusText string `json:"share_status_text"`
 	}
 	if err := utiljson.Unmarshal(body, &response); err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "响应解析失败"), nil
+		return checkStateUncertain, "响应解析失败", nil
 	}
 
 	switch {
 	case response.ShareStatus == "OK":
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+		return checkStateOK, "链接有效", nil
 	case response.ShareID != "", response.ShareName != "", response.FileCount > 0:
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+		return checkStateOK, "链接有效", nil
 	case containsAny(strings.ToLower(response.Error), []string{"pass_code"}), containsAny(strings.ToLower(response.ErrorMsg), []string{"pass_code", "提取码", "密码"}):
-		return s.buildResult(item, normalized, checkStateLocked, false, coalesce(response.ErrorMsg, "需要提取码")), nil
+		return checkStateLocked, coalesce(response.ErrorMsg, "需要提取码"), nil
 	case containsAny(strings.ToLower(response.ShareStatus), []string{"pass_code"}), containsAny(strings.ToLower(response.ShareStatusText), []string{"pass_code", "提取码", "密码"}):
-		return s.buildResult(item, normalized, checkStateLocked, false, coalesce(response.ShareStatusText, "需要提取码")), nil
+		return checkStateLocked, coalesce(response.ShareStatusText, "需要提取码"), nil
 	case response.ShareStatus != "" && response.ShareStatus != "OK":
 		summary := coalesce(response.ShareStatusText, fmt.Sprintf("分享状态: %s", response.ShareStatus))
 		if containsAny(strings.ToLower(summary), []string{"不存在", "失效", "过期", "not found", "deleted"}) {
-			return s.buildResult(item, normalized, checkStateBad, false, summary), nil
+			return checkStateBad, summary, nil
 		}
-		return s.buildResult(item, normalized, checkStateBad, false, summary), nil
+		return checkStateBad, summary, nil
 	case response.ErrorCode != 0 || response.Error != "" || response.ErrorMsg != "":
 		if containsAny(strings.ToLower(response.ErrorMsg), []string{"参数错误", "share_status", "不存在", "失效", "过期", "not found"}) {
-			return s.buildResult(item, normalized, checkStateBad, false, coalesce(response.ErrorMsg, "链接失效")), nil
+			return checkStateBad, coalesce(response.ErrorMsg, "链接失效"), nil
 		}
 		if containsAny(strings.ToLower(response.Error), []string{"参数错误", "share_status", "不存在", "失效", "过期", "not found"}) {
-			return s.buildResult(item, normalized, checkStateBad, false, coalesce(response.ErrorMsg, response.Error)), nil
+			return checkStateBad, coalesce(response.ErrorMsg, response.Error), nil
 		}
 		if containsAny(strings.ToLower(response.ErrorMsg), []string{"not found", "不存在", "失效", "过期"}) {
-			return s.buildResult(item, normalized, checkStateBad, false, coalesce(response.ErrorMsg, "链接失效")), nil
+			return checkStateBad, coalesce(response.ErrorMsg, "链接失效"), nil
 		}
-		return s.buildResult(item, normalized, checkStateUncertain, false, coalesce(response.ErrorMsg, response.Error)), nil
+		return checkStateUncertain, coalesce(response.ErrorMsg, response.Error), nil
 	default:
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法确认链接状态"), nil
+		return checkStateUncertain, "无法确认链接状态", nil
 	}
 }
 
-func (s *CheckService) check115(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (s *CheckService) check115(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
 	shareCode, password := extract115ShareInfo(normalized, item.Password)
 	if shareCode == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return checkStateUncertain, "无法解析分享地址", nil
 	}
 	if password == "" {
-		return s.buildResult(item, normalized, checkStateLocked, false, "115 需要提取码"), nil
+		return checkStateLocked, "115 需要提取码", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	apiURL := fmt.Sprintf("https://115cdn.com/webapi/share/snap?share_code=%s&offset=0&limit=20&receive_code=%s&cid=",
@@ -681,7 +844,7 @@ func (s *CheckService) check115(item model.CheckItem, normalized string) (model.
 		"sec-fetch-site":      "same-origin",
 	})
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return checkStateUncertain, "请求失败", err
 	}
 
 	var response struct {
@@ -701,12 +864,12 @@ func (s *CheckService) check115(item model.CheckItem, normalized string) (model.
 		} `json:"data"`
 	}
 	if err := utiljson.Unmarshal(body, &response); err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "响应解析失败"), nil
+		return checkStateUncertain, "响应解析失败", nil
 	}
 
 	if response.State && response.Errno == 0 {
 		if len(response.Data.List) > 0 || response.Data.Count > 0 || response.Data.ShareInfo.SnapID != "" || response.Data.ShareInfo.ShareTitle != "" {
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return checkStateOK, "链接有效", nil
 		}
 
 		shareState := response.Data.ShareState
@@ -715,7 +878,7 @@ func (s *CheckService) check115(item model.CheckItem, normalized string) (model.
 		}
 
 		if shareState == 1 {
-			return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+			return checkStateOK, "链接有效", nil
 		}
 
 		reason := strings.TrimSpace(response.Data.ShareInfo.ForbidReason)
@@ -723,33 +886,33 @@ func (s *CheckService) check115(item model.CheckItem, normalized string) (model.
 			reason = fmt.Sprintf("链接状态异常(share_state=%d)", shareState)
 		}
 		if containsAny(strings.ToLower(reason), []string{"密码", "提取码"}) {
-			return s.buildResult(item, normalized, checkStateLocked, false, reason), nil
+			return checkStateLocked, reason, nil
 		}
-		return s.buildResult(item, normalized, checkStateBad, false, reason), nil
+		return checkStateBad, reason, nil
 	}
 
 	if containsAny(strings.ToLower(response.Error), []string{"密码", "提取码", "receive_code"}) {
-		return s.buildResult(item, normalized, checkStateLocked, false, coalesce(response.Error, "需要提取码")), nil
+		return checkStateLocked, coalesce(response.Error, "需要提取码"), nil
 	}
 
 	if containsAny(strings.ToLower(response.Error), []string{"参数错误", "不存在", "失效", "share_code", "forbid", "forbidden", "违规", "删除", "取消"}) {
-		return s.buildResult(item, normalized, checkStateBad, false, coalesce(response.Error, "链接失效")), nil
+		return checkStateBad, coalesce(response.Error, "链接失效"), nil
 	}
 
 	if response.Error == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法确认链接状态"), nil
+		return checkStateUncertain, "无法确认链接状态", nil
 	}
 
-	return s.buildResult(item, normalized, checkStateBad, false, response.Error), nil
+	return checkStateBad, response.Error, nil
 }
 
-func (s *CheckService) checkMobile(item model.CheckItem, normalized string) (model.CheckResult, error) {
+func (s *CheckService) checkMobile(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
 	shareID := extractMobileShareID(normalized)
 	if shareID == "" {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法解析分享地址"), nil
+		return checkStateUncertain, "无法解析分享地址", nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	requestPayload := map[string]any{
@@ -772,12 +935,12 @@ func (s *CheckService) checkMobile(item model.CheckItem, normalized string) (mod
 
 	encrypted, err := encryptMobilePayload(requestPayload)
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求加密失败"), err
+		return checkStateUncertain, "请求加密失败", err
 	}
 
 	requestBody, err := utiljson.Marshal(encrypted)
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求序列化失败"), err
+		return checkStateUncertain, "请求序列化失败", err
 	}
 
 	body, _, err := s.doRequest(ctx, "POST", "https://share-kd-njs.yun.139.com/yun-share/richlifeApp/devapp/IOutLink/getOutLinkInfoV6", strings.NewReader(string(requestBody)), map[string]string{
@@ -787,17 +950,17 @@ func (s *CheckService) checkMobile(item model.CheckItem, normalized string) (mod
 		"x-deviceinfo":  "||3|12.27.0|chrome|131.0.0.0|5c7c68368f048245e1ce47f1c0f8f2d0||windows 10|1536X695|zh-CN|||",
 	})
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "请求失败"), err
+		return checkStateUncertain, "请求失败", err
 	}
 
 	decrypted, err := decryptMobilePayload(string(body))
 	if err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "响应解密失败"), nil
+		return checkStateUncertain, "响应解密失败", nil
 	}
 
 	var response map[string]any
 	if err := utiljson.Unmarshal([]byte(decrypted), &response); err != nil {
-		return s.buildResult(item, normalized, checkStateUncertain, false, "响应解析失败"), nil
+		return checkStateUncertain, "响应解析失败", nil
 	}
 
 	resultCode, _ := response["resultCode"].(string)
@@ -806,18 +969,18 @@ func (s *CheckService) checkMobile(item model.CheckItem, normalized string) (mod
 
 	switch {
 	case resultCode == "0" && data != nil:
-		return s.buildResult(item, normalized, checkStateOK, false, "链接有效"), nil
+		return checkStateOK, "链接有效", nil
 	case containsAny(strings.ToLower(description), []string{"提取码", "密码", "访问码"}):
-		return s.buildResult(item, normalized, checkStateLocked, false, coalesce(description, "需要提取码")), nil
+		return checkStateLocked, coalesce(description, "需要提取码"), nil
 	case description != "":
 		if containsAny(strings.ToLower(description), []string{"失效", "不存在", "过期", "取消"}) {
-			return s.buildResult(item, normalized, checkStateBad, false, description), nil
+			return checkStateBad, description, nil
 		}
-		return s.buildResult(item, normalized, checkStateUncertain, false, description), nil
+		return checkStateUncertain, description, nil
 	case resultCode != "":
-		return s.buildResult(item, normalized, checkStateBad, false, "错误码: "+resultCode), nil
+		return checkStateBad, "错误码: "+resultCode, nil
 	default:
-		return s.buildResult(item, normalized, checkStateUncertain, false, "无法确认链接状态"), nil
+		return checkStateUncertain, "无法确认链接状态", nil
 	}
 }
 
@@ -930,32 +1093,23 @@ func (s *CheckService) buildResult(item model.CheckItem, normalized string, stat
 	}
 }
 
-func (s *CheckService) normalizeShareLink(diskType, rawURL, password string) string {
-	base := strings.TrimSpace(rawURL)
-	if base == "" {
-		return ""
+// normalizeShareLink 使用检测器规范化分享链接，返回规范URL、缓存键和最终使用的提取码
+// 可解析的链接按“网盘类型|分享ID|提取码”作为缓存键，同一分享的不同写法共用检测结果
+func (s *CheckService) normalizeShareLink(checker plugin.LinkChecker, diskType, rawURL, password string) (string, string, string) {
+	var normalized string
+	if checker != nil {
+		normalized, password = checker.Normalize(rawURL, password)
+	} else {
+		normalized, password = plugin.NormalizeShareLink(rawURL, password)
 	}
-
-	parsed, err := url.Parse(base)
-	if err != nil {
-		return base
+	if normalized == "" {
+		return "", "", password
 	}
 
-	parsed.Fragment = ""
-	parsed.Host = strings.ToLower(parsed.Host)
-
-	query := parsed.Query()
-	if password != "" {
-		switch diskType {
-		case "baidu", "quark", "uc":
-			if query.Get("pwd") == "" {
-				query.Set("pwd", password)
-			}
-		}
+	if id, ok := linkid.Parse(normalized); ok && id.Provider == diskType {
+		return normalized, diskType + "|" + id.Key() + "|" + password, password
 	}
-
-	parsed.RawQuery = query.Encode()
-	return parsed.String()
+	return normalized, diskType + "|" + normalized, password
 }
 
 func ttlForState(state string) time.Duration {
@@ -988,8 +1142,12 @@ func (s *CheckService) openCacheStore() {
 	}
 
 	if err := db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(checkCacheBucketName))
-		return err
+		for _, name := range []string{checkCacheBucketName, shareContentBucketName, checkHistoryBucketName, checkStatsBucketName, sharePasswordBucketName} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
 	}); err != nil {
 		_ = db.Close()
 		return
@@ -1088,6 +1246,7 @@ func (s *CheckService) deletePersistentCache(key string) {
 	})
 }
 
+// pruneExpiredCacheStore 删除持久化存储中已过期的检测结果、分享内容和提取码记录
 func (s *CheckService) pruneExpiredCacheStore() {
 	if s.cacheDB == nil {
 		return
@@ -1095,30 +1254,85 @@ func (s *CheckService) pruneExpiredCacheStore() {
 
 	now := time.Now()
 	_ = s.cacheDB.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(checkCacheBucketName))
-		if bucket == nil {
-			return nil
+		err := pruneBucket(tx, checkCacheBucketName, func(key, value []byte) bool {
+			entry, err := decodeCachedCheckEntry(value)
+			return err != nil || now.After(entry.expiresAt)
+		})
+		if err != nil {
+			return err
 		}
 
-		var staleKeys [][]byte
-		_ = bucket.ForEach(func(key, value []byte) error {
-			entry, err := decodeCachedCheckEntry(value)
-			if err != nil || now.After(entry.expiresAt) {
-				keyCopy := make([]byte, len(key))
-				copy(keyCopy, key)
-				staleKeys = append(staleKeys, keyCopy)
+		err = pruneBucket(tx, shareContentBucketName, func(key, value []byte) bool {
+			var result model.ShareInspectResult
+			if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&result); err != nil {
+				return true
 			}
-			return nil
+			return now.UnixMilli() > result.ExpiresAt
 		})
+		if err != nil {
+			return err
+		}
 
-		for _, key := range staleKeys {
-			if err := bucket.Delete(key); err != nil {
-				return err
+		return pruneBucket(tx, sharePasswordBucketName, func(key, value []byte) bool {
+			var entry resolvedSharePassword
+			if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&entry); err != nil {
+				return true
 			}
-		}
+			return now.Sub(time.UnixMilli(entry.ResolvedAt)) > sharePasswordTTL
+		})
+	})
+}
 
+// pruneBucket 删除bucket中expired返回true的条目
+func pruneBucket(tx *bolt.Tx, name string, expired func(key, value []byte) bool) error {
+	bucket := tx.Bucket([]byte(name))
+	if bucket == nil {
+		return nil
+	}
+
+	var staleKeys [][]byte
+	_ = bucket.ForEach(func(key, value []byte) error {
+		if expired(key, value) {
+			keyCopy := make([]byte, len(key))
+			copy(keyCopy, key)
+			staleKeys = append(staleKeys, keyCopy)
+		}
 		return nil
 	})
+
+	for _, key := range staleKeys {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sweepLoop 定期清理已过期的内存缓存和持久化缓存，避免只在再次访问时才清理的条目一直累积
+func (s *CheckService) sweepLoop() {
+	ticker := time.NewTicker(checkSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.sweepExpired()
+	}
+}
+
+// sweepExpired 清理一轮已过期的缓存
+func (s *CheckService) sweepExpired() {
+	now := time.Now().UnixMilli()
+
+	s.mu.Lock()
+	for key, result := range s.inspectCache {
+		if now > result.ExpiresAt {
+			delete(s.inspectCache, key)
+		}
+	}
+	s.mu.Unlock()
+
+	s.sweepResolvedPasswords()
+	s.pruneExpiredCacheStore()
+	s.pruneCheckStats()
 }
 
 func buildXunleiCaptchaSignature(clientID, clientVersion, packageName, deviceID string) (string, string) {
@@ -1190,174 +1404,56 @@ func decompressResponseBody(raw []byte, acceptedEncoding string, contentEncoding
 }
 
 func extractAliyunShareID(rawURL string) string {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return ""
-	}
-
-	pathParts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
-	if len(pathParts) == 0 {
-		return ""
-	}
-
-	return pathParts[len(pathParts)-1]
+	id, _ := linkid.Parse(rawURL)
+	return id.ShareID
 }
 
 func extractQuarkShareIDAndPassword(rawURL string) (string, string) {
-	re := regexp.MustCompile(`/s/([A-Za-z0-9]+)`)
-	matches := re.FindStringSubmatch(rawURL)
-	if len(matches) < 2 {
-		return "", ""
-	}
-
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return matches[1], ""
-	}
-
-	return matches[1], parsed.Query().Get("pwd")
+	id, _ := linkid.Parse(rawURL)
+	return id.ShareID, id.Password
 }
 
 func extractBaiduShareInfo(rawURL string) (string, string, string) {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
+	id, ok := linkid.Parse(rawURL)
+	if !ok {
 		return "", "", ""
 	}
 
-	queryPwd := parsed.Query().Get("pwd")
-
-	if strings.HasPrefix(parsed.Path, "/s/") {
-		shareID := strings.TrimPrefix(parsed.Path, "/s/")
-		shortURL := shareID
-		if strings.HasPrefix(shortURL, "1") && len(shortURL) > 1 {
-			shortURL = shortURL[1:]
-		}
-		return shareID, shortURL, queryPwd
+	// 接口使用的短链接省略了分享ID开头的"1"
+	shortURL := id.ShareID
+	if strings.HasPrefix(shortURL, "1") && len(shortURL) > 1 {
+		shortURL = shortURL[1:]
 	}
-
-	if strings.HasPrefix(parsed.Path, "/share/init") {
-		shareID := parsed.Query().Get("surl")
-		shortURL := shareID
-		if strings.HasPrefix(shortURL, "1") && len(shortURL) > 1 {
-			shortURL = shortURL[1:]
-		}
-		return shareID, shortURL, queryPwd
-	}
-
-	return "", "", queryPwd
+	return id.ShareID, shortURL, id.Password
 }
 
 func extractTianyiShareInfo(rawURL string, fallbackPassword string) (string, string, string) {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
+	id, ok := linkid.ParseWithPassword(rawURL, fallbackPassword)
+	if !ok {
 		return "", fallbackPassword, rawURL
 	}
-
-	shareCode := parsed.Query().Get("code")
-	if shareCode == "" && strings.HasPrefix(parsed.Path, "/t/") {
-		shareCode = strings.TrimPrefix(parsed.Path, "/t/")
-	}
-	if shareCode == "" && strings.HasPrefix(parsed.Fragment, "/t/") {
-		shareCode = strings.TrimPrefix(parsed.Fragment, "/t/")
-	}
-
-	if index := strings.Index(shareCode, "/"); index >= 0 {
-		shareCode = shareCode[:index]
-	}
-
-	password := fallbackPassword
-	re := regexp.MustCompile(`（访问码[：:]\s*([a-zA-Z0-9]+)）`)
-	matches := re.FindStringSubmatch(rawURL)
-	if len(matches) >= 2 && matches[1] != "" {
-		password = matches[1]
-	}
-
-	return shareCode, password, rawURL
+	return id.ShareID, id.Password, rawURL
 }
 
 func extract123ShareKey(rawURL string) string {
-	patterns := []string{
-		`https?://(?:www\.)?(?:123684|123685|123912|123pan|123592|123865)\.com/s/([a-zA-Z0-9-]+)`,
-		`https?://(?:www\.)?123pan\.cn/s/([a-zA-Z0-9-]+)`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(rawURL)
-		if len(matches) >= 2 {
-			return matches[1]
-		}
-	}
-
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return ""
-	}
-
-	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
-	if len(parts) == 0 {
-		return ""
-	}
-
-	return parts[len(parts)-1]
+	id, _ := linkid.Parse(rawURL)
+	return id.ShareID
 }
 
 func extractXunleiShareInfo(rawURL string) (string, string) {
-	re := regexp.MustCompile(`pan\.xunlei\.com/s/([^?/#]+)`)
-	matches := re.FindStringSubmatch(rawURL)
-	if len(matches) < 2 {
-		return "", ""
-	}
-
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return matches[1], ""
-	}
-
-	return matches[1], parsed.Query().Get("pwd")
+	id, _ := linkid.Parse(rawURL)
+	return id.ShareID, id.Password
 }
 
 func extract115ShareInfo(rawURL string, fallbackPassword string) (string, string) {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return "", fallbackPassword
-	}
-
-	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
-	if len(parts) == 0 {
+	id, ok := linkid.ParseWithPassword(rawURL, fallbackPassword)
+	if !ok {
 		return "", fallbackPassword
 	}
-
-	shareCode := parts[len(parts)-1]
-	password := parsed.Query().Get("password")
-	if password == "" {
-		password = fallbackPassword
-	}
-
-	if password == "" && parsed.Fragment != "" && strings.Contains(parsed.Fragment, "password=") {
-		if values, err := url.ParseQuery(parsed.Fragment); err == nil {
-			password = values.Get("password")
-		}
-	}
-
-	return shareCode, password
+	return id.ShareID, id.Password
 }
 
 func extractMobileShareID(rawURL string) string {
-	patterns := []string{
-		`https?://(?:www\.)?yun\.139\.com/shareweb/#/w/i/([^&/?#]+)`,
-		`https?://(?:www\.)?caiyun\.139\.com/w/i/([^&/?#]+)`,
-		`https?://(?:www\.)?caiyun\.139\.com/m/i\?([^&/?#]+)`,
-		`https?://caiyun\.feixin\.10086\.cn/([^&/?#]+)`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(rawURL)
-		if len(matches) >= 2 {
-			return matches[1]
-		}
-	}
-
-	return ""
+	id, _ := linkid.Parse(rawURL)
+	return id.ShareID
 }
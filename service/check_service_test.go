@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+)
+
+// newTestCheckService 创建不读写磁盘缓存的检测服务，quark链接使用check检测
+func newTestCheckService(t *testing.T, check func(ctx context.Context, item model.CheckItem, normalized string) (string, string, error)) *CheckService {
+	t.Helper()
+	config.Init()
+	return &CheckService{
+		cache:       make(map[string]cachedCheckResult),
+		inflight:    make(map[string]*activeCheckCall),
+		concurrency: config.AppConfig.CheckConcurrency,
+		limiter:     newCheckLimiter(map[string]int{"*": config.AppConfig.CheckConcurrency}, nil),
+		checkers: map[string]plugin.LinkChecker{
+			"quark": &builtinLinkChecker{name: "quark", check: check},
+		},
+		stats: make(map[string]model.CheckStatsCounts),
+	}
+}
+
+// testCheckItems 生成count个不同的夸克分享链接
+func testCheckItems(count int) []model.CheckItem {
+	items := make([]model.CheckItem, count)
+	for i := range items {
+		items[i] = model.CheckItem{DiskType: "quark", URL: fmt.Sprintf("https://pan.quark.cn/s/abc%04d", i)}
+	}
+	return items
+}
+
+func TestCheckMaxBatchSize(t *testing.T) {
+	var calls int32
+	s := newTestCheckService(t, func(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
+		atomic.AddInt32(&calls, 1)
+		return checkStateOK, "", nil
+	})
+	items := testCheckItems(config.AppConfig.CheckMaxBatchSize)
+
+	done := make(chan model.CheckResponse, 1)
+	go func() { done <- s.Check(items) }()
+
+	select {
+	case resp := <-done:
+		if len(resp.Results) != len(items) {
+			t.Fatalf("结果数 = %d，期望 %d", len(resp.Results), len(items))
+		}
+		for i, result := range resp.Results {
+			if result.URL != items[i].URL || result.State != checkStateOK {
+				t.Fatalf("第%d个结果 = %+v，期望 %s 有效", i, result, items[i].URL)
+			}
+		}
+		if int(calls) != len(items) {
+			t.Fatalf("检测次数 = %d，期望 %d", calls, len(items))
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("检测 %d 个链接超时未返回", len(items))
+	}
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/plugin"
+)
+
+const (
+	checkHistoryBucketName = "check_history"
+	checkStatsBucketName   = "check_stats"
+	// maxCheckHistory 每个链接最多保留的状态变化记录数
+	maxCheckHistory = 20
+	// checkStatsRetentionDays 检测统计保留的天数
+	checkStatsRetentionDays = 30
+	checkStatsDateLayout    = "2006-01-02"
+
+	checkStatsKindProvider = "provider"
+	checkStatsKindSource   = "source"
+)
+
+// checkStatsKey 统计键：类别|日期|网盘类型或来源
+func checkStatsKey(kind, date, name string) string {
+	return kind + "|" + date + "|" + name
+}
+
+// KnownCheckSource 判断链接来源是否为已注册的插件（plugin:插件名）或默认搜索的频道（tg:频道名）
+// 公开检测请求中的来源由客户端提交，只接受这些来源，避免任意来源不断新增统计记录
+func KnownCheckSource(source string) bool {
+	kind, name, ok := strings.Cut(source, ":")
+	if !ok || name == "" {
+		return false
+	}
+
+	switch kind {
+	case "plugin":
+		_, exists := plugin.GetPluginByName(name)
+		return exists
+	case "tg":
+		for _, channel := range config.AppConfig.DefaultChannels {
+			if strings.EqualFold(strings.TrimSpace(channel), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordCheck 记录一次实际发起的检测：更新按网盘和按来源的统计，状态变化时追加检测历史
+func (s *CheckService) recordCheck(item model.CheckItem, cacheKey string, result model.CheckResult, checkErr error) {
+	date := time.UnixMilli(result.CheckedAt).Format(checkStatsDateLayout)
+
+	keys := []string{checkStatsKey(checkStatsKindProvider, date, item.DiskType)}
+	if item.Source != "" {
+		keys = append(keys, checkStatsKey(checkStatsKindSource, date, item.Source))
+	}
+
+	updated := make(map[string]model.CheckStatsCounts, len(keys))
+	s.statsMu.Lock()
+	for _, key := range keys {
+		counts := s.stats[key]
+		counts.Total++
+		switch result.State {
+		case checkStateOK:
+			counts.OK++
+		case checkStateBad:
+			counts.Bad++
+		case checkStateLocked:
+			counts.Locked++
+		case checkStateUnsupported:
+			counts.Unsupported++
+		default:
+			counts.Uncertain++
+		}
+		if checkErr != nil {
+			counts.Errors++
+		}
+		s.stats[key] = counts
+		updated[key] = counts
+	}
+	s.statsMu.Unlock()
+
+	s.saveCheckStats(updated)
+
+	// 检测失败的结果不代表链接状态，不写入历史
+	if checkErr == nil {
+		s.appendCheckHistory(cacheKey, model.CheckHistoryEntry{
+			State:     result.State,
+			Summary:   result.Summary,
+			Source:    item.Source,
+			CheckedAt: result.CheckedAt,
+		})
+	}
+}
+
+// Stats 汇总最近days天按网盘类型和按来源的检测统计，来源按检测次数倒序最多返回limit个
+func (s *CheckService) Stats(days, limit int) model.CheckStatsResponse {
+	if days <= 0 || days > checkStatsRetentionDays {
+		days = checkStatsRetentionDays
+	}
+	since := time.Now().AddDate(0, 0, -(days - 1)).Format(checkStatsDateLayout)
+
+	groups := map[string]map[string]*model.CheckStatsGroup{
+		checkStatsKindProvider: {},
+		checkStatsKindSource:   {},
+	}
+
+	s.statsMu.Lock()
+	for key, counts := range s.stats {
+		parts := strings.SplitN(key, "|", 3)
+		if len(parts) != 3 || parts[1] < since {
+			continue
+		}
+		kind, date, name := parts[0], parts[1], parts[2]
+		byName, ok := groups[kind]
+		if !ok {
+			continue
+		}
+
+		group, ok := byName[name]
+		if !ok {
+			group = &model.CheckStatsGroup{Name: name}
+			byName[name] = group
+		}
+		addCheckStatsCounts(&group.CheckStatsCounts, counts)
+		group.Daily = append(group.Daily, model.CheckStatsDay{Date: date, CheckStatsCounts: counts})
+	}
+	s.statsMu.Unlock()
+
+	response := model.CheckStatsResponse{
+		Days:      days,
+		Providers: finishCheckStatsGroups(groups[checkStatsKindProvider]),
+		Sources:   finishCheckStatsGroups(groups[checkStatsKindSource]),
+	}
+	if limit > 0 && len(response.Sources) > limit {
+		response.Sources = response.Sources[:limit]
+	}
+	return response
+}
+
+// History 获取链接的检测状态变化历史
+func (s *CheckService) History(item model.CheckItem) model.CheckHistoryResponse {
+	checker, _ := s.getChecker(item.DiskType)
+	normalized, cacheKey, _ := s.normalizeShareLink(checker, item.DiskType, item.URL, item.Password)
+
+	response := model.CheckHistoryResponse{
+		DiskType:      item.DiskType,
+		URL:           item.URL,
+		NormalizedURL: normalized,
+		History:       []model.CheckHistoryEntry{},
+	}
+	if normalized != "" {
+		if history := s.loadCheckHistory(cacheKey); history != nil {
+			response.History = history
+		}
+	}
+	return response
+}
+
+func addCheckStatsCounts(total *model.CheckStatsCounts, counts model.CheckStatsCounts) {
+	total.Total += counts.Total
+	total.OK += counts.OK
+	total.Bad += counts.Bad
+	total.Locked += counts.Locked
+	total.Unsupported += counts.Unsupported
+	total.Uncertain += counts.Uncertain
+	total.Errors += counts.Errors
+}
+
+// finishCheckStatsGroups 计算比例并排序：按检测次数倒序，每组的按天统计按日期正序
+func finishCheckStatsGroups(byName map[string]*model.CheckStatsGroup) []model.CheckStatsGroup {
+	groups := make([]model.CheckStatsGroup, 0, len(byName))
+	for _, group := range byName {
+		if total := float64(group.Total); total > 0 {
+			group.OKRatio = float64(group.OK) / total
+			group.BadRatio = float64(group.Bad) / total
+			group.LockedRatio = float64(group.Locked) / total
+			group.ErrorRate = float64(group.Errors) / total
+		}
+		sort.Slice(group.Daily, func(i, j int) bool {
+			return group.Daily[i].Date < group.Daily[j].Date
+		})
+		groups = append(groups, *group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// loadCheckStats 启动时加载保留期内的检测统计，并删除过期的统计
+func (s *CheckService) loadCheckStats() {
+	if s.cacheDB == nil {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -checkStatsRetentionDays).Format(checkStatsDateLayout)
+	_ = s.cacheDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkStatsBucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		var staleKeys [][]byte
+		_ = bucket.ForEach(func(key, value []byte) error {
+			parts := strings.SplitN(string(key), "|", 3)
+			var counts model.CheckStatsCounts
+			if len(parts) != 3 || parts[1] < cutoff || gob.NewDecoder(bytes.NewReader(value)).Decode(&counts) != nil {
+				keyCopy := make([]byte, len(key))
+				copy(keyCopy, key)
+				staleKeys = append(staleKeys, keyCopy)
+				return nil
+			}
+			s.stats[string(key)] = counts
+			return nil
+		})
+
+		for _, key := range staleKeys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// pruneCheckStats 删除超过保留期的检测统计和最近一次检测已超过保留期的检测历史，运行期间定期执行
+func (s *CheckService) pruneCheckStats() {
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -checkStatsRetentionDays)
+	cutoffDate := cutoff.Format(checkStatsDateLayout)
+
+	s.statsMu.Lock()
+	for key := range s.stats {
+		if parts := strings.SplitN(key, "|", 3); len(parts) != 3 || parts[1] < cutoffDate {
+			delete(s.stats, key)
+		}
+	}
+	s.statsMu.Unlock()
+
+	if s.cacheDB == nil {
+		return
+	}
+
+	_ = s.cacheDB.Update(func(tx *bolt.Tx) error {
+		err := pruneBucket(tx, checkStatsBucketName, func(key, value []byte) bool {
+			parts := strings.SplitN(string(key), "|", 3)
+			return len(parts) != 3 || parts[1] < cutoffDate
+		})
+		if err != nil {
+			return err
+		}
+
+		return pruneBucket(tx, checkHistoryBucketName, func(key, value []byte) bool {
+			var history []model.CheckHistoryEntry
+			if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&history); err != nil || len(history) == 0 {
+				return true
+			}
+			return history[len(history)-1].CheckedAt < cutoff.UnixMilli()
+		})
+	})
+}
+
+func (s *CheckService) saveCheckStats(updated map[string]model.CheckStatsCounts) {
+	if s.cacheDB == nil {
+		return
+	}
+
+	_ = s.cacheDB.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkStatsBucketName))
+		if bucket == nil {
+			return nil
+		}
+		for key, counts := range updated {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(counts); err != nil {
+				continue
+			}
+			if err := bucket.Put([]byte(key), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// appendCheckHistory 状态与上一条记录不同时追加一条历史，超出上限时丢弃最早的记录
+func (s *CheckService) appendCheckHistory(cacheKey string, entry model.CheckHistoryEntry) {
+	if s.cacheDB == nil {
+		return
+	}
+
+	_ = s.cacheDB.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkHistoryBucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		var history []model.CheckHistoryEntry
+		if raw := bucket.Get([]byte(cacheKey)); len(raw) > 0 {
+			_ = gob.NewDecoder(bytes.NewReader(raw)).Decode(&history)
+		}
+		if len(history) > 0 && history[len(history)-1].State == entry.State {
+			return nil
+		}
+
+		history = append(history, entry)
+		if len(history) > maxCheckHistory {
+			history = history[len(history)-maxCheckHistory:]
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(history); err != nil {
+			return nil
+		}
+		return bucket.Put([]byte(cacheKey), buf.Bytes())
+	})
+}
+
+func (s *CheckService) loadCheckHistory(cacheKey string) []model.CheckHistoryEntry {
+	if s.cacheDB == nil {
+		return nil
+	}
+
+	var history []model.CheckHistoryEntry
+	_ = s.cacheDB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkHistoryBucketName))
+		if bucket == nil {
+			return nil
+		}
+		if raw := bucket.Get([]byte(cacheKey)); len(raw) > 0 {
+			_ = gob.NewDecoder(bytes.NewReader(raw)).Decode(&history)
+		}
+		return nil
+	})
+	return history
+}
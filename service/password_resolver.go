@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"pansou/model"
+	"pansou/util/linkid"
+)
+
+const (
+	sharePasswordBucketName = "share_passwords"
+	// sharePasswordTTL 确认过的提取码的有效期，过期后重新按候选提取码检测
+	sharePasswordTTL = 7 * 24 * time.Hour
+	// maxPasswordCandidates 每个分享最多尝试的候选提取码数
+	maxPasswordCandidates = 5
+	// maxPendingPasswordResolutions 同时在后台确认提取码的分享数上限
+	maxPendingPasswordResolutions = 100
+	// backgroundPasswordConcurrency 每种网盘同时在后台确认提取码的请求数
+	backgroundPasswordConcurrency = 1
+	// backgroundPasswordRate 每种网盘每秒在后台确认提取码的请求数
+	backgroundPasswordRate = 0.5
+)
+
+// newBackgroundPasswordLimiter 创建后台确认提取码使用的限制器
+// 后台确认由普通搜索触发，先占用这个较小的名额再占用共用的检测名额，避免挤占用户发起的检测
+func newBackgroundPasswordLimiter() *checkLimiter {
+	return newCheckLimiter(
+		map[string]int{"*": backgroundPasswordConcurrency},
+		map[string]float64{"*": backgroundPasswordRate},
+	)
+}
+
+// passwordVerifier 使用指定提取码访问分享，提取码正确时返回ok，错误时返回locked
+type passwordVerifier func(ctx context.Context, item model.CheckItem, normalized string) (string, string, error)
+
+// resolvedSharePassword 确认可以打开分享的提取码
+type resolvedSharePassword struct {
+	Password   string
+	ResolvedAt int64
+}
+
+// builtinPasswordVerifiers 检测结果能区分提取码是否正确的网盘
+func (s *CheckService) builtinPasswordVerifiers() map[string]passwordVerifier {
+	return map[string]passwordVerifier{
+		"baidu":  s.checkBaidu,
+		"quark":  s.checkQuark,
+		"115":    s.check115,
+		"tianyi": s.verifyTianyiPassword,
+	}
+}
+
+// ResolvesPasswords 判断是否能通过检测确认指定网盘的提取码
+func (s *CheckService) ResolvesPasswords(diskType string) bool {
+	_, ok := s.passwordVerifiers[diskType]
+	return ok
+}
+
+// PreferredPassword 获取分享确认过的提取码
+// 尚未确认且存在多个候选提取码时，在后台逐个检测候选提取码并记录能打开分享的那个，供之后的合并使用
+func (s *CheckService) PreferredPassword(diskType, rawURL string, candidates []string) (string, bool) {
+	if !s.ResolvesPasswords(diskType) {
+		return "", false
+	}
+
+	key := linkid.DedupKey(rawURL)
+	if password, ok := s.getResolvedPassword(key); ok {
+		return password, true
+	}
+	if len(candidates) < 2 {
+		return "", false
+	}
+
+	s.passwordMu.Lock()
+	if s.passwordPending[key] || len(s.passwordPending) >= maxPendingPasswordResolutions {
+		s.passwordMu.Unlock()
+		return "", false
+	}
+	s.passwordPending[key] = true
+	s.passwordMu.Unlock()
+
+	candidates = append([]string(nil), candidates...)
+	go func() {
+		defer func() {
+			s.passwordMu.Lock()
+			delete(s.passwordPending, key)
+			s.passwordMu.Unlock()
+		}()
+		s.resolvePassword(diskType, rawURL, candidates, s.passwordLimiter)
+	}()
+	return "", false
+}
+
+// ResolvePassword 逐个检测候选提取码，返回并记录第一个能打开分享的提取码
+// 分享已失效或所有候选提取码都无法确认时返回false
+func (s *CheckService) ResolvePassword(diskType, rawURL string, candidates []string) (string, bool) {
+	return s.resolvePassword(diskType, rawURL, candidates, nil)
+}
+
+// resolvePassword 逐个检测候选提取码，background不为nil时每次检测先占用其名额
+func (s *CheckService) resolvePassword(diskType, rawURL string, candidates []string, background *checkLimiter) (string, bool) {
+	verifier, ok := s.passwordVerifiers[diskType]
+	if !ok {
+		return "", false
+	}
+	if len(candidates) > maxPasswordCandidates {
+		candidates = candidates[:maxPasswordCandidates]
+	}
+
+	ctx := context.Background()
+	for _, password := range candidates {
+		if password == "" {
+			continue
+		}
+
+		normalized := linkid.WithPassword(rawURL, password)
+		item := model.CheckItem{DiskType: diskType, URL: normalized, Password: password}
+
+		var releaseBackground func()
+		if background != nil {
+			var err error
+			if releaseBackground, err = background.acquire(ctx, diskType); err != nil {
+				return "", false
+			}
+		}
+		release, err := s.limiter.acquire(ctx, diskType)
+		if err != nil {
+			if releaseBackground != nil {
+				releaseBackground()
+			}
+			return "", false
+		}
+		state, _, err := verifier(ctx, item, normalized)
+		release()
+		if releaseBackground != nil {
+			releaseBackground()
+		}
+		if err != nil {
+			continue
+		}
+
+		switch state {
+		case checkStateOK:
+			s.saveResolvedPassword(linkid.DedupKey(rawURL), password)
+			return password, true
+		case checkStateBad:
+			// 分享已失效，换提取码也无法打开
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// verifyTianyiPassword 天翼云盘的分享信息接口不校验访问码，需要单独调用访问码校验接口
+func (s *CheckService) verifyTianyiPassword(ctx context.Context, item model.CheckItem, normalized string) (string, string, error) {
+	shareCode, password, referer := extractTianyiShareInfo(normalized, item.Password)
+	if shareCode == "" {
+		return checkStateUncertain, "无法解析分享地址", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	headers := tianyiJSONHeaders(referer)
+	info, ok, err := s.fetchTianyiShareInfo(ctx, shareCode, headers)
+	if err != nil {
+		return checkStateUncertain, "请求失败", err
+	}
+	if !ok {
+		return checkStateUncertain, "无法确认链接状态", nil
+	}
+	if info.NeedAccessCode != 1 {
+		return checkStateOK, "链接有效", nil
+	}
+
+	_, state, summary, err := s.verifyTianyiAccessCode(ctx, shareCode, password, headers)
+	if state != "" {
+		return state, summary, err
+	}
+	return checkStateOK, "访问码正确", nil
+}
+
+func (s *CheckService) getResolvedPassword(key string) (string, bool) {
+	s.passwordMu.Lock()
+	defer s.passwordMu.Unlock()
+
+	entry, ok := s.passwords[key]
+	if !ok {
+		return "", false
+	}
+	if time.Since(time.UnixMilli(entry.ResolvedAt)) > sharePasswordTTL {
+		delete(s.passwords, key)
+		return "", false
+	}
+	return entry.Password, true
+}
+
+func (s *CheckService) saveResolvedPassword(key, password string) {
+	entry := resolvedSharePassword{Password: password, ResolvedAt: time.Now().UnixMilli()}
+
+	s.passwordMu.Lock()
+	s.passwords[key] = entry
+	s.passwordMu.Unlock()
+
+	if s.cacheDB == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return
+	}
+	if err := s.cacheDB.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sharePasswordBucketName))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Put([]byte(key), buf.Bytes())
+	}); err != nil {
+		fmt.Printf("保存分享提取码失败: %v\n", err)
+	}
+}
+
+// sweepResolvedPasswords 删除内存中过期的提取码记录，持久化的记录由pruneExpiredCacheStore清理
+func (s *CheckService) sweepResolvedPasswords() {
+	s.passwordMu.Lock()
+	defer s.passwordMu.Unlock()
+
+	for key, entry := range s.passwords {
+		if time.Since(time.UnixMilli(entry.ResolvedAt)) > sharePasswordTTL {
+			delete(s.passwords, key)
+		}
+	}
+}
+
+// loadResolvedPasswords 启动时加载有效期内确认过的提取码，并删除过期的记录
+func (s *CheckService) loadResolvedPasswords() {
+	if s.cacheDB == nil {
+		return
+	}
+
+	_ = s.cacheDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sharePasswordBucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		var staleKeys [][]byte
+		_ = bucket.ForEach(func(key, value []byte) error {
+			var entry resolvedSharePassword
+			if gob.NewDecoder(bytes.NewReader(value)).Decode(&entry) != nil || time.Since(time.UnixMilli(entry.ResolvedAt)) > sharePasswordTTL {
+				keyCopy := make([]byte, len(key))
+				copy(keyCopy, key)
+				staleKeys = append(staleKeys, keyCopy)
+				return nil
+			}
+			s.passwords[string(key)] = entry
+			return nil
+		})
+
+		for _, key := range staleKeys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util/peer"
+)
+
+// peerGroup 实例组，未配置CACHE_PEERS时为nil
+var peerGroup *peer.Group
+
+// InitPeerGroup 根据配置创建实例组，未配置实例组时返回nil
+// 其他实例请求的搜索由不再转发的搜索服务执行，避免实例之间循环请求
+func InitPeerGroup(searchService *SearchService) *peer.Group {
+	if len(config.AppConfig.CachePeers) == 0 {
+		return nil
+	}
+	if config.AppConfig.CachePeerSelf == "" {
+		fmt.Println("[实例组] 未设置CACHE_PEER_SELF，实例组未启用")
+		return nil
+	}
+	if config.AppConfig.AuthEnabled && config.AppConfig.CachePeerToken == "" {
+		// 实例之间的请求不携带JWT，会被认证中间件拒绝
+		fmt.Println("[实例组] 已启用认证但未设置CACHE_PEER_TOKEN，实例之间的请求会被拒绝，实例组未启用")
+		return nil
+	}
+
+	server := &SearchService{pluginManager: searchService.pluginManager, peerServing: true}
+	peerGroup = peer.NewGroup(config.AppConfig.CachePeerSelf, config.AppConfig.CachePeers,
+		config.AppConfig.CachePeerToken, config.AppConfig.CachePeerTimeout, server.servePeer)
+
+	fmt.Printf("实例组: 本实例 %s，共 %d 个实例\n", peerGroup.Self(), len(peerGroup.Peers()))
+	return peerGroup
+}
+
+// GetPeerGroup 获取实例组，未启用时返回nil
+func GetPeerGroup() *peer.Group {
+	return peerGroup
+}
+
+// servePeer 为其他实例执行搜索，先查本实例缓存，结果写入本实例缓存
+func (s *SearchService) servePeer(req peer.Request) ([]model.SearchResult, error) {
+	switch req.Source {
+	case peer.SourceTG:
+		results, _, err := s.searchTG(req.Keyword, req.Channels, req.Refresh)
+		return results, err
+	case peer.SourcePlugin:
+		return s.searchPlugins(req.Keyword, req.Plugins, req.Refresh, req.Concurrency, req.Ext)
+	}
+	return nil, fmt.Errorf("未知的搜索来源: %s", req.Source)
+}
+
+// cachePeerResults 将来自所属实例的结果短暂保存在本实例内存缓存中，同一搜索的重复请求不必每次都请求所属实例
+// 只写内存缓存，二级缓存由所属实例负责
+func cachePeerResults(key string, results []model.SearchResult) {
+	ttl := config.AppConfig.CachePeerLocalTTL
+	if ttl <= 0 || !cacheInitialized || !config.AppConfig.CacheEnabled || enhancedTwoLevelCache == nil {
+		return
+	}
+
+	data, err := enhancedTwoLevelCache.GetSerializer().Serialize(results)
+	if err != nil {
+		return
+	}
+	enhancedTwoLevelCache.SetMemoryOnly(key, data, ttl)
+}
+
+// fill 获取缓存未命中时的搜索结果
+// 启用实例组时，缓存键属于其他实例则向其请求，只有所属实例执行实际搜索；同一缓存键同时只执行一次本地搜索
+// fromPeer表示结果来自所属实例，本实例只在内存缓存中短暂保存（见cachePeerResults）
+func (s *SearchService) fill(req peer.Request, local func() []model.SearchResult) (results []model.SearchResult, fromPeer bool) {
+	if peerGroup == nil {
+		return local(), false
+	}
+
+	search := func() ([]model.SearchResult, error) { return local(), nil }
+	if s.peerServing {
+		results, _ = peerGroup.Do(req.Key, search)
+		return results, false
+	}
+	results, fromPeer, _ = peerGroup.Fill(req, search)
+	if fromPeer {
+		go cachePeerResults(req.Key, results)
+	}
+	return results, fromPeer
+}
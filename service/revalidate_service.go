@@ -0,0 +1,455 @@
+package service
+
+import (
+	"container/list"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+)
+
+const (
+	// maxTrackedCacheKeys 最多记录访问次数的搜索缓存键数
+	maxTrackedCacheKeys = 10000
+	// maxTrackedLinkStates 最多记录上次检测状态的链接数，超出后清空重新记录
+	maxTrackedLinkStates = 50000
+	// maxRecentTransitions 保留的最近状态变化记录数
+	maxRecentTransitions = 100
+)
+
+// cacheAccessTracker 记录访问次数，用于挑选热门缓存条目复检和热门关键词预热
+// 最多记录capacity个键，按最近访问排序，记录已满时淘汰最久未访问的键，新的热门键可以持续累积访问次数
+type cacheAccessTracker struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // 最近访问的键在前
+	entries  map[string]*list.Element // 键 -> order中的元素
+}
+
+// accessEntry 单个键的访问次数
+type accessEntry struct {
+	key  string
+	hits int64
+}
+
+// newCacheAccessTracker 创建最多记录capacity个键的访问记录
+func newCacheAccessTracker(capacity int) *cacheAccessTracker {
+	return &cacheAccessTracker{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// searchCacheAccess 搜索缓存（TG和插件）的访问记录
+var searchCacheAccess = newCacheAccessTracker(maxTrackedCacheKeys)
+
+// record 记录一次访问
+func (t *cacheAccessTracker) record(key string) {
+	t.add(key, 1)
+}
+
+// add 增加键的访问次数，记录已满时淘汰最久未访问的键
+func (t *cacheAccessTracker) add(key string, hits int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if elem, ok := t.entries[key]; ok {
+		elem.Value.(*accessEntry).hits += hits
+		t.order.MoveToFront(elem)
+		return
+	}
+
+	if t.order.Len() >= t.capacity {
+		if oldest := t.order.Back(); oldest != nil {
+			t.order.Remove(oldest)
+			delete(t.entries, oldest.Value.(*accessEntry).key)
+		}
+	}
+	t.entries[key] = t.order.PushFront(&accessEntry{key: key, hits: hits})
+}
+
+// forget 删除缓存键的访问记录
+func (t *cacheAccessTracker) forget(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if elem, ok := t.entries[key]; ok {
+		t.order.Remove(elem)
+		delete(t.entries, key)
+	}
+}
+
+// count 返回缓存键的访问次数
+func (t *cacheAccessTracker) count(key string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if elem, ok := t.entries[key]; ok {
+		return elem.Value.(*accessEntry).hits
+	}
+	return 0
+}
+
+// snapshot 返回所有键的访问次数
+func (t *cacheAccessTracker) snapshot() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	hits := make(map[string]int64, len(t.entries))
+	for key, elem := range t.entries {
+		hits[key] = elem.Value.(*accessEntry).hits
+	}
+	return hits
+}
+
+// top 返回访问次数最多的n个缓存键
+func (t *cacheAccessTracker) top(n int) []string {
+	hits := t.snapshot()
+	keys := make([]string, 0, len(hits))
+	for key := range hits {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if hits[keys[i]] != hits[keys[j]] {
+			return hits[keys[i]] > hits[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// RevalidationService 后台链接复检服务
+// 定期复检访问最多的搜索缓存条目中的链接，记录状态变化，并把失效链接从缓存中移除
+type RevalidationService struct {
+	mu         sync.Mutex
+	status     model.RevalidationStatus
+	lastStates map[string]string // 链接 -> 上次检测状态
+	trigger    chan struct{}
+	startOnce  sync.Once
+
+	checker   *CheckService
+	interval  time.Duration
+	topKeys   int
+	maxChecks int
+}
+
+var (
+	globalRevalidationService     *RevalidationService
+	globalRevalidationServiceOnce sync.Once
+)
+
+// GetRevalidationService 获取全局复检服务
+func GetRevalidationService() *RevalidationService {
+	globalRevalidationServiceOnce.Do(func() {
+		globalRevalidationService = NewRevalidationService(GetCheckService())
+	})
+	return globalRevalidationService
+}
+
+// NewRevalidationService 创建复检服务
+func NewRevalidationService(checker *CheckService) *RevalidationService {
+	return &RevalidationService{
+		status: model.RevalidationStatus{
+			Enabled:          config.AppConfig.RevalidateEnabled,
+			IntervalMinutes:  int(config.AppConfig.RevalidateInterval / time.Minute),
+			TransitionCounts: make(map[string]int64),
+		},
+		lastStates: make(map[string]string),
+		trigger:    make(chan struct{}, 1),
+		checker:    checker,
+		interval:   config.AppConfig.RevalidateInterval,
+		topKeys:    config.AppConfig.RevalidateTopKeys,
+		maxChecks:  config.AppConfig.RevalidateMaxChecks,
+	}
+}
+
+// Start 启动定时复检，重复调用只会启动一次
+func (r *RevalidationService) Start() {
+	r.startOnce.Do(func() {
+		r.mu.Lock()
+		r.status.Enabled = true
+		r.status.NextRunAt = time.Now().Add(r.interval).UnixMilli()
+		r.mu.Unlock()
+
+		go r.loop()
+	})
+}
+
+func (r *RevalidationService) loop() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.trigger:
+		}
+
+		r.RunOnce()
+
+		r.mu.Lock()
+		r.status.NextRunAt = time.Now().Add(r.interval).UnixMilli()
+		r.mu.Unlock()
+	}
+}
+
+// Trigger 请求立即执行一轮复检，已在执行或已排队时返回false
+func (r *RevalidationService) Trigger() bool {
+	r.mu.Lock()
+	running, enabled := r.status.Running, r.status.Enabled
+	r.mu.Unlock()
+	if running {
+		return false
+	}
+
+	if !enabled {
+		// 未启用定时复检时在后台单独执行一轮
+		go r.RunOnce()
+		return true
+	}
+
+	select {
+	case r.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status 获取复检状态和计数
+func (r *RevalidationService) Status() model.RevalidationStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := r.status
+	status.TransitionCounts = make(map[string]int64, len(r.status.TransitionCounts))
+	for key, count := range r.status.TransitionCounts {
+		status.TransitionCounts[key] = count
+	}
+	status.RecentTransitions = append([]model.LinkStateTransition(nil), r.status.RecentTransitions...)
+	return status
+}
+
+// RunOnce 执行一轮复检
+func (r *RevalidationService) RunOnce() {
+	r.mu.Lock()
+	if r.status.Running {
+		r.mu.Unlock()
+		return
+	}
+	keys := searchCacheAccess.top(r.topKeys)
+	r.status.Running = true
+	r.status.LastStartedAt = time.Now().UnixMilli()
+	r.status.Progress = model.RevalidationProgress{
+		EntriesTotal: len(keys),
+		CheckBudget:  r.maxChecks,
+	}
+	r.mu.Unlock()
+
+	budget := r.maxChecks
+	if cacheInitialized && enhancedTwoLevelCache != nil {
+		for _, key := range keys {
+			budget = r.revalidateEntry(key, budget)
+
+			r.mu.Lock()
+			r.status.Progress.EntriesDone++
+			r.status.Progress.ChecksUsed = r.maxChecks - budget
+			r.mu.Unlock()
+		}
+	}
+
+	r.mu.Lock()
+	r.status.Running = false
+	r.status.Rounds++
+	r.status.LastFinishedAt = time.Now().UnixMilli()
+	r.mu.Unlock()
+}
+
+// entryModifiedSince 判断缓存条目在复检期间是否已被删除或被其他写入（搜索、后台刷新、异步插件）更新
+// 检测可能持续较长时间，已更新的条目不再用复检前读取的数据覆盖
+func entryModifiedSince(key string, lastModified time.Time) bool {
+	_, current, hit, err := enhancedTwoLevelCache.GetWithTimestamp(key)
+	return err != nil || !hit || !current.Equal(lastModified)
+}
+
+// revalidateEntry 复检一个缓存条目中的链接，返回剩余检测预算
+func (r *RevalidationService) revalidateEntry(key string, budget int) int {
+	data, lastModified, hit, err := enhancedTwoLevelCache.GetWithTimestamp(key)
+	if err != nil || !hit {
+		searchCacheAccess.forget(key)
+		return budget
+	}
+
+	var results []model.SearchResult
+	if err := enhancedTwoLevelCache.GetSerializer().Deserialize(data, &results); err != nil {
+		return budget
+	}
+
+	var counters model.RevalidationCounters
+	counters.EntriesScanned = 1
+
+	// 收集需要检测的链接：命中检测缓存的直接使用，其余在预算内重新检测
+	states := make(map[string]string)
+	var pending []model.CheckItem
+	for _, result := range results {
+		source := getResultSource(result)
+		for _, link := range result.Links {
+			item := model.CheckItem{DiskType: link.Type, URL: link.URL, Password: link.Password, Source: source}
+			linkKey := checkItemKey(item)
+			if _, seen := states[linkKey]; seen || !r.checker.Supports(link.Type) {
+				continue
+			}
+
+			if cached, ok := r.checker.Lookup(item); ok {
+				states[linkKey] = cached.State
+				counters.LinksCached++
+				continue
+			}
+
+			states[linkKey] = ""
+			if budget <= 0 {
+				counters.LinksSkipped++
+				continue
+			}
+			budget--
+			pending = append(pending, item)
+		}
+	}
+
+	// 按单次检测请求的上限分批检测
+	batchSize := config.AppConfig.CheckMaxBatchSize
+	if batchSize <= 0 {
+		batchSize = len(pending)
+	}
+	for start := 0; start < len(pending); start += batchSize {
+		end := start + batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+		response := r.checker.Check(batch)
+		for i, result := range response.Results {
+			states[checkItemKey(batch[i])] = result.State
+		}
+		counters.LinksChecked += int64(len(batch))
+	}
+
+	r.recordStates(states, &counters)
+
+	// 移除失效链接，链接全部失效的结果一并移除
+	changed := false
+	kept := make([]model.SearchResult, 0, len(results))
+	for _, result := range results {
+		if len(result.Links) == 0 {
+			kept = append(kept, result)
+			continue
+		}
+
+		links := make([]model.Link, 0, len(result.Links))
+		for _, link := range result.Links {
+			item := model.CheckItem{DiskType: link.Type, URL: link.URL, Password: link.Password}
+			if states[checkItemKey(item)] == checkStateBad {
+				counters.DeadLinksRemoved++
+				changed = true
+				continue
+			}
+			links = append(links, link)
+		}
+
+		if len(links) == 0 {
+			counters.ResultsRemoved++
+			continue
+		}
+		result.Links = links
+		kept = append(kept, result)
+	}
+
+	if changed && !entryModifiedSince(key, lastModified) {
+		// 保留原有的过期时间和最后修改时间，避免复检延长缓存寿命或使缓存显得刚刚更新
+		if expiry, ok := enhancedTwoLevelCache.GetExpiry(key); ok && time.Until(expiry) > 0 {
+			if data, err := enhancedTwoLevelCache.GetSerializer().Serialize(kept); err == nil {
+				if err := enhancedTwoLevelCache.SetBothLevelsWithTimestamp(key, data, time.Until(expiry), lastModified); err == nil {
+					counters.EntriesRewritten++
+				}
+			}
+		}
+		if counters.EntriesRewritten == 0 {
+			counters.DeadLinksRemoved = 0
+			counters.ResultsRemoved = 0
+		}
+	}
+
+	r.mu.Lock()
+	c := &r.status.Counters
+	c.EntriesScanned += counters.EntriesScanned
+	c.EntriesRewritten += counters.EntriesRewritten
+	c.LinksChecked += counters.LinksChecked
+	c.LinksCached += counters.LinksCached
+	c.LinksSkipped += counters.LinksSkipped
+	c.DeadLinksRemoved += counters.DeadLinksRemoved
+	c.ResultsRemoved += counters.ResultsRemoved
+	c.Transitions += counters.Transitions
+	r.mu.Unlock()
+
+	return budget
+}
+
+// recordStates 记录本次检测状态，与上次不同时记录状态变化
+func (r *RevalidationService) recordStates(states map[string]string, counters *model.RevalidationCounters) {
+	now := time.Now().UnixMilli()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.lastStates)+len(states) > maxTrackedLinkStates {
+		r.lastStates = make(map[string]string)
+	}
+
+	for linkKey, state := range states {
+		if state == "" {
+			continue
+		}
+
+		previous, ok := r.lastStates[linkKey]
+		r.lastStates[linkKey] = state
+		if !ok || previous == state {
+			continue
+		}
+
+		counters.Transitions++
+		r.status.TransitionCounts[previous+"->"+state]++
+
+		diskType, url := splitCheckItemKey(linkKey)
+		transition := model.LinkStateTransition{
+			DiskType: diskType,
+			URL:      url,
+			From:     previous,
+			To:       state,
+			At:       now,
+		}
+		r.status.RecentTransitions = append([]model.LinkStateTransition{transition}, r.status.RecentTransitions...)
+		if len(r.status.RecentTransitions) > maxRecentTransitions {
+			r.status.RecentTransitions = r.status.RecentTransitions[:maxRecentTransitions]
+		}
+	}
+}
+
+// checkItemKey 链接在复检记录中的键：网盘类型|链接|提取码
+func checkItemKey(item model.CheckItem) string {
+	return item.DiskType + "|" + item.URL + "|" + item.Password
+}
+
+// splitCheckItemKey 从复检记录键中取出网盘类型和链接
+func splitCheckItemKey(key string) (string, string) {
+	first := strings.Index(key, "|")
+	last := strings.LastIndex(key, "|")
+	if first < 0 || last == first {
+		return "", key
+	}
+	return key[:first], key[first+1 : last]
+}
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"pansou/config"
@@ -17,20 +17,12 @@ import (
 	"pansou/plugin"
 	"pansou/util"
 	"pansou/util/cache"
+	"pansou/util/linkid"
+	"pansou/util/netdisk"
+	"pansou/util/peer"
 	"pansou/util/pool"
 )
 
-// normalizeUrl 标准化URL，将URL编码的中文部分解码为中文，用于去重
-func normalizeUrl(rawUrl string) string {
-	// 解码URL中的编码字符
-	decoded, err := url.QueryUnescape(rawUrl)
-	if err != nil {
-		// 如果解码失败，返回原始URL
-		return rawUrl
-	}
-	return decoded
-}
-
 // 全局缓存写入管理器引用（避免循环依赖）
 var globalCacheWriteManager *cache.DelayedBatchWriteManager
 
@@ -49,14 +41,18 @@ func GetEnhancedTwoLevelCache() *cache.EnhancedTwoLevelCache {
 	return enhancedTwoLevelCache
 }
 
-// 优先关键词列表
-var priorityKeywords = []string{"合集", "系列", "全", "完", "最新", "附", "complete"}
+// highRelevanceScore 高相关度阈值，无时间信息的结果达到该得分时仍保留在Results中
+const highRelevanceScore = 60.0
+
+// relevanceScoreWeight 相关度得分（0-100）在综合排序中的权重，最高500分，与时间得分相当
+const relevanceScoreWeight = 5.0
 
-// extractKeywordFromCacheKey 从缓存键中提取关键词（简化版）
+// extractKeywordFromCacheKey 从缓存键索引中查询缓存键对应的关键词
 func extractKeywordFromCacheKey(cacheKey string) string {
-	// 这是一个简化的实现，实际中我们会通过传递来获得关键词
-	// 为了演示，这里返回简化的显示
-	return "搜索关键词"
+	if info, ok := cache.LookupCacheKey(cacheKey); ok {
+		return info.Keyword
+	}
+	return "未知"
 }
 
 // logAsyncCacheWithKeyword 异步缓存日志输出辅助函数（带关键词）
@@ -150,16 +146,94 @@ func generateResultKey(result model.SearchResult) string {
 	return fmt.Sprintf("title_%s_%s", result.Title, result.Channel)
 }
 
-// selectBetterResult 选择信息更完整的结果
+// selectBetterResult 选择信息更完整的结果，被舍弃结果中的提取码用于补全或纠正保留结果的链接
 func selectBetterResult(existing, new model.SearchResult) model.SearchResult {
 	// 计算信息完整度得分
 	existingScore := calculateCompletenessScore(existing)
 	newScore := calculateCompletenessScore(new)
 
 	if newScore > existingScore {
-		return new
+		return mergeLinkPasswords(new, existing)
+	}
+	return mergeLinkPasswords(existing, new)
+}
+
+// sharePasswordCandidates 同一分享在不同来源中出现过的提取码
+type sharePasswordCandidates struct {
+	provider  string
+	passwords []string
+}
+
+// collectPasswordCandidate 记录链接实际使用的提取码，链接中携带的提取码优先于单独给出的提取码
+func collectPasswordCandidate(candidates map[string]*sharePasswordCandidates, linkKey string, link model.Link) {
+	id, ok := linkid.ParseWithPassword(link.URL, link.Password)
+	if !ok || id.Password == "" {
+		return
+	}
+
+	entry, exists := candidates[linkKey]
+	if !exists {
+		entry = &sharePasswordCandidates{provider: id.Provider}
+		candidates[linkKey] = entry
+	}
+	for _, password := range entry.passwords {
+		if password == id.Password {
+			return
+		}
 	}
-	return existing
+	entry.passwords = append(entry.passwords, id.Password)
+}
+
+// preferredSharePassword 返回分享应使用的提取码
+// 优先使用检测确认过的提取码；存在多个候选且尚未确认时在后台检测，本次返回false；只有一个候选时直接使用
+func preferredSharePassword(rawURL string, entry *sharePasswordCandidates) (string, bool) {
+	if entry == nil || len(entry.passwords) == 0 {
+		return "", false
+	}
+	if password, ok := GetCheckService().PreferredPassword(entry.provider, rawURL, entry.passwords); ok {
+		return password, true
+	}
+	if len(entry.passwords) == 1 {
+		return entry.passwords[0], true
+	}
+	return "", false
+}
+
+// replaceLinkPassword 链接中携带的提取码与password不同时，改写为携带password的规范链接
+func replaceLinkPassword(rawURL, password string) string {
+	if id, ok := linkid.Parse(rawURL); ok && id.Password != "" && id.Password != password {
+		return linkid.WithPassword(rawURL, password)
+	}
+	return rawURL
+}
+
+// mergeLinkPasswords 用另一条结果中同一分享的提取码补全或纠正结果中链接的提取码
+func mergeLinkPasswords(result, other model.SearchResult) model.SearchResult {
+	if len(result.Links) == 0 || len(other.Links) == 0 {
+		return result
+	}
+
+	candidates := make(map[string]*sharePasswordCandidates)
+	for _, link := range result.Links {
+		collectPasswordCandidate(candidates, linkid.DedupKey(link.URL), link)
+	}
+	for _, link := range other.Links {
+		collectPasswordCandidate(candidates, linkid.DedupKey(link.URL), link)
+	}
+
+	// 复制链接切片，避免修改缓存中共享的数据
+	links := make([]model.Link, len(result.Links))
+	copy(links, result.Links)
+	for i, link := range links {
+		password, ok := preferredSharePassword(link.URL, candidates[linkid.DedupKey(link.URL)])
+		if !ok {
+			continue
+		}
+		links[i].URL = replaceLinkPassword(link.URL, password)
+		links[i].Password = password
+	}
+	result.Links = links
+	return result
 }
 
 // calculateCompletenessScore 计算结果信息的完整度得分
@@ -200,6 +274,8 @@ func calculateCompletenessScore(result model.SearchResult) int {
 // SearchService 搜索服务
 type SearchService struct {
 	pluginManager *plugin.PluginManager
+	background    bool // 后台任务（缓存预热）使用的搜索服务，搜索不计入访问统计
+	peerServing   bool // 为实例组中其他实例执行搜索的搜索服务，缓存未命中时不再转发
 }
 
 // NewSearchService 创建搜索服务实例并确保缓存可用
@@ -222,6 +298,10 @@ func NewSearchService(pluginManager *plugin.PluginManager) *SearchService {
 		globalCacheWriteManager.SetMainCacheUpdater(func(key string, data []byte, ttl time.Duration) error {
 			return enhancedTwoLevelCache.SetBothLevels(key, data, ttl)
 		})
+		// 在开始处理搜索之前恢复上次异常退出时尚未写入磁盘的缓存操作，只有第一次调用会执行
+		if err := globalCacheWriteManager.ReplayWAL(enhancedTwoLevelCache); err != nil {
+			fmt.Printf("%v\n", err)
+		}
 	}
 
 	return &SearchService{
@@ -287,6 +367,9 @@ func injectMainCacheToAsyncPlugins(pluginManager *plugin.PluginManager, mainCach
 			}
 		}
 
+		// 按缓存有效期策略调整有效期，没有匹配的策略时使用插件传入的有效期
+		ttl = searchCacheTTL(key, len(finalResults), ttl)
+
 		// 序列化合并后的结果
 		data, err := mainCache.GetSerializer().Serialize(finalResults)
 		if err != nil {
@@ -356,6 +439,13 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 		ext = make(map[string]interface{})
 	}
 
+	// 记录用户搜索，供缓存预热挑选热门关键词，进行中的用户搜索占用预热的并发预算
+	if !s.background {
+		searchKeywordAccess.record(strings.TrimSpace(keyword))
+		atomic.AddInt64(&liveSearches, 1)
+		defer atomic.AddInt64(&liveSearches, -1)
+	}
+
 	// 参数预处理
 	// 源类型标准化
 	if sourceType == "" {
@@ -432,6 +522,7 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 
 	// 并行获取TG搜索和插件搜索结果
 	var tgResults []model.SearchResult
+	var tgCachedAt time.Time
 	var pluginResults []model.SearchResult
 
 	var wg sync.WaitGroup
@@ -442,7 +533,7 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			tgResults, tgErr = s.searchTG(keyword, channels, forceRefresh)
+			tgResults, tgCachedAt, tgErr = s.searchTG(keyword, channels, forceRefresh)
 		}()
 	}
 	// 如果需要搜索插件（且插件功能已启用）
@@ -470,23 +561,29 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 	// 合并结果
 	allResults := mergeSearchResults(tgResults, pluginResults)
 
+	// 基于本次全部结果构建相关度评分器，并计算每个结果的相关度
+	scorer := newRelevanceScorer(keyword, allResults)
+	for i := range allResults {
+		allResults[i].Score = scorer.Score(relevanceDocOf(allResults[i]))
+	}
+
 	// 按照优化后的规则排序结果
-	sortResultsByTimeAndKeywords(allResults)
+	sortResultsByTimeAndRelevance(allResults)
 
-	// 过滤结果，只保留有时间的结果或包含优先关键词的结果或高等级插件结果到Results中
+	// 过滤结果，只保留有时间的结果或高相关度的结果或高等级插件结果到Results中
 	filteredForResults := make([]model.SearchResult, 0, len(allResults))
 	for _, result := range allResults {
 		source := getResultSource(result)
 		pluginLevel := getPluginLevelBySource(source)
 
-		// 有时间的结果或包含优先关键词的结果或高等级插件(1-2级)结果保留在Results中
-		if !result.Datetime.IsZero() || getKeywordPriority(result.Title) > 0 || pluginLevel <= 2 {
+		// 有时间的结果或高相关度的结果或高等级插件(1-2级)结果保留在Results中
+		if !result.Datetime.IsZero() || result.Score >= highRelevanceScore || pluginLevel <= 2 {
 			filteredForResults = append(filteredForResults, result)
 		}
 	}
 
 	// 合并链接按网盘类型分组（使用所有过滤后的结果）
-	mergedLinks := mergeResultsByType(allResults, keyword, cloudTypes)
+	mergedLinks := mergeResultsByType(allResults, scorer, cloudTypes)
 
 	// 构建响应
 	var total int
@@ -508,7 +605,14 @@ func (s *SearchService) Search(keyword string, channels []string, concurrency in
 	}
 
 	// 根据resultType过滤返回结果
-	return filterResponseByType(response, resultType), nil
+	response = filterResponseByType(response, resultType)
+
+	// TG结果来自缓存时返回缓存时间，供客户端显示数据更新于多久之前
+	if !tgCachedAt.IsZero() {
+		response.CachedAt = tgCachedAt.UnixMilli()
+		response.CacheAge = int64(time.Since(tgCachedAt).Seconds())
+	}
+	return response, nil
 }
 
 // filterResponseByType 根据结果类型过滤响应
@@ -540,8 +644,9 @@ func filterResponseByType(response model.SearchResponse, resultType string) mode
 	}
 }
 
-// 根据时间和关键词排序结果
-func sortResultsByTimeAndKeywords(results []model.SearchResult) {
+// sortResultsByTimeAndRelevance 根据时间、相关度和插件等级排序结果
+// 调用前需已计算好每个结果的Score
+func sortResultsByTimeAndRelevance(results []model.SearchResult) {
 	// 1. 计算每个结果的综合得分
 	scores := make([]ResultScore, len(results))
 
@@ -549,21 +654,21 @@ func sortResultsByTimeAndKeywords(results []model.SearchResult) {
 		source := getResultSource(result)
 
 		scores[i] = ResultScore{
-			Result:       result,
-			TimeScore:    calculateTimeScore(result.Datetime),
-			KeywordScore: getKeywordPriority(result.Title),
-			PluginScore:  getPluginLevelScore(source),
-			TotalScore:   0, // 稍后计算
+			Result:         result,
+			TimeScore:      calculateTimeScore(result.Datetime),
+			RelevanceScore: result.Score * relevanceScoreWeight,
+			PluginScore:    getPluginLevelScore(source),
+			TotalScore:     0, // 稍后计算
 		}
 
 		// 计算综合得分
 		scores[i].TotalScore = scores[i].TimeScore +
-			float64(scores[i].KeywordScore) +
+			scores[i].RelevanceScore +
 			float64(scores[i].PluginScore)
 	}
 
-	// 2. 按综合得分排序
-	sort.Slice(scores, func(i, j int) bool {
+	// 2. 按综合得分排序（得分相同时保持原有顺序）
+	sort.SliceStable(scores, func(i, j int) bool {
 		return scores[i].TotalScore > scores[j].TotalScore
 	})
 
@@ -573,16 +678,28 @@ func sortResultsByTimeAndKeywords(results []model.SearchResult) {
 	}
 }
 
-// 获取标题中包含优先关键词的优先级
-func getKeywordPriority(title string) int {
-	title = strings.ToLower(title)
-	for i, keyword := range priorityKeywords {
-		if strings.Contains(title, keyword) {
-			// 返回优先级得分（数组索引越小，优先级越高，最高400分）
-			return (len(priorityKeywords) - i) * 70
+// newRelevanceScorer 基于本次搜索的全部结果创建相关度评分器
+func newRelevanceScorer(keyword string, results []model.SearchResult) *util.RelevanceScorer {
+	docs := make([]util.RelevanceDoc, len(results))
+	for i, result := range results {
+		docs[i] = relevanceDocOf(result)
+	}
+	return util.NewRelevanceScorer(keyword, docs)
+}
+
+// relevanceDocOf 将搜索结果转换为相关度计算文档，链接的作品标题合并为一个字段
+func relevanceDocOf(result model.SearchResult) util.RelevanceDoc {
+	var workTitles []string
+	for _, link := range result.Links {
+		if link.WorkTitle != "" && link.WorkTitle != result.Title {
+			workTitles = append(workTitles, link.WorkTitle)
 		}
 	}
-	return 0
+	return util.RelevanceDoc{
+		Title:     result.Title,
+		WorkTitle: strings.Join(workTitles, " "),
+		Content:   result.Content,
+	}
 }
 
 // 搜索单个频道
@@ -795,8 +912,8 @@ func extractLinkTitlePairsWithoutNewlines(content string) map[string]string {
 	var links []string
 
 	for _, linkInfo := range allLinks {
-		// 标准化URL（将URL编码转换为中文）
-		normalized := normalizeUrl(linkInfo.url)
+		// 规范化链接标识，仅域名、参数顺序不同的链接视为同一个
+		normalized := linkid.DedupKey(linkInfo.url)
 
 		// 如果这个标准化URL还没有见过，则保留
 		if _, exists := uniqueLinks[normalized]; !exists {
@@ -987,15 +1104,14 @@ func isEmpty(line string) bool {
 }
 
 // 将搜索结果按网盘类型分组
-func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes []string) model.MergedLinks {
+func mergeResultsByType(results []model.SearchResult, scorer *util.RelevanceScorer, cloudTypes []string) model.MergedLinks {
 	// 创建合并结果的映射
 	mergedLinks := make(model.MergedLinks, 12) // 预分配容量，假设有12种不同的网盘类型
 
-	// 用于去重的映射，键为URL
+	// 用于去重的映射，键为规范化的链接标识（仅域名、参数顺序不同的链接视为同一个）
 	uniqueLinks := make(map[string]model.MergedLink)
-
-	// 将关键词转为小写，用于不区分大小写的匹配
-	lowerKeyword := strings.ToLower(keyword)
+	// 同一分享出现过的提取码，用于解决不同来源提取码不一致的问题
+	passwordCandidates := make(map[string]*sharePasswordCandidates)
 
 	// 遍历所有搜索结果
 	for _, result := range results {
@@ -1093,11 +1209,9 @@ func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes
 			}
 
 			// 关键词过滤：现在我们有了准确的链接-标题对应关系，只需检查每个链接的具体标题
-			if !skipKeywordFilter && keyword != "" {
-				// 只检查链接的具体标题，无论是TG来源还是插件来源
-				if !strings.Contains(strings.ToLower(title), lowerKeyword) {
-					continue
-				}
+			// 按词元匹配，查询中的多个词顺序不限，字母数字词元容忍少量拼写错误
+			if !skipKeywordFilter && !scorer.Match(title) {
+				continue
 			}
 
 			// 确定数据来源
@@ -1132,52 +1246,67 @@ func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes
 				Datetime: linkDatetime,
 				Source:   source,        // 添加数据来源字段
 				Images:   result.Images, // 添加TG消息中的图片链接
+				Score:    scorer.Score(util.RelevanceDoc{Title: title}),
 			}
 
-			// 检查是否已存在相同URL的链接
-			if existingLink, exists := uniqueLinks[link.URL]; exists {
+			// 检查是否已存在相同的链接
+			linkKey := linkid.DedupKey(link.URL)
+			collectPasswordCandidate(passwordCandidates, linkKey, link)
+			if existingLink, exists := uniqueLinks[linkKey]; exists {
 				// 如果已存在，只有当当前链接的时间更新时才替换
 				if mergedLink.Datetime.After(existingLink.Datetime) {
-					uniqueLinks[link.URL] = mergedLink
+					// 新链接未携带提取码时沿用已有的提取码
+					if mergedLink.Password == "" {
+						mergedLink.Password = existingLink.Password
+					}
+					uniqueLinks[linkKey] = mergedLink
 				}
 			} else {
 				// 如果不存在，直接添加
-				uniqueLinks[link.URL] = mergedLink
+				uniqueLinks[linkKey] = mergedLink
 			}
 		}
 	}
 
+	// 提取码不一致时使用检测确认过能打开分享的提取码
+	for linkKey, entry := range passwordCandidates {
+		mergedLink, exists := uniqueLinks[linkKey]
+		if !exists {
+			continue
+		}
+		if password, ok := preferredSharePassword(mergedLink.URL, entry); ok {
+			mergedLink.URL = replaceLinkPassword(mergedLink.URL, password)
+			mergedLink.Password = password
+			uniqueLinks[linkKey] = mergedLink
+		}
+	}
+
 	// 为保持排序顺序，按原始results顺序处理链接，而不是随机遍历map
 	// 创建一个有序的链接列表，按原始results中的顺序
 	orderedLinks := make([]model.MergedLink, 0, len(uniqueLinks))
-	linkTypeMap := make(map[string]string) // URL -> Type的映射
+	orderedTypes := make([]string, 0, len(uniqueLinks)) // 与orderedLinks一一对应的链接类型
+	addedLinks := make(map[string]bool, len(uniqueLinks))
 
 	// 按原始results的顺序收集唯一链接
 	for _, result := range results {
 		for _, link := range result.Links {
-			if mergedLink, exists := uniqueLinks[link.URL]; exists {
-				// 检查是否已经添加过这个链接
-				found := false
-				for _, existing := range orderedLinks {
-					if existing.URL == link.URL {
-						found = true
-						break
-					}
-				}
-				if !found {
-					orderedLinks = append(orderedLinks, mergedLink)
-					linkTypeMap[link.URL] = link.Type
-				}
+			linkKey := linkid.DedupKey(link.URL)
+			if mergedLink, exists := uniqueLinks[linkKey]; exists && !addedLinks[linkKey] {
+				addedLinks[linkKey] = true
+				orderedLinks = append(orderedLinks, mergedLink)
+				orderedTypes = append(orderedTypes, link.Type)
 			}
 		}
 	}
 
 	// 将有序链接按类型分组
-	for _, mergedLink := range orderedLinks {
-		// 从预建的映射中获取链接类型
-		linkType := linkTypeMap[mergedLink.URL]
-		if linkType == "" {
-			linkType = "unknown"
+	for i, mergedLink := range orderedLinks {
+		// 链接类型以网盘注册表为准，未注册的类型按链接地址重新识别
+		linkType := strings.ToLower(orderedTypes[i])
+		if provider, ok := netdisk.Get(linkType); ok {
+			linkType = provider.Name
+		} else {
+			linkType = util.GetLinkType(mergedLink.URL)
 		}
 
 		// 添加到对应类型的列表中
@@ -1208,32 +1337,74 @@ func mergeResultsByType(results []model.SearchResult, keyword string, cloudTypes
 	return mergedLinks
 }
 
-// searchTG 搜索TG频道
-func (s *SearchService) searchTG(keyword string, channels []string, forceRefresh bool) ([]model.SearchResult, error) {
+// tgRefreshing 正在后台刷新的TG缓存键，同一缓存键同时只有一个刷新任务
+var tgRefreshing sync.Map
+
+// searchTG 搜索TG频道，结果来自缓存时同时返回缓存写入时间
+// 缓存在软过期时间内直接返回；超过软过期时间但未超过缓存有效期时返回缓存并在后台刷新；超过缓存有效期时重新搜索
+func (s *SearchService) searchTG(keyword string, channels []string, forceRefresh bool) ([]model.SearchResult, time.Time, error) {
 	// 生成缓存键
 	cacheKey := cache.GenerateTGCacheKey(keyword, channels)
+	if !s.background {
+		searchCacheAccess.record(cacheKey) // 记录访问次数，供后台复检挑选热门条目
+	}
 
 	// 如果未启用强制刷新，尝试从缓存获取结果
-	if !forceRefresh && cacheInitialized && config.AppConfig.CacheEnabled {
-		var data []byte
-		var hit bool
-		var err error
-
-		// 使用增强版缓存
-		if enhancedTwoLevelCache != nil {
-			data, hit, err = enhancedTwoLevelCache.Get(cacheKey)
-
-			if err == nil && hit {
-				var results []model.SearchResult
-				if err := enhancedTwoLevelCache.GetSerializer().Deserialize(data, &results); err == nil {
-					// 直接返回缓存数据，不检查新鲜度
-					return results, nil
+	if !forceRefresh && cacheInitialized && config.AppConfig.CacheEnabled && enhancedTwoLevelCache != nil {
+		data, cachedAt, hit, err := enhancedTwoLevelCache.GetWithTimestamp(cacheKey)
+		if err == nil && hit {
+			// 按写入时间判断新鲜度
+			// 有效期由缓存有效期策略决定，软过期时间按CACHE_SOFT_TTL与CACHE_TTL的比例换算
+			age := time.Since(cachedAt)
+
+			var results []model.SearchResult
+			if enhancedTwoLevelCache.GetSerializer().Deserialize(data, &results) == nil {
+				hardTTL := searchCacheTTL(cacheKey, len(results), time.Duration(config.AppConfig.CacheTTLMinutes)*time.Minute)
+				softTTL := hardTTL * time.Duration(config.AppConfig.CacheSoftTTLMinutes) / time.Duration(config.AppConfig.CacheTTLMinutes)
+				if age < hardTTL {
+					if age >= softTTL {
+						s.refreshTGInBackground(cacheKey, keyword, channels)
+					}
+					return results, cachedAt, nil
 				}
 			}
 		}
 	}
 
-	// 缓存未命中或强制刷新，执行实际搜索
+	// 缓存未命中、已超过有效期或强制刷新，执行实际搜索，缓存键属于实例组中其他实例时向其请求
+	results, fromPeer := s.fill(peer.Request{Key: cacheKey, Source: peer.SourceTG, Keyword: keyword, Channels: channels, Refresh: forceRefresh},
+		func() []model.SearchResult { return s.fetchTG(keyword, channels) })
+
+	// 异步缓存结果，来自所属实例的结果由所属实例缓存
+	if cacheInitialized && config.AppConfig.CacheEnabled && !fromPeer {
+		go storeTGResults(cacheKey, results)
+	}
+
+	return results, time.Time{}, nil
+}
+
+// refreshTGInBackground 在后台重新搜索TG频道并更新缓存，同一缓存键正在刷新时不重复发起
+// 与普通搜索一样经过实例组，缓存键属于其他实例时由所属实例刷新
+func (s *SearchService) refreshTGInBackground(cacheKey, keyword string, channels []string) {
+	if _, refreshing := tgRefreshing.LoadOrStore(cacheKey, true); refreshing {
+		return
+	}
+
+	go func() {
+		defer tgRefreshing.Delete(cacheKey)
+
+		results, fromPeer := s.fill(peer.Request{Key: cacheKey, Source: peer.SourceTG, Keyword: keyword, Channels: channels, Refresh: true},
+			func() []model.SearchResult { return s.fetchTG(keyword, channels) })
+		if len(results) == 0 || fromPeer {
+			// 频道全部请求失败时也没有结果，保留原有缓存，下次请求时再刷新；来自所属实例的结果由所属实例缓存
+			return
+		}
+		storeTGResults(cacheKey, results)
+	}()
+}
+
+// fetchTG 并行搜索多个TG频道并合并结果
+func (s *SearchService) fetchTG(keyword string, channels []string) []model.SearchResult {
 	var results []model.SearchResult
 
 	// 使用工作池并行搜索多个频道
@@ -1261,23 +1432,27 @@ func (s *SearchService) searchTG(keyword string, channels []string, forceRefresh
 		}
 	}
 
-	// 异步缓存结果
-	if cacheInitialized && config.AppConfig.CacheEnabled {
-		go func(res []model.SearchResult) {
-			ttl := time.Duration(config.AppConfig.CacheTTLMinutes) * time.Minute
+	return results
+}
 
-			// 使用增强版缓存
-			if enhancedTwoLevelCache != nil {
-				data, err := enhancedTwoLevelCache.GetSerializer().Serialize(res)
-				if err != nil {
-					return
-				}
-				enhancedTwoLevelCache.Set(cacheKey, data, ttl)
-			}
-		}(results)
+// storeTGResults 将TG搜索结果写入缓存
+func storeTGResults(cacheKey string, results []model.SearchResult) {
+	if enhancedTwoLevelCache == nil {
+		return
 	}
 
-	return results, nil
+	ttl := searchCacheTTL(cacheKey, len(results), time.Duration(config.AppConfig.CacheTTLMinutes)*time.Minute)
+	data, err := enhancedTwoLevelCache.GetSerializer().Serialize(results)
+	if err != nil {
+		return
+	}
+	enhancedTwoLevelCache.Set(cacheKey, data, ttl)
+}
+
+// searchCacheTTL 按缓存有效期策略计算搜索缓存条目的有效期，没有匹配的策略时返回fallback
+func searchCacheTTL(cacheKey string, resultCount int, fallback time.Duration) time.Duration {
+	info, _ := cache.LookupCacheKey(cacheKey)
+	return config.AppConfig.CacheTTLPolicy.Resolve(info.SourceType, info.Sources, resultCount, searchCacheAccess.count(cacheKey), fallback)
 }
 
 // searchPlugins 搜索插件
@@ -1294,6 +1469,9 @@ func (s *SearchService) searchPlugins(keyword string, plugins []string, forceRef
 
 	// 生成缓存键
 	cacheKey := cache.GeneratePluginCacheKey(keyword, plugins)
+	if !s.background {
+		searchCacheAccess.record(cacheKey) // 记录访问次数，供后台复检挑选热门条目
+	}
 
 	// 如果未启用强制刷新，尝试从缓存获取结果
 	if !forceRefresh && cacheInitialized && config.AppConfig.CacheEnabled {
@@ -1322,8 +1500,42 @@ func (s *SearchService) searchPlugins(keyword string, plugins []string, forceRef
 		}
 	}
 
-	// 缓存未命中或强制刷新，执行实际搜索
+	// 缓存未命中或强制刷新，执行实际搜索，缓存键属于实例组中其他实例时向其请求
+	allResults, fromPeer := s.fill(peer.Request{Key: cacheKey, Source: peer.SourcePlugin, Keyword: keyword, Plugins: plugins, Concurrency: concurrency, Ext: ext, Refresh: forceRefresh},
+		func() []model.SearchResult { return s.fetchPlugins(keyword, plugins, concurrency, ext, cacheKey) })
+	if fromPeer {
+		return allResults, nil
+	}
+
+	// 恢复主程序缓存更新：确保最终合并结果被正确缓存
+	if cacheInitialized && config.AppConfig.CacheEnabled {
+		go func(res []model.SearchResult, kw string, key string) {
+			ttl := searchCacheTTL(key, len(res), time.Duration(config.AppConfig.CacheTTLMinutes)*time.Minute)
+
+			// 使用增强版缓存，确保与异步插件使用相同的序列化器
+			if enhancedTwoLevelCache != nil {
+				data, err := enhancedTwoLevelCache.GetSerializer().Serialize(res)
+				if err != nil {
+					fmt.Printf("[主程序] 缓存序列化失败: %s | 错误: %v\n", key, err)
+					return
+				}
 
+				// 主程序最后更新，覆盖可能有问题的异步插件缓存
+				// 使用同步方式确保数据写入磁盘
+				enhancedTwoLevelCache.SetBothLevels(key, data, ttl)
+				if config.AppConfig != nil && config.AppConfig.AsyncLogEnabled {
+					fmt.Printf("[主程序] 缓存更新完成: %s | 结果数: %d",
+						key, len(res))
+				}
+			}
+		}(allResults, keyword, cacheKey)
+	}
+
+	return allResults, nil
+}
+
+// fetchPlugins 使用工作池并行调用插件搜索，合并有链接的结果
+func (s *SearchService) fetchPlugins(keyword string, plugins []string, concurrency int, ext map[string]interface{}, cacheKey string) []model.SearchResult {
 	// 获取所有可用插件
 	var availablePlugins []plugin.AsyncSearchPlugin
 	if s.pluginManager != nil {
@@ -1407,31 +1619,7 @@ func (s *SearchService) searchPlugins(keyword string, plugins []string, forceRef
 		}
 	}
 
-	// 恢复主程序缓存更新：确保最终合并结果被正确缓存
-	if cacheInitialized && config.AppConfig.CacheEnabled {
-		go func(res []model.SearchResult, kw string, key string) {
-			ttl := time.Duration(config.AppConfig.CacheTTLMinutes) * time.Minute
-
-			// 使用增强版缓存，确保与异步插件使用相同的序列化器
-			if enhancedTwoLevelCache != nil {
-				data, err := enhancedTwoLevelCache.GetSerializer().Serialize(res)
-				if err != nil {
-					fmt.Printf("[主程序] 缓存序列化失败: %s | 错误: %v\n", key, err)
-					return
-				}
-
-				// 主程序最后更新，覆盖可能有问题的异步插件缓存
-				// 使用同步方式确保数据写入磁盘
-				enhancedTwoLevelCache.SetBothLevels(key, data, ttl)
-				if config.AppConfig != nil && config.AppConfig.AsyncLogEnabled {
-					fmt.Printf("[主程序] 缓存更新完成: %s | 结果数: %d",
-						key, len(res))
-				}
-			}
-		}(allResults, keyword, cacheKey)
-	}
-
-	return allResults, nil
+	return allResults
 }
 
 // GetPluginManager 获取插件管理器
@@ -1445,11 +1633,11 @@ func (s *SearchService) GetPluginManager() *plugin.PluginManager {
 
 // ResultScore 搜索结果评分结构
 type ResultScore struct {
-	Result       model.SearchResult
-	TimeScore    float64 // 时间得分
-	KeywordScore int     // 关键词得分
-	PluginScore  int     // 插件等级得分
-	TotalScore   float64 // 综合得分
+	Result         model.SearchResult
+	TimeScore      float64 // 时间得分
+	RelevanceScore float64 // 相关度得分（已乘以权重）
+	PluginScore    int     // 插件等级得分
+	TotalScore     float64 // 综合得分
 }
 
 // 插件等级缓存
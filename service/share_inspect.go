@@ -0,0 +1,595 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"pansou/model"
+	utiljson "pansou/util/json"
+)
+
+const (
+	shareContentBucketName = "share_contents"
+	// maxInspectEntries 查看分享内容时最多读取的顶层条目数
+	maxInspectEntries = 100
+	// maxInspectCacheEntries 内存中最多保留的分享内容数，超出时丢弃部分条目（仍可从持久化存储读取）
+	maxInspectCacheEntries = 1000
+)
+
+// shareContents 分享根目录的内容
+type shareContents struct {
+	Title string
+	Files []model.ShareFile
+	More  bool // 根目录还有未读取的条目
+}
+
+// shareInspector 读取分享根目录内容，同时返回链接状态和说明
+type shareInspector func(ctx context.Context, item model.CheckItem, normalized string) (shareContents, string, string, error)
+
+// builtinInspectors 支持查看分享内容的网盘
+func (s *CheckService) builtinInspectors() map[string]shareInspector {
+	return map[string]shareInspector{
+		"quark":  s.inspectQuark,
+		"aliyun": s.inspectAliyun,
+		"123":    s.inspect123,
+		"tianyi": s.inspectTianyi,
+		"baidu":  s.inspectBaidu,
+	}
+}
+
+// Inspect 并发读取分享链接的顶层文件列表，结果顺序与items一致
+// 结果按链接状态缓存，缓存时间与链接检测结果相同
+func (s *CheckService) Inspect(items []model.CheckItem) model.ShareInspectResponse {
+	results := make([]model.ShareInspectResult, len(items))
+	forEachIndex(len(items), s.concurrency, func(index int) {
+		results[index] = s.inspectOne(items[index])
+	})
+
+	return model.ShareInspectResponse{
+		Results: results,
+	}
+}
+
+func (s *CheckService) inspectOne(item model.CheckItem) model.ShareInspectResult {
+	normalized, cacheKey, password := s.normalizeShareLink(nil, item.DiskType, item.URL, item.Password)
+	if normalized == "" {
+		return s.buildInspectResult(item, "", checkStateUncertain, "链接格式无效", shareContents{})
+	}
+
+	inspector, ok := s.inspectors[item.DiskType]
+	if !ok {
+		return s.buildInspectResult(item, normalized, checkStateUnsupported, "当前平台暂不支持查看分享内容", shareContents{})
+	}
+	item.Password = password
+
+	if cached, ok := s.getCachedInspect(cacheKey); ok {
+		cached.CacheHit = true
+		return cached
+	}
+
+	ctx := context.Background()
+	release, err := s.limiter.acquire(ctx, item.DiskType)
+	if err != nil {
+		return s.buildInspectResult(item, normalized, checkStateUncertain, "检测失败", shareContents{})
+	}
+	contents, state, summary, err := inspector(ctx, item, normalized)
+	release()
+
+	if err != nil {
+		return s.buildInspectResult(item, normalized, checkStateUncertain, "检测失败", shareContents{})
+	}
+
+	result := s.buildInspectResult(item, normalized, state, summary, contents)
+	s.setCachedInspect(cacheKey, result)
+	return result
+}
+
+func (s *CheckService) buildInspectResult(item model.CheckItem, normalized, state, summary string, contents shareContents) model.ShareInspectResult {
+	now := time.Now()
+	result := model.ShareInspectResult{
+		DiskType:      item.DiskType,
+		URL:           item.URL,
+		NormalizedURL: normalized,
+		State:         state,
+		CheckedAt:     now.UnixMilli(),
+		ExpiresAt:     now.Add(ttlForState(state)).UnixMilli(),
+		Summary:       summary,
+		Title:         contents.Title,
+		Files:         contents.Files,
+		Truncated:     contents.More,
+	}
+
+	for _, file := range contents.Files {
+		if file.IsDir {
+			result.DirCount++
+			continue
+		}
+		result.FileCount++
+		result.TotalSize += file.Size
+	}
+	return result
+}
+
+// inspectFailed 读取内容失败时的状态：链接本身有效则标记为不确定
+func inspectFailed(state, summary string, err error) (shareContents, string, string, error) {
+	if state == checkStateOK {
+		return shareContents{}, checkStateUncertain, "无法获取分享内容", err
+	}
+	return shareContents{}, state, summary, err
+}
+
+func (s *CheckService) inspectQuark(ctx context.Context, item model.CheckItem, normalized string) (shareContents, string, string, error) {
+	resourceID, password := extractQuarkShareIDAndPassword(normalized)
+	if resourceID == "" {
+		return shareContents{}, checkStateUncertain, "无法解析分享地址", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	stoken, state, summary, err := s.fetchQuarkStoken(ctx, resourceID, password)
+	if state != "" {
+		return shareContents{}, state, summary, err
+	}
+
+	detailURL := fmt.Sprintf("https://drive-pc.quark.cn/1/clouddrive/share/sharepage/detail?pwd_id=%s&stoken=%s&ver=2&pr=ucpro&pdir_fid=0&force=0&_page=1&_size=%d&_fetch_share=1&_fetch_total=1&_sort=file_type:asc,file_name:asc",
+		url.QueryEscape(resourceID), url.QueryEscape(stoken), maxInspectEntries)
+	body, _, err := s.doRequest(ctx, "GET", detailURL, nil, map[string]string{
+		"accept":        "application/json, text/plain, */*",
+		"origin":        "https://pan.quark.cn",
+		"referer":       "https://pan.quark.cn/",
+		"cache-control": "no-cache",
+	})
+	if err != nil {
+		return shareContents{}, checkStateUncertain, "详情请求失败", err
+	}
+
+	var response struct {
+		Code int `json:"code"`
+		Data struct {
+			Share struct {
+				Title string `json:"title"`
+			} `json:"share"`
+			List []struct {
+				FileName     string `json:"file_name"`
+				Size         int64  `json:"size"`
+				Dir          bool   `json:"dir"`
+				IncludeItems int    `json:"include_items"`
+			} `json:"list"`
+		} `json:"data"`
+		Metadata struct {
+			Total int `json:"_total"`
+		} `json:"metadata"`
+	}
+	if err := utiljson.Unmarshal(body, &response); err != nil || response.Code != 0 {
+		return shareContents{}, checkStateUncertain, "无法获取分享内容", nil
+	}
+
+	contents := shareContents{Title: response.Data.Share.Title}
+	for _, file := range response.Data.List {
+		contents.Files = append(contents.Files, model.ShareFile{
+			Name:      file.FileName,
+			Size:      file.Size,
+			IsDir:     file.Dir,
+			ItemCount: file.IncludeItems,
+		})
+	}
+	contents.More = response.Metadata.Total > len(contents.Files)
+	return contents, checkStateOK, "链接有效", nil
+}
+
+func (s *CheckService) inspectAliyun(ctx context.Context, item model.CheckItem, normalized string) (shareContents, string, string, error) {
+	shareID := extractAliyunShareID(normalized)
+	if shareID == "" {
+		return shareContents{}, checkStateUncertain, "无法解析分享地址", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	title, state, summary, err := s.fetchAliyunShare(ctx, shareID)
+	if state != checkStateOK {
+		return shareContents{}, state, summary, err
+	}
+
+	headers := map[string]string{
+		"content-type": "application/json",
+		"origin":       "https://www.alipan.com",
+		"referer":      "https://www.alipan.com/",
+		"x-canary":     "client=web,app=share,version=v2.3.1",
+	}
+	tokenBody, _, err := s.doJSONRequest(ctx, "POST", "https://api.aliyundrive.com/v2/share_link/get_share_token", map[string]string{
+		"share_id":  shareID,
+		"share_pwd": item.Password,
+	}, headers)
+	if err != nil {
+		return shareContents{}, checkStateUncertain, "请求失败", err
+	}
+
+	var tokenResp struct {
+		ShareToken string `json:"share_token"`
+		Code       string `json:"code"`
+		Message    string `json:"message"`
+	}
+	_ = utiljson.Unmarshal(tokenBody, &tokenResp)
+	if tokenResp.ShareToken == "" {
+		if containsAny(strings.ToLower(tokenResp.Code), []string{"sharepwd", "password"}) {
+			return shareContents{}, checkStateLocked, "提取码错误或缺失", nil
+		}
+		return shareContents{}, checkStateUncertain, coalesce(tokenResp.Message, "无法获取分享内容"), nil
+	}
+
+	headers["x-share-token"] = tokenResp.ShareToken
+	listBody, _, err := s.doJSONRequest(ctx, "POST", "https://api.aliyundrive.com/adrive/v2/file/list_by_share", map[string]any{
+		"share_id":        shareID,
+		"parent_file_id":  "root",
+		"limit":           maxInspectEntries,
+		"order_by":        "name",
+		"order_direction": "ASC",
+	}, headers)
+	if err != nil {
+		return shareContents{}, checkStateUncertain, "详情请求失败", err
+	}
+
+	var listResp struct {
+		Items []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+			Size int64  `json:"size"`
+		} `json:"items"`
+		NextMarker string `json:"next_marker"`
+	}
+	if err := utiljson.Unmarshal(listBody, &listResp); err != nil {
+		return shareContents{}, checkStateUncertain, "无法获取分享内容", nil
+	}
+
+	contents := shareContents{Title: title, More: listResp.NextMarker != ""}
+	for _, file := range listResp.Items {
+		contents.Files = append(contents.Files, model.ShareFile{
+			Name:  file.Name,
+			Size:  file.Size,
+			IsDir: file.Type == "folder",
+		})
+	}
+	return contents, checkStateOK, "链接有效", nil
+}
+
+func (s *CheckService) inspect123(ctx context.Context, item model.CheckItem, normalized string) (shareContents, string, string, error) {
+	shareKey := extract123ShareKey(normalized)
+	if shareKey == "" {
+		return shareContents{}, checkStateUncertain, "无法解析分享地址", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	apiURL := fmt.Sprintf("https://www.123pan.com/api/share/get?limit=%d&next=1&orderBy=file_name&orderDirection=asc&shareKey=%s&SharePwd=%s&ParentFileId=0&Page=1&event=homeListFile&operateType=1",
+		maxInspectEntries, url.QueryEscape(shareKey), url.QueryEscape(item.Password))
+	body, _, err := s.doRequest(ctx, "GET", apiURL, nil, nil)
+	if err != nil {
+		return shareContents{}, checkStateUncertain, "请求失败", err
+	}
+
+	var response struct {
+		Code int `json:"code"`
+		Data struct {
+			Next     string `json:"Next"`
+			InfoList []struct {
+				FileName string `json:"FileName"`
+				Type     int    `json:"Type"`
+				Size     int64  `json:"Size"`
+			} `json:"InfoList"`
+		} `json:"data"`
+		Message string `json:"message"`
+	}
+	if err := utiljson.Unmarshal(body, &response); err != nil || response.Code != 0 {
+		// 列表接口失败时按检测逻辑判断链接状态
+		return inspectFailed(s.check123(ctx, item, normalized))
+	}
+
+	contents := shareContents{More: response.Data.Next != "" && response.Data.Next != "-1"}
+	for _, file := range response.Data.InfoList {
+		contents.Files = append(contents.Files, model.ShareFile{
+			Name:  file.FileName,
+			Size:  file.Size,
+			IsDir: file.Type == 1,
+		})
+	}
+	return contents, checkStateOK, "链接有效", nil
+}
+
+func (s *CheckService) inspectTianyi(ctx context.Context, item model.CheckItem, normalized string) (shareContents, string, string, error) {
+	shareCode, password, referer := extractTianyiShareInfo(normalized, item.Password)
+	if shareCode == "" {
+		return shareContents{}, checkStateUncertain, "无法解析分享地址", nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	headers := tianyiJSONHeaders(referer)
+	info, ok, err := s.fetchTianyiShareInfo(ctx, shareCode, headers)
+	if err != nil {
+		return shareContents{}, checkStateUncertain, "请求失败", err
+	}
+	if !ok {
+		return inspectFailed(s.checkTianyi(ctx, item, normalized))
+	}
+
+	shareID := jsonString(info.ShareID)
+	if info.NeedAccessCode == 1 {
+		var state, summary string
+		shareID, state, summary, err = s.verifyTianyiAccessCode(ctx, shareCode, password, headers)
+		if state != "" {
+			return shareContents{}, state, summary, err
+		}
+	}
+
+	contents := shareContents{Title: info.FileName}
+	if !info.IsFolder {
+		contents.Files = []model.ShareFile{{Name: info.FileName, Size: jsonInt64(info.FileSize)}}
+		return contents, checkStateOK, "链接有效", nil
+	}
+
+	fileID := jsonString(info.FileID)
+	listURL := fmt.Sprintf("https://cloud.189.cn/api/open/share/listShareDir.action?pageNum=1&pageSize=%d&fileId=%s&shareDirFileId=%s&isFolder=true&shareId=%s&shareMode=%s&iconOption=5&orderBy=filename&descending=false&accessCode=%s",
+		maxInspectEntries, url.QueryEscape(fileID), url.QueryEscape(fileID), url.QueryEscape(shareID), url.QueryEscape(jsonString(info.ShareMode)), url.QueryEscape(password))
+	body, _, err := s.doRequest(ctx, "GET", listURL, nil, headers)
+	if err != nil {
+		return shareContents{}, checkStateUncertain, "详情请求失败", err
+	}
+
+	var listResp struct {
+		ResCode    int `json:"res_code"`
+		FileListAO struct {
+			Count      int `json:"count"`
+			FolderList []struct {
+				Name string `json:"name"`
+			} `json:"folderList"`
+			FileList []struct {
+				Name string `json:"name"`
+				Size int64  `json:"size"`
+			} `json:"fileList"`
+		} `json:"fileListAO"`
+	}
+	if err := utiljson.Unmarshal(body, &listResp); err != nil || listResp.ResCode != 0 {
+		return shareContents{}, checkStateUncertain, "无法获取分享内容", nil
+	}
+
+	for _, folder := range listResp.FileListAO.FolderList {
+		contents.Files = append(contents.Files, model.ShareFile{Name: folder.Name, IsDir: true})
+	}
+	for _, file := range listResp.FileListAO.FileList {
+		contents.Files = append(contents.Files, model.ShareFile{Name: file.Name, Size: file.Size})
+	}
+	contents.More = listResp.FileListAO.Count > len(contents.Files)
+	return contents, checkStateOK, "链接有效", nil
+}
+
+// tianyiShareInfo 天翼云盘分享基本信息
+type tianyiShareInfo struct {
+	ResCode        int    `json:"res_code"`
+	ShareID        any    `json:"shareId"`
+	FileID         any    `json:"fileId"`
+	FileName       string `json:"fileName"`
+	FileSize       any    `json:"fileSize"`
+	IsFolder       bool   `json:"isFolder"`
+	ShareMode      any    `json:"shareMode"`
+	NeedAccessCode int    `json:"needAccessCode"`
+}
+
+func tianyiJSONHeaders(referer string) map[string]string {
+	return map[string]string{
+		"accept":    "application/json;charset=UTF-8",
+		"referer":   referer,
+		"sign-type": "1",
+	}
+}
+
+// fetchTianyiShareInfo 以JSON格式获取天翼云盘分享信息，响应无法解析或接口返回错误时返回false
+func (s *CheckService) fetchTianyiShareInfo(ctx context.Context, shareCode string, headers map[string]string) (tianyiShareInfo, bool, error) {
+	var info tianyiShareInfo
+	infoURL := "https://cloud.189.cn/api/open/share/getShareInfoByCodeV2.action?shareCode=" + url.QueryEscape(shareCode)
+	body, _, err := s.doRequest(ctx, "GET", infoURL, nil, headers)
+	if err != nil {
+		return info, false, err
+	}
+	if err := utiljson.Unmarshal(body, &info); err != nil || info.ResCode != 0 {
+		return info, false, nil
+	}
+	return info, true, nil
+}
+
+// verifyTianyiAccessCode 校验天翼云盘访问码，成功时返回分享ID，失败时返回检测状态和说明
+func (s *CheckService) verifyTianyiAccessCode(ctx context.Context, shareCode, password string, headers map[string]string) (string, string, string, error) {
+	if password == "" {
+		return "", checkStateLocked, "需要访问码", nil
+	}
+
+	checkURL := fmt.Sprintf("https://cloud.189.cn/api/open/share/checkAccessCode.action?shareCode=%s&accessCode=%s", url.QueryEscape(shareCode), url.QueryEscape(password))
+	body, _, err := s.doRequest(ctx, "GET", checkURL, nil, headers)
+	if err != nil {
+		return "", checkStateUncertain, "验证失败", err
+	}
+
+	var accessResp struct {
+		ResCode int `json:"res_code"`
+		ShareID any `json:"shareId"`
+	}
+	if err := utiljson.Unmarshal(body, &accessResp); err != nil || accessResp.ResCode != 0 || jsonString(accessResp.ShareID) == "" {
+		return "", checkStateLocked, "访问码错误", nil
+	}
+	return jsonString(accessResp.ShareID), "", "", nil
+}
+
+func (s *CheckService) inspectBaidu(ctx context.Context, item model.CheckItem, normalized string) (shareContents, string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	listResp, state, summary, err := s.fetchBaiduShareList(ctx, normalized, maxInspectEntries)
+	if state != checkStateOK {
+		return shareContents{}, state, summary, err
+	}
+
+	contents := shareContents{
+		Title: strings.TrimPrefix(listResp.Title, "/"),
+		More:  len(listResp.List) >= maxInspectEntries,
+	}
+	for _, file := range listResp.List {
+		contents.Files = append(contents.Files, model.ShareFile{
+			Name:  file.ServerFilename,
+			Size:  jsonInt64(file.Size),
+			IsDir: jsonInt64(file.Isdir) == 1,
+		})
+	}
+	return contents, state, summary, nil
+}
+
+// jsonInt64 读取可能是数字或字符串的JSON数值
+func jsonInt64(value any) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	default:
+		return 0
+	}
+}
+
+// jsonString 读取可能是数字或字符串的JSON字段
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatInt(int64(v), 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (s *CheckService) getCachedInspect(key string) (model.ShareInspectResult, bool) {
+	now := time.Now().UnixMilli()
+
+	s.mu.Lock()
+	result, ok := s.inspectCache[key]
+	s.mu.Unlock()
+	if !ok {
+		result, ok = s.loadPersistentInspect(key)
+		if !ok {
+			return model.ShareInspectResult{}, false
+		}
+		s.mu.Lock()
+		s.storeInspectLocked(key, result)
+		s.mu.Unlock()
+	}
+
+	if now > result.ExpiresAt {
+		s.mu.Lock()
+		delete(s.inspectCache, key)
+		s.mu.Unlock()
+		s.deletePersistentInspect(key)
+		return model.ShareInspectResult{}, false
+	}
+	return result, true
+}
+
+func (s *CheckService) setCachedInspect(key string, result model.ShareInspectResult) {
+	s.mu.Lock()
+	s.storeInspectLocked(key, result)
+	s.mu.Unlock()
+
+	if s.cacheDB == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(result); err != nil {
+		return
+	}
+
+	_ = s.cacheDB.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(shareContentBucketName))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Put([]byte(key), buf.Bytes())
+	})
+}
+
+// storeInspectLocked 将分享内容放入内存缓存，达到上限时先清理过期条目，仍然超出则随机丢弃条目，调用方需持有s.mu
+func (s *CheckService) storeInspectLocked(key string, result model.ShareInspectResult) {
+	if _, exists := s.inspectCache[key]; !exists && len(s.inspectCache) >= maxInspectCacheEntries {
+		now := time.Now().UnixMilli()
+		for k, cached := range s.inspectCache {
+			if now > cached.ExpiresAt {
+				delete(s.inspectCache, k)
+			}
+		}
+		for k := range s.inspectCache {
+			if len(s.inspectCache) < maxInspectCacheEntries {
+				break
+			}
+			delete(s.inspectCache, k)
+		}
+	}
+	s.inspectCache[key] = result
+}
+
+func (s *CheckService) loadPersistentInspect(key string) (model.ShareInspectResult, bool) {
+	if s.cacheDB == nil {
+		return model.ShareInspectResult{}, false
+	}
+
+	var result model.ShareInspectResult
+	var found bool
+	_ = s.cacheDB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(shareContentBucketName))
+		if bucket == nil {
+			return nil
+		}
+
+		raw := bucket.Get([]byte(key))
+		if len(raw) == 0 {
+			return nil
+		}
+
+		if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&result); err != nil {
+			return nil
+		}
+		found = true
+		return nil
+	})
+
+	return result, found
+}
+
+func (s *CheckService) deletePersistentInspect(key string) {
+	if s.cacheDB == nil {
+		return
+	}
+
+	_ = s.cacheDB.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(shareContentBucketName))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(key))
+	})
+}
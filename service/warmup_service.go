@@ -0,0 +1,362 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+	"pansou/util/cache"
+	utiljson "pansou/util/json"
+)
+
+const (
+	// warmupYieldInterval 并发预算被占满时，预热搜索再次尝试的间隔
+	warmupYieldInterval = 200 * time.Millisecond
+
+	// keywordAccessFile 关键词访问次数的持久化文件，保存在缓存目录中
+	keywordAccessFile = "keyword_access.json"
+)
+
+var (
+	// searchKeywordAccess 用户搜索关键词的访问记录，供缓存预热挑选热门关键词
+	searchKeywordAccess = newCacheAccessTracker(maxTrackedCacheKeys)
+
+	// liveSearches 进行中的用户搜索数
+	liveSearches int64
+)
+
+// warmupKeyword 待预热的关键词及其来源
+type warmupKeyword struct {
+	keyword string
+	source  string // file或top
+}
+
+// WarmupService 缓存预热服务
+// 启动时和定期预先执行关键词文件中的关键词及访问最多的关键词的搜索，避免重启后第一批用户承担完整的搜索延迟
+type WarmupService struct {
+	mu        sync.Mutex
+	status    model.WarmupStatus
+	trigger   chan struct{}
+	startOnce sync.Once
+	active    int // 进行中的预热搜索数
+
+	searcher     *SearchService
+	interval     time.Duration
+	keywordsFile string
+	topKeywords  int
+	concurrency  int
+}
+
+var (
+	globalWarmupService     *WarmupService
+	globalWarmupServiceOnce sync.Once
+)
+
+// GetWarmupService 获取全局缓存预热服务
+func GetWarmupService() *WarmupService {
+	globalWarmupServiceOnce.Do(func() {
+		globalWarmupService = NewWarmupService()
+	})
+	return globalWarmupService
+}
+
+// NewWarmupService 创建缓存预热服务
+func NewWarmupService() *WarmupService {
+	return &WarmupService{
+		status: model.WarmupStatus{
+			Enabled:         config.AppConfig.WarmupEnabled,
+			IntervalMinutes: int(config.AppConfig.WarmupInterval / time.Minute),
+			Concurrency:     config.AppConfig.WarmupConcurrency,
+			KeywordsFile:    config.AppConfig.WarmupKeywordsFile,
+			TopKeywords:     config.AppConfig.WarmupTopKeywords,
+			Results:         []model.WarmupResult{},
+		},
+		trigger:      make(chan struct{}, 1),
+		interval:     config.AppConfig.WarmupInterval,
+		keywordsFile: config.AppConfig.WarmupKeywordsFile,
+		topKeywords:  config.AppConfig.WarmupTopKeywords,
+		concurrency:  config.AppConfig.WarmupConcurrency,
+	}
+}
+
+// Start 立即执行一轮预热并启动定时预热，重复调用只会启动一次
+func (w *WarmupService) Start(searchService *SearchService) {
+	w.startOnce.Do(func() {
+		loadKeywordAccess()
+
+		w.mu.Lock()
+		w.bind(searchService)
+		w.status.Enabled = true
+		w.mu.Unlock()
+
+		go w.loop()
+	})
+}
+
+// bind 创建预热使用的搜索服务，预热搜索不计入访问统计，调用方需持有锁
+func (w *WarmupService) bind(searchService *SearchService) {
+	if w.searcher == nil && searchService != nil {
+		w.searcher = &SearchService{pluginManager: searchService.pluginManager, background: true}
+	}
+}
+
+func (w *WarmupService) loop() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce()
+
+		w.mu.Lock()
+		w.status.NextRunAt = time.Now().Add(w.interval).UnixMilli()
+		w.mu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-w.trigger:
+		}
+	}
+}
+
+// Trigger 请求立即执行一轮预热，已在执行或已排队时返回false
+// 未启动定时预热时使用searchService在后台单独执行一轮
+func (w *WarmupService) Trigger(searchService *SearchService) bool {
+	w.mu.Lock()
+	running, enabled := w.status.Running, w.status.Enabled && w.searcher != nil
+	if !enabled {
+		w.bind(searchService)
+	}
+	w.mu.Unlock()
+	if running {
+		return false
+	}
+
+	if !enabled {
+		go w.RunOnce()
+		return true
+	}
+
+	select {
+	case w.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status 获取预热状态和各关键词的预热结果
+func (w *WarmupService) Status() model.WarmupStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := w.status
+	status.Results = append([]model.WarmupResult{}, w.status.Results...)
+	return status
+}
+
+// RunOnce 执行一轮预热
+func (w *WarmupService) RunOnce() {
+	w.mu.Lock()
+	if w.status.Running || w.searcher == nil {
+		w.mu.Unlock()
+		return
+	}
+	w.status.Running = true
+	w.status.LastStartedAt = time.Now().UnixMilli()
+	w.status.LastError = ""
+	w.mu.Unlock()
+
+	keywords, err := w.collectKeywords()
+
+	w.mu.Lock()
+	if err != nil {
+		w.status.LastError = err.Error()
+	}
+	w.status.Progress = model.WarmupProgress{KeywordsTotal: len(keywords)}
+	w.status.Results = make([]model.WarmupResult, 0, len(keywords))
+	w.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, item := range keywords {
+		w.acquire()
+		wg.Add(1)
+		go func(item warmupKeyword) {
+			defer wg.Done()
+			defer w.release()
+
+			result := w.warm(item)
+
+			w.mu.Lock()
+			progress := &w.status.Progress
+			progress.KeywordsDone++
+			switch {
+			case result.Cached:
+				progress.Cached++
+			case result.Error != "":
+				progress.Failed++
+			default:
+				progress.Searched++
+			}
+			w.status.Results = append(w.status.Results, result)
+			w.mu.Unlock()
+		}(item)
+	}
+	wg.Wait()
+
+	if err := SaveKeywordAccess(); err != nil {
+		fmt.Printf("[缓存预热] 保存关键词访问记录失败: %v\n", err)
+	}
+
+	w.mu.Lock()
+	w.status.Running = false
+	w.status.Rounds++
+	w.status.LastFinishedAt = time.Now().UnixMilli()
+	progress := w.status.Progress
+	w.mu.Unlock()
+
+	fmt.Printf("[缓存预热] 完成: 关键词 %d 个，搜索 %d 个，已有缓存 %d 个，失败 %d 个\n",
+		progress.KeywordsTotal, progress.Searched, progress.Cached, progress.Failed)
+}
+
+// collectKeywords 收集本轮待预热的关键词：先是关键词文件中的关键词，再是访问次数最多的关键词
+// 关键词文件读取失败时仍返回热门关键词
+func (w *WarmupService) collectKeywords() ([]warmupKeyword, error) {
+	var keywords []warmupKeyword
+	seen := make(map[string]bool)
+	add := func(keyword, source string) {
+		keyword = strings.TrimSpace(keyword)
+		normalized := strings.ToLower(keyword)
+		if keyword == "" || seen[normalized] {
+			return
+		}
+		seen[normalized] = true
+		keywords = append(keywords, warmupKeyword{keyword: keyword, source: source})
+	}
+
+	var fileErr error
+	if w.keywordsFile != "" {
+		data, err := os.ReadFile(w.keywordsFile)
+		if err != nil {
+			fileErr = fmt.Errorf("读取预热关键词文件失败: %v", err)
+		} else {
+			for _, line := range strings.Split(string(data), "\n") {
+				if line = strings.TrimSpace(line); !strings.HasPrefix(line, "#") {
+					add(line, "file")
+				}
+			}
+		}
+	}
+
+	if w.topKeywords > 0 {
+		for _, keyword := range searchKeywordAccess.top(w.topKeywords) {
+			add(keyword, "top")
+		}
+	}
+	return keywords, fileErr
+}
+
+// warm 预热一个关键词，使用默认频道和全部插件搜索，TG和插件结果都已缓存时跳过
+func (w *WarmupService) warm(item warmupKeyword) model.WarmupResult {
+	result := model.WarmupResult{Keyword: item.keyword, Source: item.source}
+
+	channels := config.AppConfig.DefaultChannels
+	if warmupCached(item.keyword, channels) {
+		result.Cached = true
+		return result
+	}
+
+	start := time.Now()
+	response, err := w.searcher.Search(item.keyword, channels, 0, false, "merged_by_type", "all", nil, nil, nil)
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ResultCount = response.Total
+	return result
+}
+
+// warmupCached 判断关键词的默认搜索结果是否都已在缓存中
+func warmupCached(keyword string, channels []string) bool {
+	if !CacheAvailable() {
+		return false
+	}
+	if !enhancedTwoLevelCache.Has(cache.GenerateTGCacheKey(keyword, channels)) {
+		return false
+	}
+	if config.AppConfig.AsyncPluginEnabled && !enhancedTwoLevelCache.Has(cache.GeneratePluginCacheKey(keyword, nil)) {
+		return false
+	}
+	return true
+}
+
+// acquire 等待预热搜索的并发名额
+// 进行中的用户搜索与预热搜索共享并发预算，预算被用户搜索占满时预热让出，直到有空闲名额
+func (w *WarmupService) acquire() {
+	for {
+		live := atomic.LoadInt64(&liveSearches)
+
+		w.mu.Lock()
+		if int64(w.active)+live < int64(w.concurrency) {
+			w.active++
+			w.mu.Unlock()
+			return
+		}
+		if live > 0 {
+			w.status.Yields++
+		}
+		w.mu.Unlock()
+
+		time.Sleep(warmupYieldInterval)
+	}
+}
+
+// release 释放预热搜索的并发名额
+func (w *WarmupService) release() {
+	w.mu.Lock()
+	w.active--
+	w.mu.Unlock()
+}
+
+// loadKeywordAccess 从缓存目录加载关键词访问次数，使重启后仍能预热热门关键词
+func loadKeywordAccess() {
+	data, err := os.ReadFile(filepath.Join(config.AppConfig.CachePath, keywordAccessFile))
+	if err != nil {
+		return
+	}
+
+	var hits map[string]int64
+	if err := utiljson.Unmarshal(data, &hits); err != nil {
+		fmt.Printf("[缓存预热] 关键词访问记录解析失败: %v\n", err)
+		return
+	}
+
+	for keyword, count := range hits {
+		searchKeywordAccess.add(keyword, count)
+	}
+}
+
+// SaveKeywordAccess 将关键词访问次数保存到缓存目录
+// 先写临时文件再替换，避免写入中断导致文件损坏
+func SaveKeywordAccess() error {
+	data, err := utiljson.Marshal(searchKeywordAccess.snapshot())
+	if err != nil {
+		return err
+	}
+
+	dir := config.AppConfig.CachePath
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmpFile := filepath.Join(dir, keywordAccessFile+".tmp")
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filepath.Join(dir, keywordAccessFile))
+}
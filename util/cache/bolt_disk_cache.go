@@ -0,0 +1,584 @@
+package cache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"pansou/util/json"
+)
+
+// boltCacheFile bbolt存储的缓存文件名，保存在缓存目录下
+const boltCacheFile = "cache.db"
+
+// boltEvictRatio 空间不足时一次淘汰到容量上限的该比例，避免每次写入都排序淘汰
+const boltEvictRatio = 0.9
+
+// boltCompactTxMaxSize 压缩时单个事务写入的最大字节数
+const boltCompactTxMaxSize = 64 * 1024 * 1024
+
+// boltBulkWriteSize 批量写入（迁移、导入快照）时单个事务写入的最大缓存项数
+const boltBulkWriteSize = 500
+
+// boltStore 所有分片共享的bbolt数据库
+// 压缩时需要替换数据库文件，期间独占访问
+type boltStore struct {
+	path  string
+	mutex sync.RWMutex
+	db    *bolt.DB
+}
+
+// openBoltStore 打开bbolt缓存文件
+func openBoltStore(path string) (*boltStore, error) {
+	// 上次压缩替换文件时中断，恢复保留的原文件
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if _, err := os.Stat(path + ".bak"); err == nil {
+			os.Rename(path+".bak", path)
+		}
+	}
+
+	db, err := openBoltDB(path)
+	if err != nil {
+		return nil, err
+	}
+	return &boltStore{path: path, db: db}, nil
+}
+
+func openBoltDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{
+		Timeout:        time.Second,
+		NoFreelistSync: true,
+		FreelistType:   bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("打开缓存文件失败: %v", err)
+	}
+	return db, nil
+}
+
+// view 执行只读事务
+func (s *boltStore) view(fn func(*bolt.Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.db.View(fn)
+}
+
+// batch 执行写事务，并发的写入会合并提交，fn可能被重复执行，必须是幂等的
+func (s *boltStore) batch(fn func(*bolt.Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.db.Batch(fn)
+}
+
+// update 执行写事务，用于一次写入大量数据，不与其他写入合并
+func (s *boltStore) update(fn func(*bolt.Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.db.Update(fn)
+}
+
+// fileSize 获取缓存文件大小
+func (s *boltStore) fileSize() int64 {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// compact 将数据复制到新文件以回收已删除数据占用的空间，完成后替换原文件
+func (s *boltStore) compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tmpPath := s.path + ".compact"
+	os.Remove(tmpPath)
+
+	dst, err := openBoltDB(tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := bolt.Compact(dst, s.db, boltCompactTxMaxSize); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("压缩缓存文件失败: %v", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := s.db.Close(); err != nil {
+		os.Remove(tmpPath)
+		return s.reopen(fmt.Errorf("关闭缓存文件失败: %v", err))
+	}
+
+	// 先保留原文件，压缩后的文件无法使用时恢复
+	backupPath := s.path + ".bak"
+	if err := os.Rename(s.path, backupPath); err != nil {
+		os.Remove(tmpPath)
+		return s.reopen(fmt.Errorf("替换缓存文件失败: %v", err))
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		os.Rename(backupPath, s.path)
+		return s.reopen(fmt.Errorf("替换缓存文件失败: %v", err))
+	}
+
+	db, err := openBoltDB(s.path)
+	if err != nil {
+		os.Remove(s.path)
+		os.Rename(backupPath, s.path)
+		return s.reopen(err)
+	}
+	os.Remove(backupPath)
+	s.db = db
+	return nil
+}
+
+// reopen 压缩失败后重新打开原文件，返回压缩失败的原因
+func (s *boltStore) reopen(cause error) error {
+	db, err := openBoltDB(s.path)
+	if err != nil {
+		return fmt.Errorf("%v，重新打开原文件失败: %v", cause, err)
+	}
+	s.db = db
+	return cause
+}
+
+// BoltDiskCache 基于bbolt的磁盘缓存分片
+// 所有分片的数据保存在同一个文件中，每个分片使用独立的数据、元数据和过期时间索引bucket
+type BoltDiskCache struct {
+	store        *boltStore
+	dataBucket   []byte
+	metaBucket   []byte
+	expiryBucket []byte // 键为过期时间（纳秒，大端序）+缓存键，用于按过期时间顺序清理
+	maxSizeMB    int
+	metadata     map[string]*diskCacheMetadata
+	mutex        sync.RWMutex
+	currSize     int64
+}
+
+// newBoltDiskCache 创建bbolt磁盘缓存分片，name用于区分同一文件中的不同分片
+func newBoltDiskCache(store *boltStore, name string, maxSizeMB int) (*BoltDiskCache, error) {
+	c := &BoltDiskCache{
+		store:        store,
+		dataBucket:   []byte(name + "_data"),
+		metaBucket:   []byte(name + "_meta"),
+		expiryBucket: []byte(name + "_expiry"),
+		maxSizeMB:    maxSizeMB,
+		metadata:     make(map[string]*diskCacheMetadata),
+	}
+
+	if err := store.batch(c.createBuckets); err != nil {
+		return nil, fmt.Errorf("创建缓存bucket失败: %v", err)
+	}
+
+	// 加载现有缓存元数据
+	if err := c.loadMetadata(); err != nil {
+		return nil, err
+	}
+
+	// 启动周期性清理
+	go c.startCleanupTask()
+
+	return c, nil
+}
+
+func (c *BoltDiskCache) createBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{c.dataBucket, c.metaBucket, c.expiryBucket} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 加载元数据
+func (c *BoltDiskCache) loadMetadata() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.store.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(c.metaBucket).ForEach(func(key, value []byte) error {
+			var meta diskCacheMetadata
+			if err := json.Unmarshal(value, &meta); err != nil {
+				return nil
+			}
+			c.metadata[meta.Key] = &meta
+			c.currSize += int64(meta.Size)
+			return nil
+		})
+	})
+}
+
+// expiryIndexKey 过期时间索引的键
+func expiryIndexKey(expiry time.Time, key string) []byte {
+	indexKey := make([]byte, 8+len(key))
+	binary.BigEndian.PutUint64(indexKey, uint64(expiry.UnixNano()))
+	copy(indexKey[8:], key)
+	return indexKey
+}
+
+// deleteEntry 在事务中删除缓存项的数据、元数据和过期时间索引
+func (c *BoltDiskCache) deleteEntry(tx *bolt.Tx, key string, meta *diskCacheMetadata) error {
+	if err := tx.Bucket(c.dataBucket).Delete([]byte(key)); err != nil {
+		return err
+	}
+	if err := tx.Bucket(c.metaBucket).Delete([]byte(key)); err != nil {
+		return err
+	}
+	return tx.Bucket(c.expiryBucket).Delete(expiryIndexKey(meta.Expiry, key))
+}
+
+// Set 设置缓存
+func (c *BoltDiskCache) Set(key string, data []byte, ttl time.Duration) error {
+	return c.SetWithTimestamp(key, data, ttl, time.Now())
+}
+
+// SetWithTimestamp 设置缓存，并指定最后修改时间
+func (c *BoltDiskCache) SetWithTimestamp(key string, data []byte, ttl time.Duration, lastModified time.Time) error {
+	item := bulkCacheItem{Key: key, Data: data, TTL: ttl, LastModified: lastModified}
+	return c.write([]bulkCacheItem{item}, c.store.batch)
+}
+
+// setMany 在一个事务中写入多个缓存项，用于迁移和导入快照等大量写入
+func (c *BoltDiskCache) setMany(items []bulkCacheItem) error {
+	return c.write(items, c.store.update)
+}
+
+// write 通过commit执行的写事务写入缓存项，空间不足时同一事务中淘汰最久未使用的缓存项
+func (c *BoltDiskCache) write(items []bulkCacheItem, commit func(func(*bolt.Tx) error) error) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	metas := make(map[string]*diskCacheMetadata, len(items))
+	metaData := make(map[string][]byte, len(items))
+	data := make(map[string][]byte, len(items))
+	for _, item := range items {
+		meta := &diskCacheMetadata{
+			Key:          item.Key,
+			Expiry:       now.Add(item.TTL),
+			LastUsed:     now,
+			LastModified: item.LastModified,
+			Size:         len(item.Data),
+		}
+		encoded, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		// 同一键出现多次时以最后一次为准
+		metas[item.Key] = meta
+		metaData[item.Key] = encoded
+		data[item.Key] = item.Data
+	}
+
+	newSize := c.currSize
+	for key, meta := range metas {
+		newSize += int64(meta.Size)
+		if oldMeta := c.metadata[key]; oldMeta != nil {
+			newSize -= int64(oldMeta.Size)
+		}
+	}
+
+	// 检查空间
+	var evicted map[string]*diskCacheMetadata
+	if maxSize := int64(c.maxSizeMB) * 1024 * 1024; newSize > maxSize {
+		evicted = c.selectLRU(metas, newSize-int64(float64(maxSize)*boltEvictRatio))
+	}
+
+	err := commit(func(tx *bolt.Tx) error {
+		for evictKey, evictMeta := range evicted {
+			if err := c.deleteEntry(tx, evictKey, evictMeta); err != nil {
+				return err
+			}
+		}
+		for key, meta := range metas {
+			if oldMeta := c.metadata[key]; oldMeta != nil {
+				if err := tx.Bucket(c.expiryBucket).Delete(expiryIndexKey(oldMeta.Expiry, key)); err != nil {
+					return err
+				}
+			}
+			if err := tx.Bucket(c.dataBucket).Put([]byte(key), data[key]); err != nil {
+				return err
+			}
+			if err := tx.Bucket(c.metaBucket).Put([]byte(key), metaData[key]); err != nil {
+				return err
+			}
+			if err := tx.Bucket(c.expiryBucket).Put(expiryIndexKey(meta.Expiry, key), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("写入缓存失败: %v", err)
+	}
+
+	// 更新内存中的元数据
+	for evictKey, evictMeta := range evicted {
+		c.currSize -= int64(evictMeta.Size)
+		delete(c.metadata, evictKey)
+	}
+	for key, meta := range metas {
+		if oldMeta := c.metadata[key]; oldMeta != nil {
+			c.currSize -= int64(oldMeta.Size)
+		}
+		c.metadata[key] = meta
+		c.currSize += int64(meta.Size)
+	}
+
+	return nil
+}
+
+// selectLRU 按最后使用时间选出需要淘汰的缓存项（不包括正在写入的except），使释放的空间不少于requiredSpace
+func (c *BoltDiskCache) selectLRU(except map[string]*diskCacheMetadata, requiredSpace int64) map[string]*diskCacheMetadata {
+	items := make([]*diskCacheMetadata, 0, len(c.metadata))
+	for key, meta := range c.metadata {
+		if _, writing := except[key]; !writing {
+			items = append(items, meta)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].LastUsed.Before(items[j].LastUsed)
+	})
+
+	evicted := make(map[string]*diskCacheMetadata)
+	var freed int64
+	for _, meta := range items {
+		if freed >= requiredSpace {
+			break
+		}
+		evicted[meta.Key] = meta
+		freed += int64(meta.Size)
+	}
+	return evicted
+}
+
+// Get 获取缓存
+func (c *BoltDiskCache) Get(key string) ([]byte, bool, error) {
+	c.mutex.RLock()
+	meta, exists := c.metadata[key]
+	c.mutex.RUnlock()
+
+	if !exists {
+		return nil, false, nil
+	}
+
+	// 检查是否过期
+	if time.Now().After(meta.Expiry) {
+		c.Delete(key)
+		return nil, false, nil
+	}
+
+	var data []byte
+	err := c.store.view(func(tx *bolt.Tx) error {
+		if value := tx.Bucket(c.dataBucket).Get([]byte(key)); value != nil {
+			data = make([]byte, len(value))
+			copy(data, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if data == nil {
+		return nil, false, nil
+	}
+
+	// 更新最后使用时间（仅在内存中记录，用于LRU淘汰）
+	c.mutex.Lock()
+	meta.LastUsed = time.Now()
+	c.mutex.Unlock()
+
+	return data, true, nil
+}
+
+// Delete 删除缓存
+func (c *BoltDiskCache) Delete(key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	meta, exists := c.metadata[key]
+	if !exists {
+		return nil
+	}
+
+	if err := c.store.batch(func(tx *bolt.Tx) error {
+		return c.deleteEntry(tx, key, meta)
+	}); err != nil {
+		return err
+	}
+
+	c.currSize -= int64(meta.Size)
+	delete(c.metadata, key)
+	return nil
+}
+
+// Has 检查缓存是否存在
+func (c *BoltDiskCache) Has(key string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	meta, exists := c.metadata[key]
+	return exists && !time.Now().After(meta.Expiry)
+}
+
+// 清理过期项，按过期时间索引顺序查找，不需要遍历所有缓存项
+func (c *BoltDiskCache) cleanExpired() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	expired := make(map[string]*diskCacheMetadata)
+	var staleIndexKeys [][]byte
+	_ = c.store.view(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(c.expiryBucket).Cursor()
+		for indexKey, _ := cursor.First(); indexKey != nil; indexKey, _ = cursor.Next() {
+			if len(indexKey) < 8 || int64(binary.BigEndian.Uint64(indexKey)) > now.UnixNano() {
+				break
+			}
+			key := string(indexKey[8:])
+			if meta, ok := c.metadata[key]; ok && now.After(meta.Expiry) {
+				expired[key] = meta
+			} else if !ok {
+				staleIndexKeys = append(staleIndexKeys, append([]byte(nil), indexKey...))
+			}
+		}
+		return nil
+	})
+	if len(expired) == 0 && len(staleIndexKeys) == 0 {
+		return
+	}
+
+	err := c.store.batch(func(tx *bolt.Tx) error {
+		for key, meta := range expired {
+			if err := c.deleteEntry(tx, key, meta); err != nil {
+				return err
+			}
+		}
+		for _, indexKey := range staleIndexKeys {
+			if err := tx.Bucket(c.expiryBucket).Delete(indexKey); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("[bbolt缓存] 清理过期项失败: %v\n", err)
+		return
+	}
+
+	for key, meta := range expired {
+		c.currSize -= int64(meta.Size)
+		delete(c.metadata, key)
+	}
+}
+
+// 启动定期清理任务
+func (c *BoltDiskCache) startCleanupTask() {
+	ticker := time.NewTicker(10 * time.Minute)
+	for range ticker.C {
+		c.cleanExpired()
+	}
+}
+
+// Clear 清空缓存
+func (c *BoltDiskCache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	err := c.store.batch(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{c.dataBucket, c.metaBucket, c.expiryBucket} {
+			if tx.Bucket(name) != nil {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+		}
+		return c.createBuckets(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 重置元数据
+	c.metadata = make(map[string]*diskCacheMetadata)
+	c.currSize = 0
+	return nil
+}
+
+// GetLastModified 获取缓存项的最后修改时间
+func (c *BoltDiskCache) GetLastModified(key string) (time.Time, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	meta, exists := c.metadata[key]
+	if !exists {
+		return time.Time{}, false
+	}
+	return meta.LastModified, true
+}
+
+// GetExpiry 获取缓存项的过期时间
+func (c *BoltDiskCache) GetExpiry(key string) (time.Time, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	meta, exists := c.metadata[key]
+	if !exists {
+		return time.Time{}, false
+	}
+	return meta.Expiry, true
+}
+
+// Entries 获取所有未过期缓存项的信息
+func (c *BoltDiskCache) Entries() []CacheEntryInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntryInfo, 0, len(c.metadata))
+	for key, meta := range c.metadata {
+		if now.After(meta.Expiry) {
+			continue
+		}
+		entries = append(entries, CacheEntryInfo{
+			Key:          key,
+			Size:         meta.Size,
+			Expiry:       meta.Expiry,
+			LastModified: meta.LastModified,
+		})
+	}
+	return entries
+}
+
+// Entry 获取单个未过期缓存项的信息
+func (c *BoltDiskCache) Entry(key string) (CacheEntryInfo, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	meta, exists := c.metadata[key]
+	if !exists || time.Now().After(meta.Expiry) {
+		return CacheEntryInfo{}, false
+	}
+	return CacheEntryInfo{
+		Key:          key,
+		Size:         meta.Size,
+		Expiry:       meta.Expiry,
+		LastModified: meta.LastModified,
+	}, true
+}
+
+// Usage 获取缓存项数量和占用空间（字节）
+func (c *BoltDiskCache) Usage() (int, int64) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.metadata), c.currSize
+}
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestBoltShard(t *testing.T, maxSizeMB int) (*boltStore, *BoltDiskCache) {
+	t.Helper()
+	store, err := openBoltStore(filepath.Join(t.TempDir(), boltCacheFile))
+	if err != nil {
+		t.Fatalf("打开缓存文件失败: %v", err)
+	}
+	t.Cleanup(func() { store.db.Close() })
+
+	shard, err := newBoltDiskCache(store, "shard_0", maxSizeMB)
+	if err != nil {
+		t.Fatalf("创建分片失败: %v", err)
+	}
+	return store, shard
+}
+
+func TestBoltDiskCacheSetGetDelete(t *testing.T) {
+	_, shard := newTestBoltShard(t, 10)
+
+	lastModified := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	if err := shard.SetWithTimestamp("a", []byte("hello"), time.Hour, lastModified); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	data, ok, err := shard.Get("a")
+	if err != nil || !ok || string(data) != "hello" {
+		t.Fatalf("Get(a) = %q, %v, %v，期望 hello", data, ok, err)
+	}
+	if got, ok := shard.GetLastModified("a"); !ok || !got.Equal(lastModified) {
+		t.Fatalf("GetLastModified(a) = %v，期望 %v", got, lastModified)
+	}
+	if expiry, ok := shard.GetExpiry("a"); !ok || time.Until(expiry) <= 59*time.Minute {
+		t.Fatalf("GetExpiry(a) = %v，期望约1小时后", expiry)
+	}
+
+	if err := shard.Delete("a"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if shard.Has("a") {
+		t.Fatalf("删除后Has(a) = true")
+	}
+	if items, size := shard.Usage(); items != 0 || size != 0 {
+		t.Fatalf("删除后Usage() = %d, %d，期望 0, 0", items, size)
+	}
+}
+
+func TestBoltDiskCacheExpired(t *testing.T) {
+	_, shard := newTestBoltShard(t, 10)
+
+	if err := shard.Set("old", []byte("x"), -time.Second); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if _, ok, _ := shard.Get("old"); ok {
+		t.Fatalf("已过期的缓存项不应命中")
+	}
+
+	if err := shard.Set("expiring", []byte("x"), -time.Second); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	shard.cleanExpired()
+	if items, _ := shard.Usage(); items != 0 {
+		t.Fatalf("清理后还剩 %d 个缓存项", items)
+	}
+}
+
+func TestBoltDiskCacheReload(t *testing.T) {
+	store, shard := newTestBoltShard(t, 10)
+	if err := shard.Set("a", []byte("hello"), time.Hour); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	store.db.Close()
+
+	reopened, err := openBoltStore(store.path)
+	if err != nil {
+		t.Fatalf("重新打开失败: %v", err)
+	}
+	defer reopened.db.Close()
+	shard, err = newBoltDiskCache(reopened, "shard_0", 10)
+	if err != nil {
+		t.Fatalf("创建分片失败: %v", err)
+	}
+
+	if data, ok, _ := shard.Get("a"); !ok || string(data) != "hello" {
+		t.Fatalf("重新打开后Get(a) = %q, %v，期望 hello", data, ok)
+	}
+}
+
+func TestBoltDiskCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	_, shard := newTestBoltShard(t, 1)
+
+	value := bytes.Repeat([]byte("x"), 400*1024)
+	for _, key := range []string{"a", "b"} {
+		if err := shard.Set(key, value, time.Hour); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	// 访问a，使b成为最久未使用的缓存项
+	shard.Get("a")
+	if err := shard.Set("c", value, time.Hour); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	if shard.Has("b") {
+		t.Fatalf("空间不足时应淘汰最久未使用的b")
+	}
+	if !shard.Has("a") || !shard.Has("c") {
+		t.Fatalf("a和c应保留")
+	}
+	if _, size := shard.Usage(); size > 1024*1024 {
+		t.Fatalf("占用空间 %d 超过上限", size)
+	}
+}
+
+func TestBoltDiskCacheSetMany(t *testing.T) {
+	_, shard := newTestBoltShard(t, 10)
+
+	if err := shard.Set("a", []byte("old"), time.Hour); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	items := []bulkCacheItem{
+		{Key: "a", Data: []byte("new"), TTL: time.Hour},
+		{Key: "b", Data: []byte("bb"), TTL: time.Hour},
+		{Key: "c", Data: []byte("first"), TTL: time.Hour},
+		{Key: "c", Data: []byte("last"), TTL: time.Hour},
+	}
+	if err := shard.setMany(items); err != nil {
+		t.Fatalf("批量写入失败: %v", err)
+	}
+
+	want := map[string]string{"a": "new", "b": "bb", "c": "last"}
+	for key, value := range want {
+		if data, ok, _ := shard.Get(key); !ok || string(data) != value {
+			t.Fatalf("Get(%s) = %q, %v，期望 %q", key, data, ok, value)
+		}
+	}
+	if count, size := shard.Usage(); count != 3 || size != int64(len("new")+len("bb")+len("last")) {
+		t.Fatalf("Usage() = %d, %d，期望 3, %d", count, size, len("new")+len("bb")+len("last"))
+	}
+}
+
+func TestMigrateFileShards(t *testing.T) {
+	dir := t.TempDir()
+
+	fileShard, err := NewDiskCache(filepath.Join(dir, "shard_0"), 10)
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	const count = boltBulkWriteSize + 10
+	for i := 0; i < count; i++ {
+		if err := fileShard.Set(fmt.Sprintf("key-%d", i), []byte(fmt.Sprintf("value-%d", i)), time.Hour); err != nil {
+			t.Fatalf("写入文件缓存失败: %v", err)
+		}
+	}
+	if err := fileShard.Set("expired", []byte("x"), -time.Second); err != nil {
+		t.Fatalf("写入文件缓存失败: %v", err)
+	}
+
+	cache, err := NewOptimizedBoltShardedDiskCache(dir, 100, 0)
+	if err != nil {
+		t.Fatalf("创建bbolt缓存失败: %v", err)
+	}
+	defer cache.store.db.Close()
+
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if data, ok, _ := cache.Get(key); !ok || string(data) != fmt.Sprintf("value-%d", i) {
+			t.Fatalf("迁移后Get(%s) = %q, %v", key, data, ok)
+		}
+	}
+	if cache.Has("expired") {
+		t.Fatalf("已过期的文件缓存不应迁移")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "shard_0")); !os.IsNotExist(err) {
+		t.Fatalf("迁移后应删除文件缓存目录")
+	}
+}
+
+func TestBoltStoreCompact(t *testing.T) {
+	store, shard := newTestBoltShard(t, 100)
+
+	value := bytes.Repeat([]byte("x"), 64*1024)
+	for i := 0; i < 64; i++ {
+		if err := shard.Set(fmt.Sprintf("key-%d", i), value, time.Hour); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	for i := 1; i < 64; i++ {
+		shard.Delete(fmt.Sprintf("key-%d", i))
+	}
+
+	before := store.fileSize()
+	if err := store.compact(); err != nil {
+		t.Fatalf("压缩失败: %v", err)
+	}
+	if after := store.fileSize(); after >= before {
+		t.Fatalf("压缩后文件大小 %d，期望小于 %d", after, before)
+	}
+	if data, ok, _ := shard.Get("key-0"); !ok || !bytes.Equal(data, value) {
+		t.Fatalf("压缩后数据丢失")
+	}
+	if _, err := os.Stat(store.path + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("压缩成功后应删除保留的原文件")
+	}
+}
+
+func TestBoltStoreCompactFailureReopens(t *testing.T) {
+	store, shard := newTestBoltShard(t, 100)
+	if err := shard.Set("a", []byte("hello"), time.Hour); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	// 占用保留原文件的路径，使替换文件失败
+	if err := os.MkdirAll(filepath.Join(store.path+".bak", "busy"), 0o755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := store.compact(); err == nil {
+		t.Fatalf("替换文件失败时compact应返回错误")
+	}
+
+	if data, ok, err := shard.Get("a"); err != nil || !ok || string(data) != "hello" {
+		t.Fatalf("压缩失败后Get(a) = %q, %v, %v，期望继续使用原文件", data, ok, err)
+	}
+	if err := shard.Set("b", []byte("world"), time.Hour); err != nil {
+		t.Fatalf("压缩失败后写入失败: %v", err)
+	}
+	if _, err := os.Stat(store.path + ".compact"); !os.IsNotExist(err) {
+		t.Fatalf("压缩失败后应删除临时文件")
+	}
+}
@@ -0,0 +1,29 @@
+package cache
+
+import "time"
+
+// CacheBackend 两级缓存的二级存储，本地分片磁盘缓存（ShardedDiskCache）和
+// 多实例共享的Redis协议远程缓存（RedisCache）均实现该接口
+type CacheBackend interface {
+	Set(key string, data []byte, ttl time.Duration) error
+	SetWithTimestamp(key string, data []byte, ttl time.Duration, lastModified time.Time) error
+	Get(key string) ([]byte, bool, error)
+	Delete(key string) error
+	Has(key string) bool
+	Clear() error
+	GetLastModified(key string) (time.Time, bool)
+	GetExpiry(key string) (time.Time, bool)
+	Entries() []CacheEntryInfo
+	Entry(key string) (CacheEntryInfo, bool)
+	GetStats() map[string]interface{}
+}
+
+// entryReader 能在一次读取中同时返回数据和元数据的二级缓存，避免远程缓存每次读取多次往返
+type entryReader interface {
+	getEntry(key string) ([]byte, CacheEntryInfo, bool, error)
+}
+
+// batchChecker 能批量检查缓存项是否存在的二级缓存
+type batchChecker interface {
+	hasKeys(keys []string) (map[string]bool, error)
+}
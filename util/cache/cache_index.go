@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pansou/util/json"
+)
+
+// cacheKeyIndexFile 缓存键索引文件名，保存在缓存目录下
+const cacheKeyIndexFile = "cache_keys.json"
+
+// cacheKeyIndexGrace 新登记的缓存键在该时长内不因缓存中不存在而被清理（缓存写入可能仍在进行）
+const cacheKeyIndexGrace = 10 * time.Minute
+
+// CacheKeyInfo 缓存键对应的搜索参数
+// 缓存键是哈希值，无法从中还原关键词，因此在生成缓存键时登记
+type CacheKeyInfo struct {
+	Keyword    string    `json:"keyword"`
+	SourceType string    `json:"source_type"`       // tg 或 plugin
+	Sources    []string  `json:"sources,omitempty"` // 频道或插件列表，为空表示默认全部
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HasSource 判断缓存键的搜索范围是否包含指定频道或插件
+func (info CacheKeyInfo) HasSource(name string) bool {
+	if len(info.Sources) == 0 {
+		return true
+	}
+	for _, source := range info.Sources {
+		if strings.EqualFold(source, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// 全局缓存键索引
+var (
+	cacheKeyIndex      = make(map[string]CacheKeyInfo)
+	cacheKeyIndexMutex sync.RWMutex
+	cacheKeyIndexDirty bool
+)
+
+// registerCacheKey 登记缓存键对应的搜索参数
+func registerCacheKey(key, keyword, sourceType string, sources []string) {
+	cacheKeyIndexMutex.RLock()
+	_, exists := cacheKeyIndex[key]
+	cacheKeyIndexMutex.RUnlock()
+	if exists {
+		return
+	}
+
+	var sortedSources []string
+	if len(sources) > 0 {
+		sortedSources = make([]string, len(sources))
+		copy(sortedSources, sources)
+		sort.Strings(sortedSources)
+	}
+
+	cacheKeyIndexMutex.Lock()
+	if _, exists := cacheKeyIndex[key]; !exists {
+		cacheKeyIndex[key] = CacheKeyInfo{
+			Keyword:    keyword,
+			SourceType: sourceType,
+			Sources:    sortedSources,
+			CreatedAt:  time.Now(),
+		}
+		cacheKeyIndexDirty = true
+	}
+	cacheKeyIndexMutex.Unlock()
+}
+
+// restoreCacheKey 登记从快照等外部来源恢复的缓存键，已登记的缓存键保持不变
+func restoreCacheKey(key string, info CacheKeyInfo) {
+	cacheKeyIndexMutex.Lock()
+	defer cacheKeyIndexMutex.Unlock()
+
+	if _, exists := cacheKeyIndex[key]; !exists {
+		cacheKeyIndex[key] = info
+		cacheKeyIndexDirty = true
+	}
+}
+
+// LookupCacheKey 查询缓存键对应的搜索参数
+func LookupCacheKey(key string) (CacheKeyInfo, bool) {
+	cacheKeyIndexMutex.RLock()
+	defer cacheKeyIndexMutex.RUnlock()
+	info, ok := cacheKeyIndex[key]
+	return info, ok
+}
+
+// FindCacheKeys 查找符合条件的缓存键
+func FindCacheKeys(match func(key string, info CacheKeyInfo) bool) []string {
+	cacheKeyIndexMutex.RLock()
+	defer cacheKeyIndexMutex.RUnlock()
+
+	var keys []string
+	for key, info := range cacheKeyIndex {
+		if match(key, info) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// forgetCacheKeys 删除缓存键索引中不再需要的记录
+func forgetCacheKeys(keep func(key string, info CacheKeyInfo) bool) int {
+	cacheKeyIndexMutex.Lock()
+	defer cacheKeyIndexMutex.Unlock()
+
+	removed := 0
+	for key, info := range cacheKeyIndex {
+		if !keep(key, info) {
+			delete(cacheKeyIndex, key)
+			removed++
+		}
+	}
+	if removed > 0 {
+		cacheKeyIndexDirty = true
+	}
+	return removed
+}
+
+// loadCacheKeyIndex 从缓存目录加载缓存键索引
+func loadCacheKeyIndex(dir string) {
+	data, err := os.ReadFile(filepath.Join(dir, cacheKeyIndexFile))
+	if err != nil {
+		return
+	}
+
+	var entries map[string]CacheKeyInfo
+	if err := json.Unmarshal(data, &entries); err != nil {
+		fmt.Printf("[缓存索引] 加载失败: %v\n", err)
+		return
+	}
+
+	cacheKeyIndexMutex.Lock()
+	for key, info := range entries {
+		if _, exists := cacheKeyIndex[key]; !exists {
+			cacheKeyIndex[key] = info
+		}
+	}
+	cacheKeyIndexMutex.Unlock()
+}
+
+// saveCacheKeyIndex 将缓存键索引保存到缓存目录，索引没有变化时不写入
+func saveCacheKeyIndex(dir string) error {
+	cacheKeyIndexMutex.Lock()
+	if !cacheKeyIndexDirty {
+		cacheKeyIndexMutex.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(cacheKeyIndex)
+	cacheKeyIndexDirty = false
+	cacheKeyIndexMutex.Unlock()
+
+	if err == nil {
+		err = writeCacheKeyIndexFile(dir, data)
+	}
+	if err != nil {
+		// 保存失败时保留脏标记，下次继续尝试
+		cacheKeyIndexMutex.Lock()
+		cacheKeyIndexDirty = true
+		cacheKeyIndexMutex.Unlock()
+	}
+	return err
+}
+
+// writeCacheKeyIndexFile 先写临时文件再替换，避免写入中断导致索引文件损坏
+func writeCacheKeyIndexFile(dir string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmpFile := filepath.Join(dir, cacheKeyIndexFile+".tmp")
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filepath.Join(dir, cacheKeyIndexFile))
+}
@@ -57,7 +57,11 @@ func GenerateTGCacheKey(keyword string, channels []string) string {
 	// 生成TG搜索特定的缓存键
 	keyStr := fmt.Sprintf("tg:%s:%s", normalizedKeyword, channelsHash)
 	hash := md5.Sum([]byte(keyStr))
-	return hex.EncodeToString(hash[:])
+	key := hex.EncodeToString(hash[:])
+	
+	// 登记缓存键对应的关键词，供缓存管理使用
+	registerCacheKey(key, normalizedKeyword, "tg", channels)
+	return key
 }
 
 // GeneratePluginCacheKey 为插件搜索生成缓存键
@@ -71,7 +75,11 @@ func GeneratePluginCacheKey(keyword string, plugins []string) string {
 	// 生成插件搜索特定的缓存键
 	keyStr := fmt.Sprintf("plugin:%s:%s", normalizedKeyword, pluginsHash)
 	hash := md5.Sum([]byte(keyStr))
-	return hex.EncodeToString(hash[:])
+	key := hex.EncodeToString(hash[:])
+	
+	// 登记缓存键对应的关键词，供缓存管理使用
+	registerCacheKey(key, normalizedKeyword, "plugin", plugins)
+	return key
 }
 
 // GenerateCacheKey 根据所有影响搜索结果的参数生成缓存键
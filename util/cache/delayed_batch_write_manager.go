@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"pansou/config"
 	"pansou/model"
 )
 
@@ -37,6 +39,9 @@ type CacheOperation struct {
 	Priority         int                // 优先级 (1=highest, 4=lowest)
 	DataSize         int                // 数据大小（字节）
 	IsFinal          bool               // 是否为最终结果
+	
+	walSeq           uint64             // 预写日志序号，0表示未记录到预写日志
+	walSize          int64              // 在预写日志中的记录长度
 }
 
 // CacheWriteConfig 缓存写入配置
@@ -53,6 +58,11 @@ type CacheWriteConfig struct {
 	HighPriorityRatio       float64            `env:"HIGH_PRIORITY_RATIO" default:"0.3"`
 	EnableCompression       bool               // 默认启用操作合并
 	
+	// 预写日志参数
+	WALEnabled              bool               `env:"CACHE_WAL_ENABLED" default:"true"`
+	WALSync                 string             `env:"CACHE_WAL_SYNC" default:"interval"`          // always/interval/none
+	WALSyncInterval         time.Duration      `env:"CACHE_WAL_SYNC_INTERVAL" default:"1s"`      // interval策略的同步间隔
+	
 	// 内部计算参数（运行时动态调整）
 	idleThresholdCPU        float64            // CPU空闲阈值
 	idleThresholdDisk       float64            // 磁盘空闲阈值
@@ -130,6 +140,21 @@ func (c *CacheWriteConfig) loadFromEnvironment() {
 			c.HighPriorityRatio = r
 		}
 	}
+	
+	// 预写日志参数
+	if enabled := os.Getenv("CACHE_WAL_ENABLED"); enabled != "" {
+		c.WALEnabled = enabled != "false"
+	}
+	
+	if syncMode := os.Getenv("CACHE_WAL_SYNC"); syncMode != "" {
+		c.WALSync = strings.ToLower(syncMode)
+	}
+	
+	if interval := os.Getenv("CACHE_WAL_SYNC_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			c.WALSyncInterval = d
+		}
+	}
 }
 
 // calculateOptimalBatchInterval 计算最优批量间隔
@@ -243,6 +268,14 @@ func (c *CacheWriteConfig) validateAndConstraint() error {
 		c.Strategy = CacheStrategyHybrid
 	}
 	
+	// 设置默认预写日志同步策略
+	if c.WALSync != WALSyncAlways && c.WALSync != WALSyncInterval && c.WALSync != WALSyncNone {
+		c.WALSync = WALSyncInterval
+	}
+	if c.WALSyncInterval <= 0 {
+		c.WALSyncInterval = time.Second
+	}
+	
 	return nil
 }
 
@@ -273,6 +306,14 @@ type DelayedBatchWriteManager struct {
 	// 主缓存更新函数
 	mainCacheUpdater  func(string, []byte, time.Duration) error
 	
+	// 已失效的缓存键：失效之前加入的待写入操作不再写入磁盘
+	discardedKeys     map[string]time.Time
+	discardMutex      sync.Mutex
+	
+	// 预写日志：缓存操作加入缓冲区前先记录，进程异常退出后可恢复
+	wal               *writeAheadLog
+	walRecovered      []*CacheOperation  // 启动时从预写日志恢复、等待重放的操作
+	
 	// 序列化器
 	serializer        *GobSerializer
 	
@@ -313,6 +354,9 @@ func NewDelayedBatchWriteManager() (*DelayedBatchWriteManager, error) {
 	config := &CacheWriteConfig{
 		Strategy:          CacheStrategyHybrid,
 		EnableCompression: true,
+		WALEnabled:        true,
+		WALSync:           WALSyncInterval,
+		WALSyncInterval:   time.Second,
 	}
 	
 	// 初始化配置
@@ -330,6 +374,7 @@ func NewDelayedBatchWriteManager() (*DelayedBatchWriteManager, error) {
 		queueBuffer:         make([]*CacheOperation, 0, config.MaxBatchSize),
 		globalBufferManager: globalBufferManager,
 		operationMap:        make(map[string]*CacheOperation),
+		discardedKeys:       make(map[string]time.Time),
 		shutdownChan:        make(chan struct{}),
 		stats: &WriteManagerStats{
 			WindowStart: time.Now(),
@@ -354,6 +399,21 @@ func (m *DelayedBatchWriteManager) Initialize() error {
 		return fmt.Errorf("全局缓冲区管理器初始化失败: %v", err)
 	}
 	
+	// 打开预写日志，失败时仅关闭预写日志，不影响缓存写入
+	if m.config.WALEnabled {
+		walPath := filepath.Join(walDir(), walFileName)
+		wal, recovered, err := openWriteAheadLog(walPath, m.config.WALSync, m.config.WALSyncInterval)
+		if err != nil {
+			fmt.Printf("[预写日志] 打开失败，预写日志已禁用: %v\n", err)
+		} else {
+			m.wal = wal
+			m.walRecovered = recovered
+			if len(recovered) > 0 {
+				fmt.Printf("[预写日志] 发现 %d 个上次未写入磁盘的缓存操作，等待恢复\n", len(recovered))
+			}
+		}
+	}
+	
 	// 启动后台处理goroutine
 	go m.backgroundProcessor()
 	
@@ -393,6 +453,13 @@ func (m *DelayedBatchWriteManager) HandleCacheOperation(op *CacheOperation) erro
 		return m.immediateWriteToDisk(op)
 	}
 	
+	// 加入缓冲区之前先记录到预写日志，记录失败不影响缓冲写入
+	if m.wal != nil {
+		if err := m.wal.append(op); err != nil {
+			fmt.Printf("[预写日志] %v\n", err)
+		}
+	}
+	
 	// 使用全局缓冲区管理器进行智能缓冲
 	return m.handleWithGlobalBuffer(op)
 }
@@ -525,6 +592,11 @@ func (m *DelayedBatchWriteManager) immediateWriteToDisk(op *CacheOperation) erro
 		return fmt.Errorf("主缓存更新函数未设置")
 	}
 	
+	// 跳过缓存失效前加入的操作
+	if m.isDiscarded(op) {
+		return nil
+	}
+	
 	// 序列化数据
 	data, err := m.serializer.Serialize(op.Data)
 	if err != nil {
@@ -660,6 +732,14 @@ func (m *DelayedBatchWriteManager) Shutdown(timeout time.Duration) error {
 			lastErr = err
 		} 
 		
+		// 第四步：关闭预写日志，未写入磁盘的操作保留在日志中
+		if m.wal != nil {
+			if err := m.wal.close(); err != nil {
+				fmt.Printf("[数据保护] 预写日志关闭失败: %v\n", err)
+				lastErr = err
+			}
+		}
+		
 		done <- lastErr
 	}()
 	
@@ -831,6 +911,11 @@ func (m *DelayedBatchWriteManager) executeBatchWrite(trigger string) error {
 		return fmt.Errorf("批量写入失败: %v", err)
 	}
 	
+	// 被合并掉的旧操作不会单独写入，一并从预写日志中移除
+	if m.wal != nil {
+		m.wal.resolve(m.queueBuffer)
+	}
+	
 	// 清空缓冲区
 	m.queueBuffer = m.queueBuffer[:0]
 	if m.config.EnableCompression {
@@ -868,6 +953,11 @@ func (m *DelayedBatchWriteManager) batchWriteToDisk(operations []*CacheOperation
 	
 	// 批量处理所有操作
 	for _, op := range operations {
+		// 跳过缓存失效前加入的操作
+		if m.isDiscarded(op) {
+			continue
+		}
+		
 		// 序列化数据
 		data, err := m.serializer.Serialize(op.Data)
 		if err != nil {
@@ -880,6 +970,11 @@ func (m *DelayedBatchWriteManager) batchWriteToDisk(operations []*CacheOperation
 		}
 	}
 	
+	// 全部写入成功后从预写日志中移除
+	if m.wal != nil {
+		m.wal.resolve(operations)
+	}
+	
 	return nil
 }
 
@@ -961,6 +1056,9 @@ func (m *DelayedBatchWriteManager) GetStats() map[string]interface{} {
 		"global_buffer": globalBufferStats,
 		"buffer_info":   m.globalBufferManager.GetBufferInfo(),
 	}
+	if m.wal != nil {
+		combinedStats["wal"] = m.wal.stats()
+	}
 	
 	return combinedStats
 }
@@ -977,4 +1075,87 @@ func (m *DelayedBatchWriteManager) GetWriteManagerStats() *WriteManagerStats {
 	}
 	
 	return &stats
-}
\ No newline at end of file
+}
+
+// Discard 丢弃指定缓存键尚未写入磁盘的操作，用于缓存失效后避免旧数据被重新写入
+func (m *DelayedBatchWriteManager) Discard(key string) {
+	now := time.Now()
+	
+	m.discardMutex.Lock()
+	defer m.discardMutex.Unlock()
+	
+	// 清理足够久之前的记录，这些记录对应的待写入操作早已刷新
+	for k, discardedAt := range m.discardedKeys {
+		if now.Sub(discardedAt) > time.Hour {
+			delete(m.discardedKeys, k)
+		}
+	}
+	m.discardedKeys[key] = now
+}
+
+// isDiscarded 判断操作是否在其缓存键失效之前加入
+func (m *DelayedBatchWriteManager) isDiscarded(op *CacheOperation) bool {
+	m.discardMutex.Lock()
+	defer m.discardMutex.Unlock()
+	
+	discardedAt, ok := m.discardedKeys[op.Key]
+	return ok && !op.Timestamp.After(discardedAt)
+}
+
+// ReplayWAL 将预写日志中上次未写入磁盘的缓存操作写入缓存，只在启动时执行一次
+// 已过期、已失效或缓存中已有更新数据的操作会被跳过，写入时保留剩余的TTL和原始时间戳
+func (m *DelayedBatchWriteManager) ReplayWAL(mainCache *EnhancedTwoLevelCache) error {
+	m.initMutex.Lock()
+	recovered := m.walRecovered
+	m.walRecovered = nil
+	m.initMutex.Unlock()
+	
+	if m.wal == nil || len(recovered) == 0 {
+		return nil
+	}
+	
+	resolved := make([]*CacheOperation, 0, len(recovered))
+	replayed, skipped := 0, 0
+	var lastErr error
+	for _, op := range recovered {
+		remaining := op.TTL - time.Since(op.Timestamp)
+		if remaining <= 0 || m.isDiscarded(op) {
+			resolved = append(resolved, op)
+			skipped++
+			continue
+		}
+		if entry, ok := mainCache.Entry(op.Key); ok && !entry.LastModified.Before(op.Timestamp) {
+			resolved = append(resolved, op)
+			skipped++
+			continue
+		}
+		
+		data, err := m.serializer.Serialize(op.Data)
+		if err == nil {
+			err = mainCache.SetBothLevelsWithTimestamp(op.Key, data, remaining, op.Timestamp)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resolved = append(resolved, op)
+		replayed++
+	}
+	
+	m.wal.resolve(resolved)
+	fmt.Printf("[预写日志] 恢复完成: 写入 %d 个，跳过 %d 个，失败 %d 个\n",
+		replayed, skipped, len(recovered)-len(resolved))
+	
+	if lastErr != nil {
+		return fmt.Errorf("预写日志恢复失败: %v", lastErr)
+	}
+	return nil
+}
+
+// walDir 预写日志所在目录，与缓存目录相同
+func walDir() string {
+	if config.AppConfig != nil && config.AppConfig.CachePath != "" {
+		return config.AppConfig.CachePath
+	}
+	return "./cache"
+}
@@ -112,6 +112,11 @@ func (c *DiskCache) getFilename(key string) string {
 
 // Set 设置缓存
 func (c *DiskCache) Set(key string, data []byte, ttl time.Duration) error {
+	return c.SetWithTimestamp(key, data, ttl, time.Now())
+}
+
+// SetWithTimestamp 设置缓存，并指定最后修改时间
+func (c *DiskCache) SetWithTimestamp(key string, data []byte, ttl time.Duration, lastModified time.Time) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -151,7 +156,7 @@ func (c *DiskCache) Set(key string, data []byte, ttl time.Duration) error {
 		Key:         key,
 		Expiry:      now.Add(ttl),
 		LastUsed:    now,
-		LastModified: now, // 设置最后修改时间
+		LastModified: lastModified, // 设置最后修改时间
 		Size:        len(data),
 	}
 
@@ -360,4 +365,70 @@ func (c *DiskCache) GetLastModified(key string) (time.Time, bool) {
 	}
 
 	return meta.LastModified, true
-} 
\ No newline at end of file
+}
+
+// GetExpiry 获取缓存项的过期时间
+func (c *DiskCache) GetExpiry(key string) (time.Time, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	meta, exists := c.metadata[key]
+	if !exists {
+		return time.Time{}, false
+	}
+
+	return meta.Expiry, true
+}
+
+// CacheEntryInfo 缓存项信息（用于缓存管理）
+type CacheEntryInfo struct {
+	Key          string
+	Size         int
+	Expiry       time.Time
+	LastModified time.Time
+}
+
+// Entries 获取所有未过期缓存项的信息
+func (c *DiskCache) Entries() []CacheEntryInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntryInfo, 0, len(c.metadata))
+	for key, meta := range c.metadata {
+		if now.After(meta.Expiry) {
+			continue
+		}
+		entries = append(entries, CacheEntryInfo{
+			Key:          key,
+			Size:         meta.Size,
+			Expiry:       meta.Expiry,
+			LastModified: meta.LastModified,
+		})
+	}
+	return entries
+}
+
+// Entry 获取单个未过期缓存项的信息
+func (c *DiskCache) Entry(key string) (CacheEntryInfo, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	meta, exists := c.metadata[key]
+	if !exists || time.Now().After(meta.Expiry) {
+		return CacheEntryInfo{}, false
+	}
+	return CacheEntryInfo{
+		Key:          key,
+		Size:         meta.Size,
+		Expiry:       meta.Expiry,
+		LastModified: meta.LastModified,
+	}, true
+}
+
+// Usage 获取缓存项数量和占用空间（字节）
+func (c *DiskCache) Usage() (int, int64) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.metadata), c.currSize
+}
@@ -11,7 +11,7 @@ import (
 // EnhancedTwoLevelCache 改进的两级缓存
 type EnhancedTwoLevelCache struct {
 	memory     *ShardedMemoryCache
-	disk       *ShardedDiskCache
+	disk       CacheBackend // 二级缓存：本地分片磁盘缓存或多实例共享的远程缓存
 	mutex      sync.RWMutex
 	serializer Serializer
 }
@@ -25,23 +25,60 @@ func NewEnhancedTwoLevelCache() (*EnhancedTwoLevelCache, error) {
 	memCache := NewShardedMemoryCache(memCacheMaxItems, memCacheSizeMB)
 	memCache.StartCleanupTask()
 
-	// 创建优化的分片磁盘缓存，使用动态分片数量
-	diskCache, err := NewOptimizedShardedDiskCache(config.AppConfig.CachePath, config.AppConfig.CacheMaxSizeMB)
+	// 按配置选择二级缓存：Redis协议远程缓存，或使用动态分片数量的本地磁盘缓存
+	var diskCache CacheBackend
+	var err error
+	switch config.AppConfig.CacheBackend {
+	case RemoteBackendRedis:
+		diskCache, err = NewRedisCache(RedisCacheOptions{
+			Addr:     config.AppConfig.CacheRedisAddr,
+			Password: config.AppConfig.CacheRedisPassword,
+			DB:       config.AppConfig.CacheRedisDB,
+			Prefix:   config.AppConfig.CacheRedisPrefix,
+		})
+	case DiskBackendBolt:
+		diskCache, err = NewOptimizedBoltShardedDiskCache(config.AppConfig.CachePath, config.AppConfig.CacheMaxSizeMB, config.AppConfig.CacheCompactInterval)
+	default:
+		diskCache, err = NewOptimizedShardedDiskCache(config.AppConfig.CachePath, config.AppConfig.CacheMaxSizeMB)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// 创建序列化器
-	serializer := NewGobSerializer()
+	c := NewEnhancedTwoLevelCacheWithBackend(memCache, diskCache)
+
+	// 加载缓存键索引并定期保存，使重启后仍能按关键词管理已有缓存
+	loadCacheKeyIndex(config.AppConfig.CachePath)
+	go c.maintainKeyIndex()
 
-	// 设置内存缓存的磁盘缓存引用，用于LRU淘汰时的备份
-	memCache.SetDiskCacheReference(diskCache)
+	return c, nil
+}
 
-	return &EnhancedTwoLevelCache{
+// NewEnhancedTwoLevelCacheWithBackend 使用指定的内存缓存和二级缓存创建两级缓存
+// 二级缓存为远程共享缓存时，其他实例写入或删除缓存后丢弃本地内存缓存中的对应数据
+func NewEnhancedTwoLevelCacheWithBackend(memCache *ShardedMemoryCache, backend CacheBackend) *EnhancedTwoLevelCache {
+	// 设置内存缓存的二级缓存引用，用于LRU淘汰时的备份
+	memCache.SetDiskCacheReference(backend)
+
+	c := &EnhancedTwoLevelCache{
 		memory:     memCache,
-		disk:       diskCache,
-		serializer: serializer,
-	}, nil
+		disk:       backend,
+		serializer: NewGobSerializer(),
+	}
+
+	if remote, ok := backend.(*RedisCache); ok {
+		remote.SetInvalidationHandler(c.invalidateMemory)
+	}
+	return c
+}
+
+// invalidateMemory 丢弃内存缓存中的数据，下次读取时从二级缓存获取
+func (c *EnhancedTwoLevelCache) invalidateMemory(key string) {
+	if key == redisInvalidateAll {
+		c.memory.Clear()
+		return
+	}
+	c.memory.Delete(key)
 }
 
 // Set 设置缓存
@@ -82,6 +119,13 @@ func (c *EnhancedTwoLevelCache) SetBothLevels(key string, data []byte, ttl time.
 	return c.disk.Set(key, data, ttl)
 }
 
+// SetBothLevelsWithTimestamp 更新内存和磁盘缓存，并保留指定的最后修改时间
+// 用于只修正数据、不代表重新获取数据的写入（如移除失效链接），避免缓存显得比实际更新
+func (c *EnhancedTwoLevelCache) SetBothLevelsWithTimestamp(key string, data []byte, ttl time.Duration, lastModified time.Time) error {
+	c.memory.SetWithTimestamp(key, data, ttl, lastModified)
+	return c.disk.SetWithTimestamp(key, data, ttl, lastModified)
+}
+
 // SetWithFinalFlag 根据结果状态选择更新策略
 func (c *EnhancedTwoLevelCache) SetWithFinalFlag(key string, data []byte, ttl time.Duration, isFinal bool) error {
 	if isFinal {
@@ -93,24 +137,56 @@ func (c *EnhancedTwoLevelCache) SetWithFinalFlag(key string, data []byte, ttl ti
 
 // Get 获取缓存
 func (c *EnhancedTwoLevelCache) Get(key string) ([]byte, bool, error) {
-	
+	data, _, hit, err := c.GetWithTimestamp(key)
+	return data, hit, err
+}
+
+// GetWithTimestamp 获取缓存及其最后修改时间
+func (c *EnhancedTwoLevelCache) GetWithTimestamp(key string) ([]byte, time.Time, bool, error) {
 	// 检查内存缓存
-	data, _, memHit := c.memory.GetWithTimestamp(key)
+	data, lastModified, memHit := c.memory.GetWithTimestamp(key)
 	if memHit {
-		return data, true, nil
+		return data, lastModified, true, nil
 	}
 
-    // 尝试从磁盘读取数据
-	diskData, diskHit, diskErr := c.disk.Get(key)
+	// 尝试从磁盘读取数据
+	diskData, info, diskHit, diskErr := c.readDisk(key)
 	if diskErr == nil && diskHit {
 		// 磁盘缓存命中，更新内存缓存
-		diskLastModified, _ := c.disk.GetLastModified(key)
+		// 沿用磁盘缓存的剩余有效期，按来源配置的有效期可能短于CACHE_TTL
 		ttl := time.Duration(config.AppConfig.CacheTTLMinutes) * time.Minute
-		c.memory.SetWithTimestamp(key, diskData, ttl, diskLastModified)
-		return diskData, true, nil
+		if time.Until(info.Expiry) > 0 {
+			ttl = time.Until(info.Expiry)
+		}
+		c.memory.SetWithTimestamp(key, diskData, ttl, info.LastModified)
+		return diskData, info.LastModified, true, nil
 	}
-	
-	return nil, false, nil
+
+	return nil, time.Time{}, false, nil
+}
+
+// readDisk 从二级缓存读取数据及其最后修改时间和过期时间，远程缓存只需一次往返
+func (c *EnhancedTwoLevelCache) readDisk(key string) ([]byte, CacheEntryInfo, bool, error) {
+	if reader, ok := c.disk.(entryReader); ok {
+		return reader.getEntry(key)
+	}
+
+	data, hit, err := c.disk.Get(key)
+	if err != nil || !hit {
+		return nil, CacheEntryInfo{}, false, err
+	}
+	info := CacheEntryInfo{Key: key, Size: len(data)}
+	info.LastModified, _ = c.disk.GetLastModified(key)
+	info.Expiry, _ = c.disk.GetExpiry(key)
+	return data, info, true, nil
+}
+
+// GetExpiry 获取缓存项的过期时间，优先使用内存缓存中的记录
+func (c *EnhancedTwoLevelCache) GetExpiry(key string) (time.Time, bool) {
+	if expiry, ok := c.memory.GetExpiry(key); ok {
+		return expiry, true
+	}
+	return c.disk.GetExpiry(key)
 }
 
 // Delete 删除缓存
@@ -154,12 +230,143 @@ func (c *EnhancedTwoLevelCache) FlushMemoryToDisk() error {
 	
 	for key, item := range allItems {
 		// 同步写入到磁盘缓存
-		if err := c.disk.Set(key, item.Data, item.TTL); err != nil {
+		if err := c.disk.SetWithTimestamp(key, item.Data, item.TTL, item.LastModified); err != nil {
 			fmt.Printf("[内存同步] 同步失败: %s -> %v\n", key, err)
 			lastErr = err
 			continue
 		}
 	}
 	
+	// 同时保存缓存键索引
+	if err := saveCacheKeyIndex(config.AppConfig.CachePath); err != nil {
+		fmt.Printf("[缓存索引] 保存失败: %v\n", err)
+		lastErr = err
+	}
+	
 	return lastErr
+}
+
+// Peek 读取缓存数据，不更新内存缓存，返回数据所在层级
+func (c *EnhancedTwoLevelCache) Peek(key string) ([]byte, string, bool) {
+	if data, ok := c.memory.Get(key); ok {
+		return data, "memory", true
+	}
+	if data, hit, err := c.disk.Get(key); err == nil && hit {
+		return data, "disk", true
+	}
+	return nil, "", false
+}
+
+// Has 检查缓存项是否存在于任一层级
+func (c *EnhancedTwoLevelCache) Has(key string) bool {
+	if _, ok := c.memory.GetExpiry(key); ok {
+		return true
+	}
+	return c.disk.Has(key)
+}
+
+// TwoLevelCacheEntry 两级缓存中的缓存项信息
+type TwoLevelCacheEntry struct {
+	CacheEntryInfo
+	InMemory bool
+	OnDisk   bool
+}
+
+// Entries 获取两级缓存中所有未过期缓存项的信息，同一缓存项只返回一次
+func (c *EnhancedTwoLevelCache) Entries() []TwoLevelCacheEntry {
+	entries := make(map[string]*TwoLevelCacheEntry)
+	for _, info := range c.memory.Entries() {
+		entries[info.Key] = &TwoLevelCacheEntry{CacheEntryInfo: info, InMemory: true}
+	}
+	for _, info := range c.disk.Entries() {
+		if entry, ok := entries[info.Key]; ok {
+			entry.OnDisk = true
+			continue
+		}
+		entries[info.Key] = &TwoLevelCacheEntry{CacheEntryInfo: info, OnDisk: true}
+	}
+
+	result := make([]TwoLevelCacheEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *entry)
+	}
+	return result
+}
+
+// Entry 获取单个缓存项的信息
+func (c *EnhancedTwoLevelCache) Entry(key string) (TwoLevelCacheEntry, bool) {
+	memInfo, inMemory := c.memory.Entry(key)
+	diskInfo, onDisk := c.disk.Entry(key)
+	switch {
+	case inMemory:
+		return TwoLevelCacheEntry{CacheEntryInfo: memInfo, InMemory: true, OnDisk: onDisk}, true
+	case onDisk:
+		return TwoLevelCacheEntry{CacheEntryInfo: diskInfo, OnDisk: true}, true
+	}
+	return TwoLevelCacheEntry{}, false
+}
+
+// GetStats 获取两级缓存的统计信息
+func (c *EnhancedTwoLevelCache) GetStats() map[string]interface{} {
+	cacheKeyIndexMutex.RLock()
+	indexedKeys := len(cacheKeyIndex)
+	cacheKeyIndexMutex.RUnlock()
+
+	return map[string]interface{}{
+		"memory":       c.memory.GetStats(),
+		"disk":         c.disk.GetStats(),
+		"indexed_keys": indexedKeys,
+	}
+}
+
+// missingKeys 返回keys中两级缓存都不存在的键，二级缓存支持时批量检查，无法确认时不返回
+func (c *EnhancedTwoLevelCache) missingKeys(keys []string) map[string]bool {
+	var check []string
+	for _, key := range keys {
+		if _, ok := c.memory.GetExpiry(key); !ok {
+			check = append(check, key)
+		}
+	}
+
+	missing := make(map[string]bool)
+	if checker, ok := c.disk.(batchChecker); ok {
+		exists, err := checker.hasKeys(check)
+		if err != nil {
+			fmt.Printf("[缓存索引] 检查缓存键失败: %v\n", err)
+			return missing
+		}
+		for _, key := range check {
+			if !exists[key] {
+				missing[key] = true
+			}
+		}
+		return missing
+	}
+
+	for _, key := range check {
+		if !c.disk.Has(key) {
+			missing[key] = true
+		}
+	}
+	return missing
+}
+
+// maintainKeyIndex 定期清理已不在缓存中的缓存键索引记录并保存索引
+func (c *EnhancedTwoLevelCache) maintainKeyIndex() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		candidates := FindCacheKeys(func(key string, info CacheKeyInfo) bool {
+			return time.Since(info.CreatedAt) >= cacheKeyIndexGrace
+		})
+		if missing := c.missingKeys(candidates); len(missing) > 0 {
+			forgetCacheKeys(func(key string, info CacheKeyInfo) bool {
+				return !missing[key]
+			})
+		}
+		if err := saveCacheKeyIndex(config.AppConfig.CachePath); err != nil {
+			fmt.Printf("[缓存索引] 保存失败: %v\n", err)
+		}
+	}
 } 
\ No newline at end of file
@@ -0,0 +1,462 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RemoteBackendRedis 使用Redis协议服务作为多个实例共享的二级缓存
+const RemoteBackendRedis = "redis"
+
+const (
+	// redisHeaderSize 缓存值头部长度：8字节最后修改时间 + 8字节过期时间（UnixNano）
+	redisHeaderSize = 16
+
+	// redisTimeout 连接和单次命令的超时时间
+	redisTimeout = 3 * time.Second
+
+	// redisPoolSize 保留的空闲连接数
+	redisPoolSize = 16
+
+	// redisScanCount SCAN命令每次返回的建议数量
+	redisScanCount = 500
+
+	// redisResubscribeDelay 失效通知订阅断开后重新订阅的间隔
+	redisResubscribeDelay = time.Second
+
+	// redisInvalidateAll 清空全部缓存的失效通知
+	redisInvalidateAll = "*"
+)
+
+// RedisCacheOptions Redis协议远程缓存配置
+type RedisCacheOptions struct {
+	Addr     string // 服务地址，host:port
+	Password string
+	DB       int
+	Prefix   string // 缓存键前缀，失效通知频道为前缀加invalidate
+}
+
+// RedisCache 基于Redis协议的远程共享缓存，作为多个实例共用的二级缓存
+// 每个缓存值前16字节保存最后修改时间和过期时间，过期由服务端按PX删除；
+// 写入和删除后通过发布订阅通知其他实例丢弃各自内存缓存中的旧数据
+type RedisCache struct {
+	client     *respClient
+	addr       string
+	prefix     string
+	channel    string
+	instanceID string
+
+	handlerMutex sync.RWMutex
+	onInvalidate func(key string)
+
+	subMutex   sync.Mutex
+	subConn    *respConn
+	closed     int32
+	stopChan   chan struct{}
+	subscribed int32
+
+	invalidationsSent     int64
+	invalidationsReceived int64
+	resubscribes          int64
+	errors                int64
+}
+
+// NewRedisCache 创建Redis协议远程缓存，连接失败时返回错误
+func NewRedisCache(opts RedisCacheOptions) (*RedisCache, error) {
+	c := &RedisCache{
+		client:     newRespClient(opts.Addr, opts.Password, opts.DB, redisTimeout, redisPoolSize),
+		addr:       opts.Addr,
+		prefix:     opts.Prefix,
+		channel:    opts.Prefix + "invalidate",
+		instanceID: newInstanceID(),
+		stopChan:   make(chan struct{}),
+	}
+
+	if _, err := c.client.do("PING"); err != nil {
+		c.client.close()
+		return nil, fmt.Errorf("连接Redis缓存失败: %v", err)
+	}
+
+	go c.subscribeLoop()
+	return c, nil
+}
+
+// newInstanceID 生成实例标识，用于忽略自己发出的失效通知
+func newInstanceID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// SetInvalidationHandler 设置收到其他实例失效通知时的回调，key为redisInvalidateAll表示全部缓存
+func (c *RedisCache) SetInvalidationHandler(handler func(key string)) {
+	c.handlerMutex.Lock()
+	defer c.handlerMutex.Unlock()
+	c.onInvalidate = handler
+}
+
+// Set 设置缓存
+func (c *RedisCache) Set(key string, data []byte, ttl time.Duration) error {
+	return c.SetWithTimestamp(key, data, ttl, time.Now())
+}
+
+// SetWithTimestamp 设置缓存并指定最后修改时间
+func (c *RedisCache) SetWithTimestamp(key string, data []byte, ttl time.Duration, lastModified time.Time) error {
+	if ttl < time.Millisecond {
+		ttl = time.Millisecond
+	}
+	expiry := time.Now().Add(ttl)
+
+	value := make([]byte, redisHeaderSize+len(data))
+	binary.BigEndian.PutUint64(value[:8], uint64(lastModified.UnixNano()))
+	binary.BigEndian.PutUint64(value[8:redisHeaderSize], uint64(expiry.UnixNano()))
+	copy(value[redisHeaderSize:], data)
+
+	if _, err := c.client.do("SET", c.prefix+key, value, "PX", fmt.Sprintf("%d", ttl.Milliseconds())); err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		return fmt.Errorf("写入Redis缓存失败: %v", err)
+	}
+	c.publishInvalidation(key)
+	return nil
+}
+
+// Get 获取缓存
+func (c *RedisCache) Get(key string) ([]byte, bool, error) {
+	data, _, ok, err := c.getEntry(key)
+	return data, ok, err
+}
+
+// getEntry 获取缓存及其最后修改时间和过期时间，元数据来自值的头部，只需一次往返
+func (c *RedisCache) getEntry(key string) ([]byte, CacheEntryInfo, bool, error) {
+	reply, err := c.client.do("GET", c.prefix+key)
+	if err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		return nil, CacheEntryInfo{}, false, fmt.Errorf("读取Redis缓存失败: %v", err)
+	}
+	value, _ := reply.([]byte)
+	if len(value) < redisHeaderSize {
+		return nil, CacheEntryInfo{}, false, nil
+	}
+	info := CacheEntryInfo{
+		Key:          key,
+		Size:         len(value) - redisHeaderSize,
+		LastModified: time.Unix(0, int64(binary.BigEndian.Uint64(value[:8]))),
+		Expiry:       time.Unix(0, int64(binary.BigEndian.Uint64(value[8:redisHeaderSize]))),
+	}
+	return value[redisHeaderSize:], info, true, nil
+}
+
+// Delete 删除缓存
+func (c *RedisCache) Delete(key string) error {
+	if _, err := c.client.do("DEL", c.prefix+key); err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		return fmt.Errorf("删除Redis缓存失败: %v", err)
+	}
+	c.publishInvalidation(key)
+	return nil
+}
+
+// Has 检查缓存项是否存在
+func (c *RedisCache) Has(key string) bool {
+	reply, err := c.client.do("EXISTS", c.prefix+key)
+	if err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		return false
+	}
+	count, _ := reply.(int64)
+	return count > 0
+}
+
+// hasKeys 批量检查缓存项是否存在，每批命令通过一次管道发送
+func (c *RedisCache) hasKeys(keys []string) (map[string]bool, error) {
+	exists := make(map[string]bool, len(keys))
+	for start := 0; start < len(keys); start += redisScanCount {
+		end := start + redisScanCount
+		if end > len(keys) {
+			end = len(keys)
+		}
+		cmds := make([][]interface{}, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, []interface{}{"EXISTS", c.prefix + key})
+		}
+		replies, err := c.client.pipeline(cmds)
+		if err == nil {
+			err = firstRespError(replies)
+		}
+		if err != nil {
+			atomic.AddInt64(&c.errors, 1)
+			return nil, fmt.Errorf("检查Redis缓存失败: %v", err)
+		}
+		for i, key := range keys[start:end] {
+			count, _ := replies[i].(int64)
+			exists[key] = count > 0
+		}
+	}
+	return exists, nil
+}
+
+// Clear 删除所有带前缀的缓存项
+func (c *RedisCache) Clear() error {
+	keys, err := c.scanKeys()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(keys); start += redisScanCount {
+		end := start + redisScanCount
+		if end > len(keys) {
+			end = len(keys)
+		}
+		args := []interface{}{"DEL"}
+		for _, key := range keys[start:end] {
+			args = append(args, c.prefix+key)
+		}
+		if _, err := c.client.do(args...); err != nil {
+			atomic.AddInt64(&c.errors, 1)
+			return fmt.Errorf("清空Redis缓存失败: %v", err)
+		}
+	}
+	c.publishInvalidation(redisInvalidateAll)
+	return nil
+}
+
+// GetLastModified 获取缓存项的最后修改时间
+func (c *RedisCache) GetLastModified(key string) (time.Time, bool) {
+	info, ok := c.Entry(key)
+	return info.LastModified, ok
+}
+
+// GetExpiry 获取缓存项的过期时间
+func (c *RedisCache) GetExpiry(key string) (time.Time, bool) {
+	info, ok := c.Entry(key)
+	return info.Expiry, ok
+}
+
+// Entry 获取单个缓存项的信息，只读取值的头部
+func (c *RedisCache) Entry(key string) (CacheEntryInfo, bool) {
+	infos, err := c.entries([]string{key})
+	if err != nil || len(infos) == 0 {
+		return CacheEntryInfo{}, false
+	}
+	return infos[0], true
+}
+
+// Entries 获取所有未过期缓存项的信息
+func (c *RedisCache) Entries() []CacheEntryInfo {
+	keys, err := c.scanKeys()
+	if err != nil {
+		return nil
+	}
+
+	var result []CacheEntryInfo
+	for start := 0; start < len(keys); start += redisScanCount {
+		end := start + redisScanCount
+		if end > len(keys) {
+			end = len(keys)
+		}
+		infos, err := c.entries(keys[start:end])
+		if err != nil {
+			break
+		}
+		result = append(result, infos...)
+	}
+	return result
+}
+
+// entries 批量读取缓存项头部和长度，已不存在的缓存项被跳过
+func (c *RedisCache) entries(keys []string) ([]CacheEntryInfo, error) {
+	cmds := make([][]interface{}, 0, len(keys)*2)
+	for _, key := range keys {
+		cmds = append(cmds,
+			[]interface{}{"GETRANGE", c.prefix + key, "0", fmt.Sprintf("%d", redisHeaderSize-1)},
+			[]interface{}{"STRLEN", c.prefix + key})
+	}
+	replies, err := c.client.pipeline(cmds)
+	if err == nil {
+		err = firstRespError(replies)
+	}
+	if err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		return nil, fmt.Errorf("读取Redis缓存信息失败: %v", err)
+	}
+
+	infos := make([]CacheEntryInfo, 0, len(keys))
+	for i, key := range keys {
+		header, _ := replies[i*2].([]byte)
+		length, _ := replies[i*2+1].(int64)
+		if len(header) < redisHeaderSize {
+			continue
+		}
+		infos = append(infos, CacheEntryInfo{
+			Key:          key,
+			Size:         int(length) - redisHeaderSize,
+			LastModified: time.Unix(0, int64(binary.BigEndian.Uint64(header[:8]))),
+			Expiry:       time.Unix(0, int64(binary.BigEndian.Uint64(header[8:redisHeaderSize]))),
+		})
+	}
+	return infos, nil
+}
+
+// scanKeys 列出所有带前缀的缓存键（不含前缀）
+func (c *RedisCache) scanKeys() ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := c.client.do("SCAN", cursor, "MATCH", c.prefix+"*", "COUNT", fmt.Sprintf("%d", redisScanCount))
+		if err != nil {
+			atomic.AddInt64(&c.errors, 1)
+			return nil, fmt.Errorf("遍历Redis缓存失败: %v", err)
+		}
+		items, _ := reply.([]interface{})
+		if len(items) != 2 {
+			return nil, fmt.Errorf("遍历Redis缓存失败: 无效的SCAN回复")
+		}
+		next, _ := items[0].([]byte)
+		batch, _ := items[1].([]interface{})
+		for _, item := range batch {
+			if key, ok := item.([]byte); ok {
+				keys = append(keys, strings.TrimPrefix(string(key), c.prefix))
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return keys, nil
+		}
+	}
+}
+
+// GetStats 获取远程缓存的统计信息
+func (c *RedisCache) GetStats() map[string]interface{} {
+	stats := map[string]interface{}{
+		"backend":                RemoteBackendRedis,
+		"addr":                   c.addr,
+		"prefix":                 c.prefix,
+		"instance_id":            c.instanceID,
+		"subscribed":             atomic.LoadInt32(&c.subscribed) == 1,
+		"invalidations_sent":     atomic.LoadInt64(&c.invalidationsSent),
+		"invalidations_received": atomic.LoadInt64(&c.invalidationsReceived),
+		"resubscribes":           atomic.LoadInt64(&c.resubscribes),
+		"errors":                 atomic.LoadInt64(&c.errors),
+	}
+	if keys, err := c.scanKeys(); err == nil {
+		stats["items"] = len(keys)
+	}
+	return stats
+}
+
+// Close 停止订阅并关闭连接
+func (c *RedisCache) Close() {
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return
+	}
+	close(c.stopChan)
+
+	c.subMutex.Lock()
+	if c.subConn != nil {
+		c.subConn.conn.Close()
+	}
+	c.subMutex.Unlock()
+
+	c.client.close()
+}
+
+// publishInvalidation 通知其他实例丢弃内存缓存中的旧数据，消息格式为"实例标识 缓存键"
+func (c *RedisCache) publishInvalidation(key string) {
+	if _, err := c.client.do("PUBLISH", c.channel, c.instanceID+" "+key); err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		fmt.Printf("[Redis缓存] 发送失效通知失败: %v\n", err)
+		return
+	}
+	atomic.AddInt64(&c.invalidationsSent, 1)
+}
+
+// subscribeLoop 订阅失效通知，连接断开后重新订阅
+// 断开期间可能错过通知，重新订阅后清空本地内存缓存
+func (c *RedisCache) subscribeLoop() {
+	first := true
+	for atomic.LoadInt32(&c.closed) == 0 {
+		err := c.subscribe(func() {
+			if !first {
+				atomic.AddInt64(&c.resubscribes, 1)
+				c.invalidateLocal(redisInvalidateAll)
+			}
+			first = false
+		})
+		atomic.StoreInt32(&c.subscribed, 0)
+		if atomic.LoadInt32(&c.closed) == 1 {
+			return
+		}
+		if err != nil {
+			atomic.AddInt64(&c.errors, 1)
+			fmt.Printf("[Redis缓存] 失效通知订阅中断: %v\n", err)
+		}
+
+		select {
+		case <-time.After(redisResubscribeDelay):
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// subscribe 建立订阅连接并处理失效通知，直到连接出错
+func (c *RedisCache) subscribe(onSubscribed func()) error {
+	cn, err := c.client.dial()
+	if err != nil {
+		return err
+	}
+	defer cn.conn.Close()
+
+	c.subMutex.Lock()
+	if atomic.LoadInt32(&c.closed) == 1 {
+		c.subMutex.Unlock()
+		return nil
+	}
+	c.subConn = cn
+	c.subMutex.Unlock()
+
+	if _, err := cn.pipeline(redisTimeout, [][]interface{}{{"SUBSCRIBE", c.channel}}); err != nil {
+		return err
+	}
+	cn.conn.SetDeadline(time.Time{})
+	atomic.StoreInt32(&c.subscribed, 1)
+	onSubscribed()
+
+	for {
+		reply, err := cn.readReply()
+		if err != nil {
+			return err
+		}
+		items, _ := reply.([]interface{})
+		if len(items) != 3 {
+			continue
+		}
+		if kind, _ := items[0].([]byte); string(kind) != "message" {
+			continue
+		}
+		payload, _ := items[2].([]byte)
+		sender, key, ok := strings.Cut(string(payload), " ")
+		if !ok || sender == c.instanceID {
+			continue
+		}
+		atomic.AddInt64(&c.invalidationsReceived, 1)
+		c.invalidateLocal(key)
+	}
+}
+
+// invalidateLocal 调用失效回调
+func (c *RedisCache) invalidateLocal(key string) {
+	c.handlerMutex.RLock()
+	handler := c.onInvalidate
+	c.handlerMutex.RUnlock()
+	if handler != nil {
+		handler(key)
+	}
+}
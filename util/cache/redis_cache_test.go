@@ -0,0 +1,428 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pansou/config"
+)
+
+// fakeRESPServer 进程内的Redis协议服务，只实现远程缓存用到的命令
+type fakeRESPServer struct {
+	listener net.Listener
+
+	mutex       sync.Mutex
+	values      map[string][]byte
+	expiries    map[string]time.Time
+	subscribers map[string][]*fakeRESPConn
+	conns       []net.Conn
+	commands    map[string]int // 各命令的执行次数
+}
+
+// fakeRESPConn 服务端连接，发布消息和回复命令可能并发写入
+type fakeRESPConn struct {
+	conn   net.Conn
+	mutex  sync.Mutex
+	writer *bufio.Writer
+}
+
+func newFakeRESPServer(t *testing.T) *fakeRESPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := &fakeRESPServer{
+		listener:    listener,
+		values:      make(map[string][]byte),
+		expiries:    make(map[string]time.Time),
+		subscribers: make(map[string][]*fakeRESPConn),
+		commands:    make(map[string]int),
+	}
+	go s.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		s.mutex.Lock()
+		for _, conn := range s.conns {
+			conn.Close()
+		}
+		s.mutex.Unlock()
+	})
+	return s
+}
+
+func (s *fakeRESPServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+// commandCount 获取命令的执行次数
+func (s *fakeRESPServer) commandCount(cmd string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.commands[cmd]
+}
+
+// dropSubscribers 断开所有订阅连接，模拟网络中断
+func (s *fakeRESPServer) dropSubscribers() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for channel, subs := range s.subscribers {
+		for _, sub := range subs {
+			sub.conn.Close()
+		}
+		delete(s.subscribers, channel)
+	}
+}
+
+func (s *fakeRESPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.conns = append(s.conns, conn)
+		s.mutex.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRESPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := &respConn{conn: conn, reader: bufio.NewReader(conn)}
+	client := &fakeRESPConn{conn: conn, writer: bufio.NewWriter(conn)}
+
+	for {
+		request, err := reader.readReply()
+		if err != nil {
+			return
+		}
+		items, _ := request.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			data, _ := item.([]byte)
+			args[i] = string(data)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		reply := s.execute(client, strings.ToUpper(args[0]), args[1:])
+		client.mutex.Lock()
+		writeFakeReply(client.writer, reply)
+		client.writer.Flush()
+		client.mutex.Unlock()
+	}
+}
+
+// execute 执行命令，返回值按类型编码为回复
+func (s *fakeRESPServer) execute(client *fakeRESPConn, cmd string, args []string) interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.commands[cmd]++
+
+	// 惰性删除过期的键
+	for key, expiry := range s.expiries {
+		if time.Now().After(expiry) {
+			delete(s.values, key)
+			delete(s.expiries, key)
+		}
+	}
+
+	switch cmd {
+	case "PING":
+		return "PONG"
+	case "AUTH", "SELECT":
+		return "OK"
+	case "SET":
+		s.values[args[0]] = []byte(args[1])
+		delete(s.expiries, args[0])
+		if len(args) == 4 && strings.ToUpper(args[2]) == "PX" {
+			ms, _ := strconv.Atoi(args[3])
+			s.expiries[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "OK"
+	case "GET":
+		if value, ok := s.values[args[0]]; ok {
+			return value
+		}
+		return []byte(nil)
+	case "GETRANGE":
+		value := s.values[args[0]]
+		start, _ := strconv.Atoi(args[1])
+		end, _ := strconv.Atoi(args[2])
+		if start >= len(value) {
+			return []byte{}
+		}
+		if end >= len(value) {
+			end = len(value) - 1
+		}
+		return value[start : end+1]
+	case "STRLEN":
+		return int64(len(s.values[args[0]]))
+	case "EXISTS":
+		var count int64
+		for _, key := range args {
+			if _, ok := s.values[key]; ok {
+				count++
+			}
+		}
+		return count
+	case "DEL":
+		var count int64
+		for _, key := range args {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				delete(s.expiries, key)
+				count++
+			}
+		}
+		return count
+	case "SCAN":
+		// 一次返回全部匹配的键
+		var keys []interface{}
+		for key := range s.values {
+			if matched, _ := path.Match(args[2], key); matched {
+				keys = append(keys, []byte(key))
+			}
+		}
+		return []interface{}{[]byte("0"), keys}
+	case "PUBLISH":
+		subs := s.subscribers[args[0]]
+		message := []interface{}{[]byte("message"), []byte(args[0]), []byte(args[1])}
+		for _, sub := range subs {
+			sub.mutex.Lock()
+			writeFakeReply(sub.writer, message)
+			sub.writer.Flush()
+			sub.mutex.Unlock()
+		}
+		return int64(len(subs))
+	case "SUBSCRIBE":
+		s.subscribers[args[0]] = append(s.subscribers[args[0]], client)
+		return []interface{}{[]byte("subscribe"), []byte(args[0]), int64(1)}
+	}
+	return fmt.Errorf("ERR unknown command '%s'", cmd)
+}
+
+func writeFakeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case string:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case error:
+		fmt.Fprintf(w, "-%s\r\n", v)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case []byte:
+		if v == nil {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case []interface{}:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeFakeReply(w, item)
+		}
+	}
+}
+
+func newTestRedisCache(t *testing.T, server *fakeRESPServer) *RedisCache {
+	c, err := NewRedisCache(RedisCacheOptions{Addr: server.addr(), Prefix: "test:"})
+	if err != nil {
+		t.Fatalf("创建Redis缓存失败: %v", err)
+	}
+	t.Cleanup(c.Close)
+	waitFor(t, "订阅失效通知", func() bool { return c.GetStats()["subscribed"] == true })
+	return c
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRedisCacheRoundTrip(t *testing.T) {
+	server := newFakeRESPServer(t)
+	c := newTestRedisCache(t, server)
+
+	lastModified := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	if err := c.SetWithTimestamp("a", []byte("hello"), time.Minute, lastModified); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	data, hit, err := c.Get("a")
+	if err != nil || !hit || string(data) != "hello" {
+		t.Fatalf("Get = %q, %v, %v", data, hit, err)
+	}
+	if !c.Has("a") || c.Has("missing") {
+		t.Fatalf("Has结果错误")
+	}
+
+	info, ok := c.Entry("a")
+	if !ok || info.Size != 5 || !info.LastModified.Equal(lastModified) {
+		t.Fatalf("Entry = %+v, %v", info, ok)
+	}
+	if until := time.Until(info.Expiry); until <= 0 || until > time.Minute {
+		t.Fatalf("过期时间错误: %v", info.Expiry)
+	}
+
+	c.Set("b", []byte("world"), time.Minute)
+	if entries := c.Entries(); len(entries) != 2 {
+		t.Fatalf("Entries数量 = %d，期望2", len(entries))
+	}
+
+	if err := c.Delete("a"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if _, hit, _ := c.Get("a"); hit {
+		t.Fatalf("删除后仍命中")
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("清空失败: %v", err)
+	}
+	if entries := c.Entries(); len(entries) != 0 {
+		t.Fatalf("清空后Entries数量 = %d", len(entries))
+	}
+
+	c.Set("short", []byte("x"), 20*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	if _, hit, _ := c.Get("short"); hit {
+		t.Fatalf("过期后仍命中")
+	}
+}
+
+func TestRedisCacheInvalidatesOtherInstances(t *testing.T) {
+	config.Init()
+	server := newFakeRESPServer(t)
+	a := NewEnhancedTwoLevelCacheWithBackend(NewShardedMemoryCache(100, 10), newTestRedisCache(t, server))
+	b := NewEnhancedTwoLevelCacheWithBackend(NewShardedMemoryCache(100, 10), newTestRedisCache(t, server))
+
+	if err := a.SetBothLevels("k", []byte("v1"), time.Minute); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	// B从共享的二级缓存读取，并填充自己的内存缓存
+	data, hit, _ := b.Get("k")
+	if !hit || string(data) != "v1" {
+		t.Fatalf("B读取 = %q, %v", data, hit)
+	}
+	if _, inMemory := b.memory.Entry("k"); !inMemory {
+		t.Fatalf("B的内存缓存未填充")
+	}
+
+	// A更新后B的内存缓存被丢弃，重新读取得到新数据
+	a.SetBothLevels("k", []byte("v2"), time.Minute)
+	waitFor(t, "B丢弃内存缓存", func() bool {
+		_, inMemory := b.memory.Entry("k")
+		return !inMemory
+	})
+	if data, _, _ := b.Get("k"); !bytes.Equal(data, []byte("v2")) {
+		t.Fatalf("B读取 = %q，期望v2", data)
+	}
+
+	// 自己发出的失效通知不影响自己的内存缓存
+	if _, inMemory := a.memory.Entry("k"); !inMemory {
+		t.Fatalf("A的内存缓存被自己的失效通知丢弃")
+	}
+
+	a.Delete("k")
+	waitFor(t, "B删除后丢弃内存缓存", func() bool {
+		_, hit, _ := b.Get("k")
+		return !hit
+	})
+}
+
+func TestRedisCacheResubscribeClearsMemory(t *testing.T) {
+	server := newFakeRESPServer(t)
+	remote := newTestRedisCache(t, server)
+	c := NewEnhancedTwoLevelCacheWithBackend(NewShardedMemoryCache(100, 10), remote)
+
+	c.SetMemoryOnly("k", []byte("v"), time.Minute)
+	server.dropSubscribers()
+
+	// 订阅中断期间可能错过失效通知，重新订阅后清空内存缓存
+	waitFor(t, "重新订阅", func() bool { return remote.GetStats()["resubscribes"].(int64) == 1 })
+	if _, inMemory := c.memory.Entry("k"); inMemory {
+		t.Fatalf("重新订阅后内存缓存未清空")
+	}
+}
+
+func TestRedisCacheReadIsSingleRoundTrip(t *testing.T) {
+	config.Init()
+	server := newFakeRESPServer(t)
+	remote := newTestRedisCache(t, server)
+	c := NewEnhancedTwoLevelCacheWithBackend(NewShardedMemoryCache(100, 10), remote)
+
+	lastModified := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	remote.SetWithTimestamp("k", []byte("v"), time.Minute, lastModified)
+
+	data, gotModified, hit, err := c.GetWithTimestamp("k")
+	if err != nil || !hit || string(data) != "v" || !gotModified.Equal(lastModified) {
+		t.Fatalf("GetWithTimestamp = %q, %v, %v, %v", data, gotModified, hit, err)
+	}
+	if gets := server.commandCount("GET"); gets != 1 {
+		t.Fatalf("GET次数 = %d，期望1", gets)
+	}
+	for _, cmd := range []string{"EXISTS", "GETRANGE", "STRLEN"} {
+		if n := server.commandCount(cmd); n != 0 {
+			t.Fatalf("读取时不应执行%s（%d次）", cmd, n)
+		}
+	}
+	if expiry, ok := c.memory.GetExpiry("k"); !ok || time.Until(expiry) > time.Minute {
+		t.Fatalf("内存缓存应沿用远程缓存的过期时间: %v", expiry)
+	}
+}
+
+func TestRedisCacheMissingKeys(t *testing.T) {
+	config.Init()
+	server := newFakeRESPServer(t)
+	remote := newTestRedisCache(t, server)
+	c := NewEnhancedTwoLevelCacheWithBackend(NewShardedMemoryCache(100, 10), remote)
+
+	remote.Set("remote", []byte("v"), time.Minute)
+	c.SetMemoryOnly("memory", []byte("v"), time.Minute)
+
+	missing := c.missingKeys([]string{"remote", "memory", "gone-1", "gone-2"})
+	if len(missing) != 2 || !missing["gone-1"] || !missing["gone-2"] {
+		t.Fatalf("missingKeys = %v，期望gone-1和gone-2", missing)
+	}
+	// 内存缓存中已有的键不再查询远程缓存，其余键通过一次管道查询
+	if n := server.commandCount("EXISTS"); n != 3 {
+		t.Fatalf("EXISTS次数 = %d，期望3", n)
+	}
+}
+
+func TestRedisCacheEvictionSkipsWriteBack(t *testing.T) {
+	server := newFakeRESPServer(t)
+	remote := newTestRedisCache(t, server)
+	c := NewEnhancedTwoLevelCacheWithBackend(NewShardedMemoryCache(100, 10), remote)
+
+	c.SetMemoryOnly("k", []byte("v"), time.Minute)
+	c.memory.evictFromShard(c.memory.getShard("k"))
+	if _, inMemory := c.memory.Entry("k"); inMemory {
+		t.Fatalf("淘汰后仍在内存缓存中")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := server.commandCount("SET"); n != 0 {
+		t.Fatalf("淘汰时不应回写远程缓存（SET %d次）", n)
+	}
+	if n := server.commandCount("PUBLISH"); n != 0 {
+		t.Fatalf("淘汰时不应发布失效通知（PUBLISH %d次）", n)
+	}
+}
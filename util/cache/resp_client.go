@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// respError Redis协议服务端返回的错误回复
+type respError string
+
+func (e respError) Error() string {
+	return string(e)
+}
+
+// respConn 一个Redis协议连接
+type respConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// respClient 最小化的Redis协议（RESP）客户端，只实现远程缓存用到的命令，兼容任何RESP服务
+type respClient struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	pool     chan *respConn
+}
+
+// newRespClient 创建Redis协议客户端，poolSize为保留的空闲连接数
+func newRespClient(addr, password string, db int, timeout time.Duration, poolSize int) *respClient {
+	return &respClient{
+		addr:     addr,
+		password: password,
+		db:       db,
+		timeout:  timeout,
+		pool:     make(chan *respConn, poolSize),
+	}
+}
+
+// dial 建立新连接，按配置完成认证和选择数据库
+func (c *respClient) dial() (*respConn, error) {
+	conn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	cn := &respConn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+
+	var setup [][]interface{}
+	if c.password != "" {
+		setup = append(setup, []interface{}{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []interface{}{"SELECT", strconv.Itoa(c.db)})
+	}
+	if len(setup) > 0 {
+		replies, err := cn.pipeline(c.timeout, setup)
+		if err == nil {
+			err = firstRespError(replies)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// get 取出一个空闲连接，没有时新建
+func (c *respClient) get() (*respConn, error) {
+	select {
+	case cn := <-c.pool:
+		return cn, nil
+	default:
+		return c.dial()
+	}
+}
+
+// put 归还连接，出错的连接和超出空闲数量的连接直接关闭
+func (c *respClient) put(cn *respConn, broken bool) {
+	if broken {
+		cn.conn.Close()
+		return
+	}
+	select {
+	case c.pool <- cn:
+	default:
+		cn.conn.Close()
+	}
+}
+
+// do 执行单条命令，服务端错误回复作为error返回
+func (c *respClient) do(args ...interface{}) (interface{}, error) {
+	replies, err := c.pipeline([][]interface{}{args})
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := replies[0].(respError); ok {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline 一次发送多条命令并按顺序读取回复，服务端错误回复以respError保留在回复中
+func (c *respClient) pipeline(cmds [][]interface{}) ([]interface{}, error) {
+	cn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	replies, err := cn.pipeline(c.timeout, cmds)
+	c.put(cn, err != nil)
+	return replies, err
+}
+
+// close 关闭所有空闲连接
+func (c *respClient) close() {
+	for {
+		select {
+		case cn := <-c.pool:
+			cn.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// pipeline 在当前连接上发送多条命令并读取回复
+func (cn *respConn) pipeline(timeout time.Duration, cmds [][]interface{}) ([]interface{}, error) {
+	if timeout > 0 {
+		cn.conn.SetDeadline(time.Now().Add(timeout))
+	}
+	for _, cmd := range cmds {
+		if err := cn.writeCommand(cmd...); err != nil {
+			return nil, err
+		}
+	}
+	if err := cn.writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, len(cmds))
+	for i := range cmds {
+		reply, err := cn.readReply()
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+// writeCommand 按RESP数组格式写入一条命令，参数为string或[]byte
+func (cn *respConn) writeCommand(args ...interface{}) error {
+	fmt.Fprintf(cn.writer, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var data []byte
+		switch v := arg.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			return fmt.Errorf("不支持的命令参数类型: %T", arg)
+		}
+		fmt.Fprintf(cn.writer, "$%d\r\n", len(data))
+		cn.writer.Write(data)
+		if _, err := cn.writer.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readReply 读取一条回复：简单字符串返回string，错误返回respError，整数返回int64，
+// 批量字符串返回[]byte（不存在时为nil），数组返回[]interface{}
+func (cn *respConn) readReply() (interface{}, error) {
+	line, err := cn.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("无效的RESP回复")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return respError(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return []byte(nil), nil
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(cn.reader, data); err != nil {
+			return nil, err
+		}
+		return data[:length], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return []interface{}(nil), nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = cn.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("无效的RESP回复类型: %q", line[0])
+}
+
+// readLine 读取一行，去掉结尾的\r\n
+func (cn *respConn) readLine() (string, error) {
+	line, err := cn.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("无效的RESP行")
+	}
+	return line[:len(line)-2], nil
+}
+
+// firstRespError 返回回复中的第一个服务端错误
+func firstRespError(replies []interface{}) error {
+	for _, reply := range replies {
+		if err, ok := reply.(respError); ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,20 +3,61 @@ package cache
 import (
 	"fmt"
 	"hash/fnv"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
+
+	"pansou/util/json"
+)
+
+// 磁盘缓存存储后端
+const (
+	DiskBackendFile = "file" // 每个缓存项一个文件
+	DiskBackendBolt = "bolt" // 所有缓存项保存在一个bbolt文件中
 )
 
+// DiskShard 磁盘缓存分片，文件存储（DiskCache）和bbolt存储（BoltDiskCache）均实现该接口
+type DiskShard interface {
+	Set(key string, data []byte, ttl time.Duration) error
+	SetWithTimestamp(key string, data []byte, ttl time.Duration, lastModified time.Time) error
+	Get(key string) ([]byte, bool, error)
+	Delete(key string) error
+	Has(key string) bool
+	Clear() error
+	GetLastModified(key string) (time.Time, bool)
+	GetExpiry(key string) (time.Time, bool)
+	Entries() []CacheEntryInfo
+	Entry(key string) (CacheEntryInfo, bool)
+	Usage() (int, int64)
+	cleanExpired()
+}
+
+// bulkCacheItem 批量写入的缓存项
+type bulkCacheItem struct {
+	Key          string
+	Data         []byte
+	TTL          time.Duration
+	LastModified time.Time
+}
+
+// bulkWriter 支持批量写入缓存项的存储
+type bulkWriter interface {
+	setMany(items []bulkCacheItem) error
+}
+
 // ShardedDiskCache 分片磁盘缓存
 type ShardedDiskCache struct {
 	baseDir     string
+	backend     string
 	shardCount  int
 	shardMask   uint32 // 用于快速取模的掩码
-	shards      []*DiskCache
+	shards      []DiskShard
 	maxSizeMB   int
 	mutex       sync.RWMutex
+	store       *boltStore // bbolt存储后端共享的数据库
 }
 
 // NewShardedDiskCache 创建新的分片磁盘缓存（兼容现有接口）
@@ -41,6 +82,63 @@ func NewOptimizedShardedDiskCache(baseDir string, maxSizeMB int) (*ShardedDiskCa
 	return newShardedDiskCacheWithCount(baseDir, shardCount, maxSizeMB)
 }
 
+// NewOptimizedBoltShardedDiskCache 创建使用bbolt存储的分片磁盘缓存
+// 所有分片保存在缓存目录下的同一个文件中，并定期压缩文件；缓存目录中已有的文件存储缓存会一次性迁移过来
+func NewOptimizedBoltShardedDiskCache(baseDir string, maxSizeMB int, compactInterval time.Duration) (*ShardedDiskCache, error) {
+	shardCount := runtime.NumCPU() * 2
+	if shardCount < 4 {
+		shardCount = 4
+	}
+	if shardCount > 32 {
+		shardCount = 32
+	}
+	shardCount = nextPowerOfTwoDisk(shardCount)
+
+	shardSize := maxSizeMB / shardCount
+	if shardSize < 1 {
+		shardSize = 1
+	}
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, err
+	}
+	store, err := openBoltStore(filepath.Join(baseDir, boltCacheFile))
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &ShardedDiskCache{
+		baseDir:    baseDir,
+		backend:    DiskBackendBolt,
+		shardCount: shardCount,
+		shardMask:  uint32(shardCount - 1),
+		shards:     make([]DiskShard, shardCount),
+		maxSizeMB:  maxSizeMB,
+		store:      store,
+	}
+
+	for i := 0; i < shardCount; i++ {
+		shard, err := newBoltDiskCache(store, fmt.Sprintf("shard_%d", i), shardSize)
+		if err != nil {
+			return nil, err
+		}
+		cache.shards[i] = shard
+	}
+
+	// 迁移文件存储的缓存
+	if migrated, err := cache.migrateFileShards(); err != nil {
+		fmt.Printf("[bbolt缓存] 迁移文件缓存失败: %v\n", err)
+	} else if migrated > 0 {
+		fmt.Printf("[bbolt缓存] 已迁移 %d 个文件缓存项\n", migrated)
+	}
+
+	if compactInterval > 0 {
+		go cache.startCompactTask(compactInterval)
+	}
+
+	return cache, nil
+}
+
 // 获取下一个2的幂（磁盘缓存版本）
 func nextPowerOfTwoDisk(n int) int {
 	if n <= 1 {
@@ -65,9 +163,10 @@ func newShardedDiskCacheWithCount(baseDir string, shardCount, maxSizeMB int) (*S
 	
 	cache := &ShardedDiskCache{
 		baseDir:    baseDir,
+		backend:    DiskBackendFile,
 		shardCount: shardCount,
 		shardMask:  uint32(shardCount - 1), // 用于快速取模
-		shards:     make([]*DiskCache, shardCount),
+		shards:     make([]DiskShard, shardCount),
 		maxSizeMB:  maxSizeMB,
 	}
 	
@@ -85,7 +184,7 @@ func newShardedDiskCacheWithCount(baseDir string, shardCount, maxSizeMB int) (*S
 }
 
 // 获取键对应的分片
-func (c *ShardedDiskCache) getShard(key string) *DiskCache {
+func (c *ShardedDiskCache) getShard(key string) DiskShard {
 	// 计算哈希值决定分片
 	h := fnv.New32a()
 	h.Write([]byte(key))
@@ -99,6 +198,36 @@ func (c *ShardedDiskCache) Set(key string, data []byte, ttl time.Duration) error
 	return shard.Set(key, data, ttl)
 }
 
+// SetWithTimestamp 设置缓存，并指定最后修改时间
+func (c *ShardedDiskCache) SetWithTimestamp(key string, data []byte, ttl time.Duration, lastModified time.Time) error {
+	shard := c.getShard(key)
+	return shard.SetWithTimestamp(key, data, ttl, lastModified)
+}
+
+// setMany 批量写入缓存项，bbolt存储按分片在一个事务中写入，文件存储逐个写入
+func (c *ShardedDiskCache) setMany(items []bulkCacheItem) error {
+	byShard := make(map[DiskShard][]bulkCacheItem)
+	for _, item := range items {
+		shard := c.getShard(item.Key)
+		byShard[shard] = append(byShard[shard], item)
+	}
+
+	for shard, shardItems := range byShard {
+		if bulk, ok := shard.(bulkWriter); ok {
+			if err := bulk.setMany(shardItems); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, item := range shardItems {
+			if err := shard.SetWithTimestamp(item.Key, item.Data, item.TTL, item.LastModified); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Get 获取缓存
 func (c *ShardedDiskCache) Get(key string) ([]byte, bool, error) {
 	shard := c.getShard(key)
@@ -138,11 +267,17 @@ func (c *ShardedDiskCache) GetLastModified(key string) (time.Time, bool) {
 	return shard.GetLastModified(key)
 }
 
+// GetExpiry 获取缓存项的过期时间
+func (c *ShardedDiskCache) GetExpiry(key string) (time.Time, bool) {
+	shard := c.getShard(key)
+	return shard.GetExpiry(key)
+}
+
 // cleanExpired 清理所有分片中的过期项
 func (c *ShardedDiskCache) cleanExpired() {
 	// 并行清理所有分片中的过期项
 	for _, shard := range c.shards {
-		go func(s *DiskCache) {
+		go func(s DiskShard) {
 			s.cleanExpired()
 		}(shard)
 	}
@@ -161,7 +296,7 @@ func (c *ShardedDiskCache) StartCleanupTask() {
 }
 
 // GetShards 获取所有分片（用于测试和调试）
-func (c *ShardedDiskCache) GetShards() []*DiskCache {
+func (c *ShardedDiskCache) GetShards() []DiskShard {
 	return c.shards
 }
 
@@ -175,4 +310,139 @@ func (c *ShardedDiskCache) GetShardIndex(key string) int {
 		// 兼容老版本的模运算
 		return int(h.Sum32()) % c.shardCount
 	}
-} 
\ No newline at end of file
+} 
+// Entries 获取所有分片中未过期缓存项的信息
+func (c *ShardedDiskCache) Entries() []CacheEntryInfo {
+	var entries []CacheEntryInfo
+	for _, shard := range c.shards {
+		entries = append(entries, shard.Entries()...)
+	}
+	return entries
+}
+
+// Entry 获取单个未过期缓存项的信息
+func (c *ShardedDiskCache) Entry(key string) (CacheEntryInfo, bool) {
+	return c.getShard(key).Entry(key)
+}
+
+// ShardUsage 分片磁盘缓存的单个分片占用情况
+type ShardUsage struct {
+	Shard     int   `json:"shard"`
+	Items     int   `json:"items"`
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+// GetStats 获取分片磁盘缓存的统计信息
+func (c *ShardedDiskCache) GetStats() map[string]interface{} {
+	shards := make([]ShardUsage, 0, len(c.shards))
+	totalItems := 0
+	var totalSize int64
+	for i, shard := range c.shards {
+		items, size := shard.Usage()
+		shards = append(shards, ShardUsage{Shard: i, Items: items, SizeBytes: size})
+		totalItems += items
+		totalSize += size
+	}
+
+	stats := map[string]interface{}{
+		"backend":     c.backend,
+		"base_dir":    c.baseDir,
+		"shard_count": c.shardCount,
+		"max_size_mb": c.maxSizeMB,
+		"items":       totalItems,
+		"size_bytes":  totalSize,
+		"shards":      shards,
+	}
+	if c.store != nil {
+		stats["file_size_bytes"] = c.store.fileSize()
+	}
+	return stats
+}
+
+// startCompactTask 定期压缩bbolt缓存文件，已删除数据占用的空间明显多于有效数据时才压缩
+func (c *ShardedDiskCache) startCompactTask(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		var liveSize int64
+		for _, shard := range c.shards {
+			_, size := shard.Usage()
+			liveSize += size
+		}
+
+		fileSize := c.store.fileSize()
+		if fileSize < 2*liveSize || fileSize-liveSize < 16*1024*1024 {
+			continue
+		}
+
+		start := time.Now()
+		if err := c.store.compact(); err != nil {
+			fmt.Printf("[bbolt缓存] 压缩失败: %v\n", err)
+			continue
+		}
+		fmt.Printf("[bbolt缓存] 压缩完成: %d -> %d 字节，耗时 %v\n", fileSize, c.store.fileSize(), time.Since(start))
+	}
+}
+
+// migrateFileShards 将文件存储的缓存项迁移到当前存储，迁移后删除原文件
+func (c *ShardedDiskCache) migrateFileShards() (int, error) {
+	metaFiles, err := filepath.Glob(filepath.Join(c.baseDir, "shard_*", "*.meta"))
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	now := time.Now()
+	var items []bulkCacheItem
+	var files []string
+
+	// 每次写入一批缓存项后再删除对应的原文件，写入失败时原文件保留，下次启动重新迁移
+	flush := func() error {
+		if err := c.setMany(items); err != nil {
+			return err
+		}
+		migrated += len(items)
+		for _, file := range files {
+			os.Remove(file)
+		}
+		items, files = items[:0], files[:0]
+		return nil
+	}
+
+	for _, metaFile := range metaFiles {
+		dataFile := strings.TrimSuffix(metaFile, ".meta")
+
+		metaData, err := os.ReadFile(metaFile)
+		if err != nil {
+			continue
+		}
+		var meta diskCacheMetadata
+		if err := json.Unmarshal(metaData, &meta); err == nil && meta.Expiry.After(now) {
+			if data, err := os.ReadFile(dataFile); err == nil {
+				items = append(items, bulkCacheItem{Key: meta.Key, Data: data, TTL: time.Until(meta.Expiry), LastModified: meta.LastModified})
+			}
+		}
+
+		// 已迁移、已过期或无法解析的缓存项都删除原文件
+		files = append(files, dataFile, metaFile)
+		if len(items) >= boltBulkWriteSize {
+			if err := flush(); err != nil {
+				return migrated, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return migrated, err
+	}
+
+	// 删除已清空的分片目录
+	shardDirs, _ := filepath.Glob(filepath.Join(c.baseDir, "shard_*"))
+	for _, dir := range shardDirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			os.Remove(dir)
+		}
+	}
+
+	return migrated, nil
+}
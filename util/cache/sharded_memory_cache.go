@@ -45,7 +45,7 @@ type ShardedMemoryCache struct {
 	maxSize   int64
 	itemsPerShard int
 	sizePerShard  int64
-	diskCache     *ShardedDiskCache // 磁盘缓存引用
+	diskCache     CacheBackend      // 磁盘缓存引用
 	diskCacheMutex sync.RWMutex     // 磁盘缓存引用的保护锁
 }
 
@@ -213,6 +213,20 @@ func (c *ShardedMemoryCache) GetLastModified(key string) (time.Time, bool) {
 	return item.lastModified, true
 }
 
+// GetExpiry 获取缓存项的过期时间
+func (c *ShardedMemoryCache) GetExpiry(key string) (time.Time, bool) {
+	shard := c.getShard(key)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	item, exists := shard.items[key]
+	if !exists || time.Now().After(item.expiry) {
+		return time.Time{}, false
+	}
+
+	return item.expiry, true
+}
+
 // 从指定分片中驱逐最久未使用的项（带磁盘备份）
 func (c *ShardedMemoryCache) evictFromShard(shard *memoryCacheShard) {
 	var oldestKey string
@@ -231,15 +245,19 @@ func (c *ShardedMemoryCache) evictFromShard(shard *memoryCacheShard) {
 	// 如果找到了最久未使用的项，删除它
 	if oldestKey != "" && oldestItem != nil {
 		// 🔥 关键优化：淘汰前检查是否需要刷盘保护
+		// 多实例共享的远程缓存不回写：其中可能已有其他实例写入的更新数据，回写还会通知所有实例丢弃内存缓存
 		diskCache := c.getDiskCacheReference()
+		if _, remote := diskCache.(*RedisCache); remote {
+			diskCache = nil
+		}
 		if time.Now().Before(oldestItem.expiry) && diskCache != nil {
 			// 数据还没过期，异步刷新到磁盘保存
-			go func(key string, data []byte, expiry time.Time) {
+			go func(key string, data []byte, expiry, lastModified time.Time) {
 				ttl := time.Until(expiry)
 				if ttl > 0 {
-					diskCache.Set(key, data, ttl) // 保持相同TTL
+					diskCache.SetWithTimestamp(key, data, ttl, lastModified) // 保持相同TTL和最后修改时间
 				}
-			}(oldestKey, oldestItem.data, oldestItem.expiry)
+			}(oldestKey, oldestItem.data, oldestItem.expiry, oldestItem.lastModified)
 		}
 		
 		// 从内存中删除
@@ -336,14 +354,14 @@ func (c *ShardedMemoryCache) StartCleanupTask() {
 }
 
 // SetDiskCacheReference 设置磁盘缓存引用
-func (c *ShardedMemoryCache) SetDiskCacheReference(diskCache *ShardedDiskCache) {
+func (c *ShardedMemoryCache) SetDiskCacheReference(diskCache CacheBackend) {
 	c.diskCacheMutex.Lock()
 	defer c.diskCacheMutex.Unlock()
 	c.diskCache = diskCache
 }
 
 // getDiskCacheReference 获取磁盘缓存引用
-func (c *ShardedMemoryCache) getDiskCacheReference() *ShardedDiskCache {
+func (c *ShardedMemoryCache) getDiskCacheReference() CacheBackend {
 	c.diskCacheMutex.RLock()
 	defer c.diskCacheMutex.RUnlock()
 	return c.diskCache
@@ -351,8 +369,9 @@ func (c *ShardedMemoryCache) getDiskCacheReference() *ShardedDiskCache {
 
 // MemoryCacheItem 内存缓存项结构（用于导出）
 type MemoryCacheItem struct {
-	Data []byte
-	TTL  time.Duration
+	Data         []byte
+	TTL          time.Duration
+	LastModified time.Time
 }
 
 // GetAllItems 获取内存缓存中的所有项
@@ -379,12 +398,74 @@ func (c *ShardedMemoryCache) GetAllItems() map[string]*MemoryCacheItem {
 			}
 			
 			result[key] = &MemoryCacheItem{
-				Data: item.data,
-				TTL:  ttl,
+				Data:         item.data,
+				TTL:          ttl,
+				LastModified: item.lastModified,
 			}
 		}
 		shard.mutex.RUnlock()
 	}
 	
 	return result
-}
\ No newline at end of file
+}
+// Entries 获取所有未过期缓存项的信息
+func (c *ShardedMemoryCache) Entries() []CacheEntryInfo {
+	var entries []CacheEntryInfo
+	now := time.Now()
+
+	for _, shard := range c.shards {
+		shard.mutex.RLock()
+		for key, item := range shard.items {
+			if !item.expiry.IsZero() && now.After(item.expiry) {
+				continue
+			}
+			entries = append(entries, CacheEntryInfo{
+				Key:          key,
+				Size:         item.size,
+				Expiry:       item.expiry,
+				LastModified: item.lastModified,
+			})
+		}
+		shard.mutex.RUnlock()
+	}
+
+	return entries
+}
+
+// Entry 获取单个未过期缓存项的信息
+func (c *ShardedMemoryCache) Entry(key string) (CacheEntryInfo, bool) {
+	shard := c.getShard(key)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	item, exists := shard.items[key]
+	if !exists || (!item.expiry.IsZero() && time.Now().After(item.expiry)) {
+		return CacheEntryInfo{}, false
+	}
+	return CacheEntryInfo{
+		Key:          key,
+		Size:         item.size,
+		Expiry:       item.expiry,
+		LastModified: item.lastModified,
+	}, true
+}
+
+// GetStats 获取分片内存缓存的统计信息
+func (c *ShardedMemoryCache) GetStats() map[string]interface{} {
+	totalItems := 0
+	var totalSize int64
+	for _, shard := range c.shards {
+		shard.mutex.RLock()
+		totalItems += len(shard.items)
+		shard.mutex.RUnlock()
+		totalSize += atomic.LoadInt64(&shard.currSize)
+	}
+
+	return map[string]interface{}{
+		"shard_count": len(c.shards),
+		"max_items":   c.maxItems,
+		"max_size_mb": c.maxSize / (1024 * 1024),
+		"items":       totalItems,
+		"size_bytes":  totalSize,
+	}
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"pansou/config"
+	"pansou/model"
+)
+
+// 缓存快照格式
+// 快照为gzip压缩的gob流：先是一个snapshotHeader，之后是逐条的snapshotEntry
+const (
+	snapshotFormat  = "pansou-cache-snapshot"
+	snapshotVersion = 1
+)
+
+// snapshotHeader 快照文件头
+type snapshotHeader struct {
+	Format    string
+	Version   int
+	CreatedAt time.Time
+}
+
+// snapshotEntry 快照中的一个缓存项
+type snapshotEntry struct {
+	Key          string
+	Data         []byte
+	Expiry       time.Time
+	LastModified time.Time
+	KeyInfo      *CacheKeyInfo // 缓存键对应的搜索参数，未登记时为nil
+}
+
+// SnapshotStats 快照导出或导入的统计
+type SnapshotStats struct {
+	Entries int   `json:"entries"` // 导出或导入的缓存项数
+	Skipped int   `json:"skipped"` // 因已过期、本地数据更新或不是搜索结果而跳过的缓存项数
+	Bytes   int64 `json:"bytes"`   // 缓存数据总大小（未压缩）
+}
+
+// ExportSnapshot 将所有未过期的缓存项写入压缩快照
+// 同时存在于内存和磁盘的缓存项以内存中的数据为准（磁盘写入可能尚未完成）
+func (c *EnhancedTwoLevelCache) ExportSnapshot(w io.Writer) (SnapshotStats, error) {
+	var stats SnapshotStats
+
+	gz := gzip.NewWriter(w)
+	encoder := gob.NewEncoder(gz)
+	header := snapshotHeader{Format: snapshotFormat, Version: snapshotVersion, CreatedAt: time.Now()}
+	if err := encoder.Encode(header); err != nil {
+		return stats, fmt.Errorf("写入快照头失败: %v", err)
+	}
+
+	for _, entry := range c.Entries() {
+		data, _, ok := c.Peek(entry.Key)
+		if !ok {
+			// 导出过程中过期或被删除
+			stats.Skipped++
+			continue
+		}
+
+		item := snapshotEntry{
+			Key:          entry.Key,
+			Data:         data,
+			Expiry:       entry.Expiry,
+			LastModified: entry.LastModified,
+		}
+		if info, ok := LookupCacheKey(entry.Key); ok {
+			item.KeyInfo = &info
+		}
+		if err := encoder.Encode(item); err != nil {
+			return stats, fmt.Errorf("写入快照失败: %v", err)
+		}
+		stats.Entries++
+		stats.Bytes += int64(len(data))
+	}
+
+	if err := gz.Close(); err != nil {
+		return stats, fmt.Errorf("写入快照失败: %v", err)
+	}
+	return stats, nil
+}
+
+// ImportSnapshot 从快照恢复缓存项，保留原有的过期时间和最后修改时间
+// 已过期的缓存项、本地已有更新数据的缓存项和无法解码为搜索结果的缓存项会被跳过；数据写入磁盘缓存，内存中已有的旧数据同时更新
+func (c *EnhancedTwoLevelCache) ImportSnapshot(r io.Reader) (SnapshotStats, error) {
+	var stats SnapshotStats
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return stats, fmt.Errorf("无效的缓存快照: %w", err)
+	}
+	defer gz.Close()
+
+	decoder := gob.NewDecoder(gz)
+	var header snapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return stats, fmt.Errorf("读取快照头失败: %w", err)
+	}
+	if header.Format != snapshotFormat {
+		return stats, fmt.Errorf("无效的缓存快照格式: %s", header.Format)
+	}
+	if header.Version > snapshotVersion {
+		return stats, fmt.Errorf("不支持的缓存快照版本: %d", header.Version)
+	}
+
+	// 磁盘缓存支持批量写入时，每批缓存项在一个事务中写入
+	bulk, _ := c.disk.(bulkWriter)
+	var pending []snapshotEntry
+	flush := func() error {
+		if bulk != nil && len(pending) > 0 {
+			items := make([]bulkCacheItem, len(pending))
+			for i, item := range pending {
+				items[i] = bulkCacheItem{Key: item.Key, Data: item.Data, TTL: time.Until(item.Expiry), LastModified: item.LastModified}
+			}
+			if err := bulk.setMany(items); err != nil {
+				return fmt.Errorf("写入缓存失败: %v", err)
+			}
+		}
+		for _, item := range pending {
+			if bulk == nil {
+				if err := c.disk.SetWithTimestamp(item.Key, item.Data, time.Until(item.Expiry), item.LastModified); err != nil {
+					return fmt.Errorf("写入缓存失败: %v", err)
+				}
+			}
+			if _, inMemory := c.memory.Entry(item.Key); inMemory {
+				c.memory.SetWithTimestamp(item.Key, item.Data, time.Until(item.Expiry), item.LastModified)
+			}
+			if item.KeyInfo != nil {
+				restoreCacheKey(item.Key, *item.KeyInfo)
+			}
+			stats.Entries++
+			stats.Bytes += int64(len(item.Data))
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	for {
+		var item snapshotEntry
+		if err := decoder.Decode(&item); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return stats, fmt.Errorf("读取快照失败: %w", err)
+		}
+
+		if time.Until(item.Expiry) <= 0 || item.Key == "" {
+			stats.Skipped++
+			continue
+		}
+		if existing, ok := c.Entry(item.Key); ok && existing.LastModified.After(item.LastModified) {
+			stats.Skipped++
+			continue
+		}
+		// 只接受搜索结果，避免写入无法使用的数据
+		var results []model.SearchResult
+		if err := c.GetSerializer().Deserialize(item.Data, &results); err != nil {
+			stats.Skipped++
+			continue
+		}
+
+		pending = append(pending, item)
+		if len(pending) >= boltBulkWriteSize {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return stats, err
+	}
+
+	if err := saveCacheKeyIndex(config.AppConfig.CachePath); err != nil {
+		fmt.Printf("[缓存索引] 保存失败: %v\n", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,376 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WAL同步策略
+const (
+	// WALSyncAlways 每条记录写入后立即fsync，系统崩溃也不丢失记录
+	WALSyncAlways = "always"
+
+	// WALSyncInterval 定期fsync，系统崩溃最多丢失一个同步间隔内的记录
+	WALSyncInterval = "interval"
+
+	// WALSyncNone 不主动fsync，进程崩溃（如OOM）不丢失记录，系统崩溃可能丢失
+	WALSyncNone = "none"
+)
+
+const (
+	// walFileName 预写日志文件名，保存在缓存目录中
+	walFileName = "cache_write.wal"
+
+	// walHeaderSize 记录头长度：4字节数据长度 + 4字节CRC32校验
+	walHeaderSize = 8
+
+	// walMaxRecordSize 单条记录的最大长度，超过视为日志损坏
+	walMaxRecordSize = 256 << 20
+
+	// walCompactSize 日志文件超过该大小且仍有未写入磁盘的记录时，重写日志只保留这些记录
+	// 未写入的记录占日志一半以上时不重写，避免每批写入都重写整个日志
+	walCompactSize = 64 << 20
+)
+
+// writeAheadLog 延迟批量写入的预写日志
+// 缓存操作加入缓冲区之前先追加到日志，批量写入磁盘成功后从日志中移除，
+// 进程异常退出时未写入磁盘的操作可在下次启动时从日志恢复
+type writeAheadLog struct {
+	path       string
+	syncMode   string
+	serializer *GobSerializer
+
+	mutex       sync.Mutex
+	file        *os.File
+	size        int64
+	dirty       bool // 有尚未fsync的记录
+	nextSeq     uint64
+	pending     map[uint64]*CacheOperation // 已记录但尚未写入磁盘的操作
+	pendingSize int64                      // 未写入磁盘的记录在日志中的总长度
+	compactSize int64                      // 触发重写的日志大小
+
+	appended    int64
+	truncations int64
+	compactions int64
+	lastError   string
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// openWriteAheadLog 打开预写日志，返回日志中尚未写入磁盘的操作
+// 日志末尾不完整或校验失败的记录（写入中途崩溃）会被截掉
+func openWriteAheadLog(path, syncMode string, syncInterval time.Duration) (*writeAheadLog, []*CacheOperation, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, fmt.Errorf("创建预写日志目录失败: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开预写日志失败: %v", err)
+	}
+
+	w := &writeAheadLog{
+		path:        path,
+		syncMode:    syncMode,
+		serializer:  NewGobSerializer(),
+		file:        file,
+		pending:     make(map[uint64]*CacheOperation),
+		compactSize: walCompactSize,
+		stopChan:    make(chan struct{}),
+	}
+
+	recovered, validSize, err := w.readRecords()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	if info, err := file.Stat(); err == nil && info.Size() > validSize {
+		fmt.Printf("[预写日志] 日志末尾有 %d 字节不完整的记录，已截断\n", info.Size()-validSize)
+		if err := file.Truncate(validSize); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("截断预写日志失败: %v", err)
+		}
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("定位预写日志失败: %v", err)
+	}
+	w.size = validSize
+
+	for _, op := range recovered {
+		w.nextSeq++
+		op.walSeq = w.nextSeq
+		w.pending[op.walSeq] = op
+		w.pendingSize += op.walSize
+	}
+
+	if syncMode == WALSyncInterval {
+		go w.syncLoop(syncInterval)
+	}
+
+	return w, recovered, nil
+}
+
+// readRecords 从头读取日志中的全部完整记录，返回记录和有效数据的长度
+func (w *writeAheadLog) readRecords() ([]*CacheOperation, int64, error) {
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("读取预写日志失败: %v", err)
+	}
+
+	reader := bufio.NewReader(w.file)
+	header := make([]byte, walHeaderSize)
+	var operations []*CacheOperation
+	var validSize int64
+
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			break
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		checksum := binary.BigEndian.Uint32(header[4:])
+		if length == 0 || length > walMaxRecordSize {
+			break
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			break
+		}
+		if crc32.ChecksumIEEE(payload) != checksum {
+			break
+		}
+
+		var op CacheOperation
+		if err := w.serializer.Deserialize(payload, &op); err != nil {
+			break
+		}
+		op.walSize = int64(walHeaderSize) + int64(length)
+		operations = append(operations, &op)
+		validSize += op.walSize
+	}
+
+	return operations, validSize, nil
+}
+
+// encodeRecord 编码一条日志记录
+func (w *writeAheadLog) encodeRecord(op *CacheOperation) ([]byte, error) {
+	payload, err := w.serializer.Serialize(op)
+	if err != nil {
+		return nil, fmt.Errorf("预写日志记录序列化失败: %v", err)
+	}
+
+	record := make([]byte, walHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:walHeaderSize], crc32.ChecksumIEEE(payload))
+	copy(record[walHeaderSize:], payload)
+	return record, nil
+}
+
+// append 追加一条缓存操作记录
+func (w *writeAheadLog) append(op *CacheOperation) error {
+	record, err := w.encodeRecord(op)
+	if err != nil {
+		return err
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("预写日志已关闭")
+	}
+
+	if _, err := w.file.Write(record); err != nil {
+		w.lastError = err.Error()
+		return fmt.Errorf("写入预写日志失败: %v", err)
+	}
+	w.size += int64(len(record))
+	w.appended++
+
+	if w.syncMode == WALSyncAlways {
+		if err := w.file.Sync(); err != nil {
+			w.lastError = err.Error()
+			return fmt.Errorf("同步预写日志失败: %v", err)
+		}
+	} else {
+		w.dirty = true
+	}
+
+	w.nextSeq++
+	op.walSeq = w.nextSeq
+	op.walSize = int64(len(record))
+	w.pending[op.walSeq] = op
+	w.pendingSize += op.walSize
+	return nil
+}
+
+// resolve 标记操作已写入磁盘
+// 全部记录都已写入时清空日志，否则在日志过大且大部分是已写入的记录时重写日志只保留未写入的记录
+func (w *writeAheadLog) resolve(operations []*CacheOperation) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, op := range operations {
+		if _, ok := w.pending[op.walSeq]; ok {
+			delete(w.pending, op.walSeq)
+			w.pendingSize -= op.walSize
+		}
+	}
+
+	if w.file == nil || w.size == 0 {
+		return
+	}
+
+	if len(w.pending) == 0 {
+		if err := w.truncate(); err != nil {
+			w.lastError = err.Error()
+			fmt.Printf("[预写日志] 清空日志失败: %v\n", err)
+		}
+		return
+	}
+
+	if w.size > w.compactSize && w.size > 2*w.pendingSize {
+		if err := w.compact(); err != nil {
+			w.lastError = err.Error()
+			fmt.Printf("[预写日志] 重写日志失败: %v\n", err)
+		}
+	}
+}
+
+// truncate 清空日志，调用方需持有锁
+func (w *writeAheadLog) truncate() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w.size = 0
+	w.pendingSize = 0
+	w.dirty = false
+	w.truncations++
+	return nil
+}
+
+// compact 重写日志只保留未写入磁盘的记录，先写临时文件再替换，调用方需持有锁
+func (w *writeAheadLog) compact() error {
+	seqs := make([]uint64, 0, len(w.pending))
+	for seq := range w.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	tmpPath := w.path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	sizes := make(map[uint64]int64, len(seqs))
+	for _, seq := range seqs {
+		record, err := w.encodeRecord(w.pending[seq])
+		if err == nil {
+			_, err = tmpFile.Write(record)
+		}
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+		sizes[seq] = int64(len(record))
+		size += int64(len(record))
+	}
+
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	w.file.Close()
+	w.file = tmpFile
+	w.size = size
+	w.pendingSize = size
+	for seq, recordSize := range sizes {
+		w.pending[seq].walSize = recordSize
+	}
+	w.dirty = false
+	w.compactions++
+	return nil
+}
+
+// syncLoop 按间隔将日志同步到磁盘
+func (w *writeAheadLog) syncLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mutex.Lock()
+			if w.file != nil && w.dirty {
+				if err := w.file.Sync(); err != nil {
+					w.lastError = err.Error()
+				} else {
+					w.dirty = false
+				}
+			}
+			w.mutex.Unlock()
+
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+// close 同步并关闭日志，未写入磁盘的记录保留在日志中，下次启动时恢复
+func (w *writeAheadLog) close() error {
+	w.stopOnce.Do(func() { close(w.stopChan) })
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	syncErr := w.file.Sync()
+	closeErr := w.file.Close()
+	w.file = nil
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+// stats 获取预写日志统计信息
+func (w *writeAheadLog) stats() map[string]interface{} {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return map[string]interface{}{
+		"path":        w.path,
+		"sync":        w.syncMode,
+		"size_bytes":  w.size,
+		"pending":     len(w.pending),
+		"appended":    w.appended,
+		"truncations": w.truncations,
+		"compactions": w.compactions,
+		"last_error":  w.lastError,
+	}
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pansou/model"
+)
+
+func newTestOperation(key string) *CacheOperation {
+	return &CacheOperation{
+		Key:       key,
+		Data:      []model.SearchResult{{UniqueID: key, Title: "标题 " + key}},
+		TTL:       time.Hour,
+		Timestamp: time.Now(),
+	}
+}
+
+func openTestWAL(t *testing.T, path string) (*writeAheadLog, []*CacheOperation) {
+	t.Helper()
+	wal, recovered, err := openWriteAheadLog(path, WALSyncNone, time.Second)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	t.Cleanup(func() { wal.close() })
+	return wal, recovered
+}
+
+func operationKeys(operations []*CacheOperation) []string {
+	keys := make([]string, len(operations))
+	for i, op := range operations {
+		keys[i] = op.Key
+	}
+	return keys
+}
+
+func TestWriteAheadLogReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), walFileName)
+
+	wal, recovered := openTestWAL(t, path)
+	if len(recovered) != 0 {
+		t.Fatalf("新日志恢复出 %d 条记录", len(recovered))
+	}
+	ops := []*CacheOperation{newTestOperation("a"), newTestOperation("b"), newTestOperation("c")}
+	for _, op := range ops {
+		if err := wal.append(op); err != nil {
+			t.Fatalf("追加记录失败: %v", err)
+		}
+	}
+	// a已写入磁盘，但日志中仍有未写入的记录，日志不会被清空
+	wal.resolve(ops[:1])
+	wal.close()
+
+	_, recovered = openTestWAL(t, path)
+	if got := fmt.Sprint(operationKeys(recovered)); got != "[a b c]" {
+		t.Fatalf("恢复的记录 = %s，期望 [a b c]", got)
+	}
+	if recovered[1].Data[0].Title != "标题 b" || !recovered[1].Timestamp.Equal(ops[1].Timestamp) {
+		t.Fatalf("恢复的记录内容不一致: %+v", recovered[1])
+	}
+}
+
+func TestWriteAheadLogTruncateWhenResolved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), walFileName)
+
+	wal, _ := openTestWAL(t, path)
+	ops := []*CacheOperation{newTestOperation("a"), newTestOperation("b")}
+	for _, op := range ops {
+		wal.append(op)
+	}
+	wal.resolve(ops)
+
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Fatalf("全部记录写入后日志应清空")
+	}
+	if wal.pendingSize != 0 {
+		t.Fatalf("清空后pendingSize = %d，期望 0", wal.pendingSize)
+	}
+}
+
+func TestWriteAheadLogTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), walFileName)
+
+	wal, _ := openTestWAL(t, path)
+	wal.append(newTestOperation("a"))
+	wal.append(newTestOperation("b"))
+	validSize := wal.size
+	wal.close()
+
+	// 模拟写入最后一条记录中途崩溃：只写入了记录头和部分数据
+	record, err := wal.encodeRecord(newTestOperation("c"))
+	if err != nil {
+		t.Fatalf("编码记录失败: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("打开日志失败: %v", err)
+	}
+	file.Write(record[:len(record)/2])
+	file.Close()
+
+	wal, recovered := openTestWAL(t, path)
+	if got := fmt.Sprint(operationKeys(recovered)); got != "[a b]" {
+		t.Fatalf("恢复的记录 = %s，期望 [a b]", got)
+	}
+	if info, _ := os.Stat(path); info.Size() != validSize {
+		t.Fatalf("截断后日志大小 = %d，期望 %d", info.Size(), validSize)
+	}
+
+	// 截断后追加的记录可以正常恢复
+	wal.append(newTestOperation("d"))
+	wal.close()
+	_, recovered = openTestWAL(t, path)
+	if got := fmt.Sprint(operationKeys(recovered)); got != "[a b d]" {
+		t.Fatalf("恢复的记录 = %s，期望 [a b d]", got)
+	}
+}
+
+func TestWriteAheadLogCorruptRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), walFileName)
+
+	wal, _ := openTestWAL(t, path)
+	wal.append(newTestOperation("a"))
+	firstSize := wal.size
+	wal.append(newTestOperation("b"))
+	wal.close()
+
+	// 破坏第二条记录的数据，校验失败后该记录及之后的内容都被截掉
+	data, _ := os.ReadFile(path)
+	data[firstSize+walHeaderSize] ^= 0xff
+	os.WriteFile(path, data, 0644)
+
+	_, recovered := openTestWAL(t, path)
+	if got := fmt.Sprint(operationKeys(recovered)); got != "[a]" {
+		t.Fatalf("恢复的记录 = %s，期望 [a]", got)
+	}
+}
+
+func TestWriteAheadLogCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), walFileName)
+
+	wal, _ := openTestWAL(t, path)
+	var ops []*CacheOperation
+	for i := 0; i < 10; i++ {
+		op := newTestOperation(fmt.Sprintf("key-%d", i))
+		wal.append(op)
+		ops = append(ops, op)
+	}
+	wal.compactSize = wal.size / 4
+
+	// 未写入的记录仍占日志一半以上，不重写
+	wal.resolve(ops[:4])
+	if wal.compactions != 0 {
+		t.Fatalf("未写入的记录占一半以上时不应重写日志")
+	}
+
+	// 已写入的记录超过一半后重写，只保留未写入的记录
+	wal.resolve(ops[4:8])
+	if wal.compactions != 1 {
+		t.Fatalf("重写次数 = %d，期望 1", wal.compactions)
+	}
+	if info, _ := os.Stat(path); info.Size() != wal.size || wal.size != wal.pendingSize {
+		t.Fatalf("重写后日志大小 = %d，size = %d，pendingSize = %d", info.Size(), wal.size, wal.pendingSize)
+	}
+
+	// 重写后继续追加，日志中只剩重写时未写入的记录和之后追加的记录
+	wal.append(newTestOperation("key-10"))
+	wal.close()
+
+	_, recovered := openTestWAL(t, path)
+	if got := fmt.Sprint(operationKeys(recovered)); got != "[key-8 key-9 key-10]" {
+		t.Fatalf("恢复的记录 = %s，期望 [key-8 key-9 key-10]", got)
+	}
+}
@@ -32,13 +32,22 @@ func (g *gzipResponseWriter) Close() {
 }
 
 // GzipMiddleware 返回一个Gin中间件，用于压缩HTTP响应
-func GzipMiddleware() gin.HandlerFunc {
+// excludedPrefixes 中的路径前缀不压缩，用于需要逐条推送数据的流式接口
+func GzipMiddleware(excludedPrefixes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 如果未启用压缩，直接跳过
 		if !config.AppConfig.EnableCompression {
 			c.Next()
 			return
 		}
+
+		// 流式接口需要立即发送数据，不能整体缓冲后压缩
+		for _, prefix := range excludedPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
 		
 		// 检查客户端是否支持gzip
 		if !strings.Contains(c.Request.Header.Get("Accept-Encoding"), "gzip") {
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"pansou/model"
+	"pansou/util/linkid"
+)
+
+// htmlTagPattern 判断内容是否为HTML
+var htmlTagPattern = regexp.MustCompile(`<[a-zA-Z][^>]*>`)
+
+// ExtractLinksFromContent 从任意文本或HTML（论坛帖子、TG消息导出等）中提取网盘链接
+// 返回内容标题和链接列表，链接包含网盘类型、提取码和所属作品标题，同一分享只保留一个
+func ExtractLinksFromContent(content string) (string, []model.Link) {
+	htmlContent := ""
+	text := content
+	if htmlTagPattern.MatchString(content) {
+		htmlContent = content
+		text = htmlToText(content)
+	}
+
+	foundLinks := make(map[string]bool)
+	var links []model.Link
+	for _, url := range ExtractNetDiskLinks(text) {
+		dedupKey := linkid.DedupKey(url)
+		if foundLinks[dedupKey] {
+			continue
+		}
+		foundLinks[dedupKey] = true
+
+		links = append(links, model.Link{
+			Type:     GetLinkType(url),
+			URL:      url,
+			Password: ExtractPassword(linkContext(text, url), url),
+		})
+	}
+
+	title := extractTitle(htmlContent, text)
+	return title, extractWorkTitlesForLinks(links, text, title)
+}
+
+// linkContext 返回链接所在行，下一行不含其他链接时一并返回（提取码常写在链接的下一行）
+// 提取码只在链接附近查找，避免用到其他链接的提取码
+func linkContext(text, url string) string {
+	index := strings.Index(text, url)
+	if index < 0 {
+		return text
+	}
+
+	start := strings.LastIndex(text[:index], "\n") + 1
+	lines := strings.SplitN(text[start:], "\n", 3)
+	context := lines[0]
+	if len(lines) > 1 && !strings.Contains(lines[1], "://") {
+		context += "\n" + lines[1]
+	}
+	return context
+}
+
+// htmlToText 将HTML转换为按行排列的纯文本，链接地址与显示文字不同时一并保留
+func htmlToText(htmlContent string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return htmlContent
+	}
+
+	doc.Find("script,style").Remove()
+	doc.Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		if href != "" && !strings.Contains(a.Text(), href) {
+			a.AppendHtml(" " + html.EscapeString(href) + " ")
+		}
+	})
+	doc.Find("br").ReplaceWithHtml("\n")
+	doc.Find("p,div,li,tr,h1,h2,h3,h4,h5,h6,blockquote,pre").Each(func(i int, s *goquery.Selection) {
+		s.AppendHtml("\n")
+	})
+
+	lines := strings.Split(doc.Text(), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,161 @@
+// Package linkid 网盘分享链接的统一解析与规范化
+// 将任意受支持的分享链接解析为（网盘类型、分享ID、提取码、规范URL），
+// 供去重、缓存键计算和链接检测统一使用
+package linkid
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"pansou/util/netdisk"
+)
+
+// LinkID 分享链接的解析结果
+type LinkID struct {
+	Provider     string // 网盘类型：baidu、quark、aliyun等，取自网盘注册表
+	ShareID      string // 分享ID
+	Password     string // 提取码（链接中携带或调用方传入）
+	CanonicalURL string // 规范URL：统一协议和域名，密码按网盘原生参数附带
+}
+
+// Key 返回与提取码无关的唯一标识，格式为"网盘类型:分享ID"，用于去重
+func (id LinkID) Key() string {
+	return id.Provider + ":" + id.ShareID
+}
+
+var (
+	magnetHashPattern = regexp.MustCompile(`(?i)xt=urn:btih:([A-Za-z0-9]+)`)
+	ed2kHashPattern   = regexp.MustCompile(`(?i)ed2k://\|file\|[^|]*\|\d+\|([A-Fa-f0-9]{32})\|`)
+)
+
+// Parse 解析分享链接，不受支持或无法提取分享ID时返回false
+func Parse(rawURL string) (LinkID, bool) {
+	return ParseWithPassword(rawURL, "")
+}
+
+// ParseWithPassword 解析分享链接，链接中未携带提取码时使用传入的password
+func ParseWithPassword(rawURL, password string) (LinkID, bool) {
+	raw := strings.TrimSpace(rawURL)
+	if raw == "" {
+		return LinkID{}, false
+	}
+	password = strings.TrimSpace(password)
+
+	lower := strings.ToLower(raw)
+	if strings.HasPrefix(lower, "magnet:") {
+		m := magnetHashPattern.FindStringSubmatch(raw)
+		if m == nil {
+			return LinkID{}, false
+		}
+		hash := strings.ToLower(m[1])
+		return LinkID{Provider: "magnet", ShareID: hash, CanonicalURL: "magnet:?xt=urn:btih:" + hash}, true
+	}
+	if strings.HasPrefix(lower, "ed2k:") {
+		m := ed2kHashPattern.FindStringSubmatch(raw)
+		if m == nil {
+			return LinkID{}, false
+		}
+		return LinkID{Provider: "ed2k", ShareID: strings.ToLower(m[1]), CanonicalURL: raw}, true
+	}
+
+	parsed, ok := netdisk.ParseURL(raw)
+	if !ok {
+		return LinkID{}, false
+	}
+
+	provider := netdisk.MatchHost(parsed.Host)
+	if provider == nil {
+		return LinkID{}, false
+	}
+
+	shareID, linkPassword := provider.Parse(parsed, raw)
+	if shareID == "" {
+		return LinkID{}, false
+	}
+	if linkPassword == "" {
+		linkPassword = password
+	}
+
+	return LinkID{
+		Provider:     provider.Name,
+		ShareID:      shareID,
+		Password:     linkPassword,
+		CanonicalURL: provider.Canonical(shareID, linkPassword),
+	}, true
+}
+
+// Canonicalize 返回链接的规范形式
+// 受支持的分享链接返回规范URL；其他链接统一协议、域名大小写、查询参数顺序并去掉锚点
+func Canonicalize(rawURL string) string {
+	if id, ok := Parse(rawURL); ok {
+		return id.CanonicalURL
+	}
+	return normalizeGenericURL(rawURL)
+}
+
+// WithPassword 返回携带指定提取码的规范URL，链接中原有的提取码会被替换
+// 无法解析或没有网盘注册信息的链接原样返回
+func WithPassword(rawURL, password string) string {
+	id, ok := Parse(rawURL)
+	if !ok {
+		return rawURL
+	}
+	provider, ok := netdisk.Get(id.Provider)
+	if !ok {
+		return rawURL
+	}
+	return provider.Canonical(id.ShareID, strings.TrimSpace(password))
+}
+
+// DedupKey 返回用于去重和缓存的键
+// 受支持的分享链接返回"网盘类型:分享ID"，因此仅域名、参数顺序或提取码写法不同的链接会得到相同的键
+func DedupKey(rawURL string) string {
+	if id, ok := Parse(rawURL); ok {
+		return id.Key()
+	}
+	return normalizeGenericURL(rawURL)
+}
+
+// Unescape 解码URL中的编码字符（如URL编码的中文），解码失败时返回原始URL
+func Unescape(rawURL string) string {
+	decoded, err := url.QueryUnescape(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return decoded
+}
+
+// normalizeGenericURL 通用URL标准化：解码、统一协议和域名大小写、排序查询参数、去掉锚点
+func normalizeGenericURL(rawURL string) string {
+	raw := Unescape(strings.TrimSpace(rawURL))
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return raw
+	}
+
+	parsed.Scheme = "https"
+	parsed.Host = strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	parsed.Fragment = ""
+
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			for _, value := range query[key] {
+				parts = append(parts, key+"="+value)
+			}
+		}
+		parsed.RawQuery = strings.Join(parts, "&")
+	}
+
+	return parsed.String()
+}
@@ -0,0 +1,174 @@
+package linkid
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want LinkID
+	}{
+		{
+			name: "百度带提取码",
+			url:  "https://pan.baidu.com/s/1AbCdEf?pwd=abcd",
+			want: LinkID{Provider: "baidu", ShareID: "1AbCdEf", Password: "abcd", CanonicalURL: "https://pan.baidu.com/s/1AbCdEf?pwd=abcd"},
+		},
+		{
+			name: "夸克",
+			url:  "https://pan.quark.cn/s/abc123",
+			want: LinkID{Provider: "quark", ShareID: "abc123", CanonicalURL: "https://pan.quark.cn/s/abc123"},
+		},
+		{
+			name: "阿里云盘alipan",
+			url:  "https://www.alipan.com/s/AbC123xyz",
+			want: LinkID{Provider: "aliyun", ShareID: "AbC123xyz", CanonicalURL: "https://www.alipan.com/s/AbC123xyz"},
+		},
+		{
+			name: "阿里云盘aliyundrive",
+			url:  "https://www.aliyundrive.com/s/AbC123xyz",
+			want: LinkID{Provider: "aliyun", ShareID: "AbC123xyz", CanonicalURL: "https://www.alipan.com/s/AbC123xyz"},
+		},
+		{
+			name: "115带密码",
+			url:  "https://115.com/s/sw1abcd?password=x1y2",
+			want: LinkID{Provider: "115", ShareID: "sw1abcd", Password: "x1y2", CanonicalURL: "https://115cdn.com/s/sw1abcd?password=x1y2"},
+		},
+		{
+			name: "123网盘",
+			url:  "https://www.123pan.com/s/abc-DEF12",
+			want: LinkID{Provider: "123", ShareID: "abc-DEF12", CanonicalURL: "https://www.123pan.com/s/abc-DEF12"},
+		},
+		{
+			name: "磁力链接",
+			url:  "magnet:?xt=urn:btih:ABCDEF0123456789ABCDEF0123456789ABCDEF01&dn=test",
+			want: LinkID{Provider: "magnet", ShareID: "abcdef0123456789abcdef0123456789abcdef01", CanonicalURL: "magnet:?xt=urn:btih:abcdef0123456789abcdef0123456789abcdef01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.url)
+			if !ok {
+				t.Fatalf("Parse(%q)失败", tt.url)
+			}
+			if got != tt.want {
+				t.Fatalf("Parse(%q) = %+v，期望 %+v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	for _, raw := range []string{"", "https://example.com/s/abc", "magnet:?dn=no-hash", "not a url"} {
+		if id, ok := Parse(raw); ok {
+			t.Errorf("Parse(%q) = %+v，期望无法解析", raw, id)
+		}
+	}
+}
+
+func TestDedupKeyAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		urls []string
+		want string
+	}{
+		{
+			name: "123网盘七个域名",
+			urls: []string{
+				"https://www.123pan.com/s/abc-DEF12",
+				"https://www.123pan.cn/s/abc-DEF12",
+				"https://www.123684.com/s/abc-DEF12",
+				"https://www.123685.com/s/abc-DEF12",
+				"https://www.123865.com/s/abc-DEF12",
+				"https://www.123912.com/s/abc-DEF12",
+				"https://www.123592.com/s/abc-DEF12",
+			},
+			want: "123:abc-DEF12",
+		},
+		{
+			name: "阿里云盘新旧域名",
+			urls: []string{
+				"https://www.alipan.com/s/AbC123xyz",
+				"https://www.aliyundrive.com/s/AbC123xyz",
+				"http://alipan.com/s/AbC123xyz",
+			},
+			want: "aliyun:AbC123xyz",
+		},
+		{
+			name: "115三个域名",
+			urls: []string{
+				"https://115.com/s/sw1abcd?password=x1y2",
+				"https://115cdn.com/s/sw1abcd?password=x1y2",
+				"https://anxia.com/s/sw1abcd?password=x1y2",
+			},
+			want: "115:sw1abcd",
+		},
+		{
+			name: "提取码写法不同",
+			urls: []string{
+				"https://pan.baidu.com/s/1AbCdEf?pwd=abcd",
+				"https://pan.baidu.com/s/1AbCdEf",
+				"https://yun.baidu.com/s/1AbCdEf?pwd=wxyz",
+			},
+			want: "baidu:1AbCdEf",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, raw := range tt.urls {
+				if got := DedupKey(raw); got != tt.want {
+					t.Errorf("DedupKey(%q) = %q，期望 %q", raw, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCanonicalizeAliases(t *testing.T) {
+	tests := []struct {
+		urls []string
+		want string
+	}{
+		{
+			urls: []string{"https://www.123pan.cn/s/abc-DEF12", "https://www.123912.com/s/abc-DEF12"},
+			want: "https://www.123pan.com/s/abc-DEF12",
+		},
+		{
+			urls: []string{"https://www.aliyundrive.com/s/AbC123xyz", "http://alipan.com/s/AbC123xyz"},
+			want: "https://www.alipan.com/s/AbC123xyz",
+		},
+		{
+			urls: []string{"https://anxia.com/s/sw1abcd?password=x1y2", "https://115.com/s/sw1abcd?password=x1y2"},
+			want: "https://115cdn.com/s/sw1abcd?password=x1y2",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, raw := range tt.urls {
+			if got := Canonicalize(raw); got != tt.want {
+				t.Errorf("Canonicalize(%q) = %q，期望 %q", raw, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestDedupKeyQueryOrder(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"https://example.com/download?id=1&file=a", "https://example.com/download?file=a&id=1"},
+		{"http://WWW.Example.com/d?b=2&a=1#top", "https://example.com/d?a=1&b=2"},
+		{"https://example.com/d?%E4%B8%AD=1&x=2", "https://example.com/d?x=2&中=1"},
+	}
+
+	for _, tt := range tests {
+		if ka, kb := DedupKey(tt.a), DedupKey(tt.b); ka != kb {
+			t.Errorf("DedupKey(%q) = %q 与 DedupKey(%q) = %q 不同", tt.a, ka, tt.b, kb)
+		}
+	}
+
+	if DedupKey("https://example.com/d?a=1") == DedupKey("https://example.com/d?a=2") {
+		t.Errorf("参数值不同的链接不应得到相同的键")
+	}
+}
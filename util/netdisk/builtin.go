@@ -0,0 +1,130 @@
+package netdisk
+
+import (
+	"net/url"
+	"regexp"
+)
+
+var baiduSharePattern = regexp.MustCompile(`/s/([A-Za-z0-9_-]+)`)
+
+// builtinProviders 内置网盘定义，注册顺序即链接类型识别的优先顺序
+func builtinProviders() []Provider {
+	return []Provider{
+		{
+			Name:        "baidu",
+			DisplayName: "百度网盘",
+			Hosts:       []string{"pan.baidu.com", "yun.baidu.com"},
+			parse: func(u *url.URL, raw string) (string, string) {
+				shareID := ""
+				if m := baiduSharePattern.FindStringSubmatch(u.Path); m != nil {
+					shareID = m[1]
+				} else if surl := u.Query().Get("surl"); surl != "" {
+					// /share/init?surl=xxx 形式的surl省略了开头的"1"
+					shareID = "1" + surl
+				}
+				return shareID, u.Query().Get("pwd")
+			},
+			CanonicalURL:           "https://pan.baidu.com/s/{id}",
+			CanonicalPasswordParam: "pwd",
+		},
+		{
+			Name:                   "quark",
+			DisplayName:            "夸克网盘",
+			Hosts:                  []string{"pan.quark.cn"},
+			CanonicalURL:           "https://pan.quark.cn/s/{id}",
+			CanonicalPasswordParam: "pwd",
+		},
+		{
+			Name:         "aliyun",
+			DisplayName:  "阿里云盘",
+			Hosts:        []string{"alipan.com", "aliyundrive.com"},
+			CanonicalURL: "https://www.alipan.com/s/{id}",
+		},
+		{
+			Name:         "guangya",
+			DisplayName:  "光鸭云盘",
+			Hosts:        []string{"guangyapan.com"},
+			CanonicalURL: "https://www.guangyapan.com/s/{id}",
+		},
+		{
+			Name:           "tianyi",
+			DisplayName:    "天翼云盘",
+			Hosts:          []string{"cloud.189.cn"},
+			SharePatterns:  []string{`[?&]code=([A-Za-z0-9]+)`, `^/t/([A-Za-z0-9]+)`, `#/t/([A-Za-z0-9]+)`},
+			InlinePassword: true,
+			CanonicalURL:   "https://cloud.189.cn/t/{id}",
+		},
+		{
+			Name:                   "uc",
+			DisplayName:            "UC网盘",
+			Hosts:                  []string{"drive.uc.cn"},
+			CanonicalURL:           "https://drive.uc.cn/s/{id}",
+			CanonicalPasswordParam: "pwd",
+		},
+		{
+			Name:        "mobile",
+			DisplayName: "移动云盘",
+			Hosts:       []string{"yun.139.com", "caiyun.139.com", "caiyun.feixin.10086.cn"},
+			SharePatterns: []string{
+				`#/w/i/([A-Za-z0-9]+)`,
+				`/w/i/([A-Za-z0-9]+)`,
+				`/m/i\?([A-Za-z0-9]+)`,
+				`^/([A-Za-z0-9]+)\?`, // caiyun.feixin.10086.cn/{分享ID}
+			},
+			CanonicalURL: "https://yun.139.com/shareweb/#/w/i/{id}",
+		},
+		{
+			Name:                   "115",
+			DisplayName:            "115网盘",
+			Hosts:                  []string{"115.com", "115cdn.com", "anxia.com"},
+			PasswordParams:         []string{"password"},
+			CanonicalURL:           "https://115cdn.com/s/{id}",
+			CanonicalPasswordParam: "password",
+		},
+		{
+			Name:         "pikpak",
+			DisplayName:  "PikPak",
+			Hosts:        []string{"mypikpak.com"},
+			CanonicalURL: "https://mypikpak.com/s/{id}",
+		},
+		{
+			Name:                   "xunlei",
+			DisplayName:            "迅雷网盘",
+			Hosts:                  []string{"pan.xunlei.com"},
+			CanonicalURL:           "https://pan.xunlei.com/s/{id}",
+			CanonicalPasswordParam: "pwd",
+		},
+		{
+			Name:           "123",
+			DisplayName:    "123网盘",
+			Hosts:          []string{"123684.com", "123685.com", "123865.com", "123912.com", "123pan.com", "123pan.cn", "123592.com"},
+			InlinePassword: true,
+			CanonicalURL:   "https://www.123pan.com/s/{id}",
+		},
+		{
+			Name:           "lanzou",
+			DisplayName:    "蓝奏云",
+			Hosts:          []string{"lanzou.com", "lanzoui.com", "lanzoux.com", "lanzouw.com", "lanzous.com"},
+			SharePatterns:  []string{`^/([A-Za-z0-9]+)\?`},
+			InlinePassword: true,
+			CanonicalURL:   "https://www.lanzoui.com/{id}",
+		},
+		{
+			Name:          "weiyun",
+			DisplayName:   "微云",
+			Hosts:         []string{"share.weiyun.com"},
+			SharePatterns: []string{`^/([A-Za-z0-9]+)\?`},
+			CanonicalURL:  "https://share.weiyun.com/{id}",
+		},
+		{
+			Name:          "jianguoyun",
+			DisplayName:   "坚果云",
+			Hosts:         []string{"jianguoyun.com"},
+			SharePatterns: []string{`/p/([A-Za-z0-9_-]+)`},
+			CanonicalURL:  "https://www.jianguoyun.com/p/{id}",
+		},
+		// 磁力链接和电驴链接没有域名，只用于链接类型校验
+		{Name: "magnet", DisplayName: "磁力链接"},
+		{Name: "ed2k", DisplayName: "电驴链接"},
+	}
+}
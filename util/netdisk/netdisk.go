@@ -0,0 +1,329 @@
+// Package netdisk 网盘类型注册表
+// 每个网盘声明域名、分享ID规则、提取码约定和显示名称，
+// 链接类型识别、链接提取、去重和cloud_types校验都以注册表为准。
+// 除内置网盘外，还可以通过配置文件追加或覆盖网盘定义，新增网盘无需修改代码
+package netdisk
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	jsonutil "pansou/util/json"
+)
+
+// OthersType 无法识别的链接类型
+const OthersType = "others"
+
+// Provider 网盘定义
+type Provider struct {
+	Name        string   `json:"name"`         // 网盘类型，与搜索结果中的链接类型一致，如"baidu"
+	DisplayName string   `json:"display_name"` // 显示名称，如"百度网盘"
+	Hosts       []string `json:"hosts"`        // 域名（不含www.前缀），同时匹配其子域名
+	// SharePatterns 分享ID正则，依次匹配"路径?查询参数#锚点"，第一个分组为分享ID
+	// 为空时使用"/s/{分享ID}"
+	SharePatterns []string `json:"share_patterns"`
+	// PasswordParams 携带提取码的查询参数名，查询参数和锚点中都会查找，为空时使用"pwd"
+	PasswordParams []string `json:"password_params"`
+	// InlinePassword 是否识别链接后附带的“提取码:xxxx”“（访问码：xxxx）”
+	InlinePassword bool `json:"inline_password"`
+	// CanonicalURL 规范URL模板，{id}替换为分享ID，为空时使用"https://{第一个域名}/s/{id}"
+	CanonicalURL string `json:"canonical_url"`
+	// CanonicalPasswordParam 规范URL中携带提取码的参数名，为空时规范URL不携带提取码
+	CanonicalPasswordParam string `json:"canonical_password_param"`
+
+	sharePatterns []*regexp.Regexp
+	linkPattern   *regexp.Regexp // 从文本中提取链接的正则，没有域名时为nil
+	// parse 内置网盘的特殊解析逻辑，设置后代替SharePatterns和PasswordParams
+	parse func(u *url.URL, raw string) (shareID, password string)
+}
+
+var (
+	defaultSharePattern  = `/s/([A-Za-z0-9_-]+)`
+	inlinePasswordRegexp = regexp.MustCompile(`(?:提取码|访问码|密码|pwd|password)\s*[=:：]\s*([A-Za-z0-9]{4,8})`)
+)
+
+// registry 已注册的网盘，按注册顺序保存
+var registry = struct {
+	sync.RWMutex
+	providers []*Provider
+}{}
+
+func init() {
+	for _, p := range builtinProviders() {
+		if err := Register(p); err != nil {
+			panic(fmt.Sprintf("内置网盘定义无效: %v", err))
+		}
+	}
+}
+
+// Register 注册网盘定义，已存在同名网盘时替换原有定义
+func Register(p Provider) error {
+	p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+	if p.Name == "" {
+		return fmt.Errorf("网盘类型不能为空")
+	}
+	if p.Name == OthersType {
+		return fmt.Errorf("网盘类型不能为%s", OthersType)
+	}
+	if p.DisplayName == "" {
+		p.DisplayName = p.Name
+	}
+
+	hosts := make([]string, 0, len(p.Hosts))
+	for _, host := range p.Hosts {
+		host = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "www.")
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	p.Hosts = hosts
+
+	if len(p.Hosts) > 0 {
+		if p.parse == nil {
+			patterns := p.SharePatterns
+			if len(patterns) == 0 {
+				patterns = []string{defaultSharePattern}
+			}
+			p.sharePatterns = make([]*regexp.Regexp, 0, len(patterns))
+			for _, pattern := range patterns {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return fmt.Errorf("网盘%s的分享ID正则无效: %v", p.Name, err)
+				}
+				if re.NumSubexp() < 1 {
+					return fmt.Errorf("网盘%s的分享ID正则缺少分组: %s", p.Name, pattern)
+				}
+				p.sharePatterns = append(p.sharePatterns, re)
+			}
+		}
+
+		if p.CanonicalURL == "" {
+			p.CanonicalURL = "https://" + p.Hosts[0] + "/s/{id}"
+		}
+
+		escaped := make([]string, len(p.Hosts))
+		for i, host := range p.Hosts {
+			escaped[i] = regexp.QuoteMeta(host)
+		}
+		p.linkPattern = regexp.MustCompile(`(?i)https?://(?:[\w-]+\.)*(?:` + strings.Join(escaped, "|") + `)(?:/[\w\-.~:/?#@!$&*+,;=%]*)?`)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	for i, existing := range registry.providers {
+		if existing.Name == p.Name {
+			registry.providers[i] = &p
+			return nil
+		}
+	}
+	registry.providers = append(registry.providers, &p)
+	return nil
+}
+
+// LoadFile 从JSON配置文件加载网盘定义，文件内容为Provider数组，返回加载的数量
+func LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取网盘配置文件失败: %v", err)
+	}
+
+	var providers []Provider
+	if err := jsonutil.Unmarshal(data, &providers); err != nil {
+		return 0, fmt.Errorf("解析网盘配置文件失败: %v", err)
+	}
+
+	for i, p := range providers {
+		if err := Register(p); err != nil {
+			return i, err
+		}
+	}
+	return len(providers), nil
+}
+
+// Get 根据网盘类型或显示名称获取网盘定义，不区分大小写
+func Get(name string) (*Provider, bool) {
+	name = strings.TrimSpace(name)
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, p := range registry.providers {
+		if strings.EqualFold(p.Name, name) || strings.EqualFold(p.DisplayName, name) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// All 返回全部网盘定义，按注册顺序排列
+func All() []*Provider {
+	registry.RLock()
+	defer registry.RUnlock()
+	providers := make([]*Provider, len(registry.providers))
+	copy(providers, registry.providers)
+	return providers
+}
+
+// Names 返回全部网盘类型
+func Names() []string {
+	providers := All()
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// MatchHost 根据域名查找网盘，支持子域名和www.前缀，多个网盘匹配时取域名最长的
+func MatchHost(host string) *Provider {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	if index := strings.LastIndex(host, ":"); index >= 0 {
+		host = host[:index]
+	}
+
+	var matched *Provider
+	matchedLen := 0
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, p := range registry.providers {
+		for _, candidate := range p.Hosts {
+			if (host == candidate || strings.HasSuffix(host, "."+candidate)) && len(candidate) > matchedLen {
+				matched = p
+				matchedLen = len(candidate)
+			}
+		}
+	}
+	return matched
+}
+
+// Detect 识别文本中链接所属的网盘
+// 优先按URL域名识别；文本无法解析为URL时，取文本中最先出现的网盘域名
+func Detect(text string) *Provider {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return nil
+	}
+
+	if u, ok := ParseURL(text); ok {
+		if p := MatchHost(u.Host); p != nil {
+			return p
+		}
+	}
+
+	var matched *Provider
+	matchedPos := -1
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, p := range registry.providers {
+		for _, host := range p.Hosts {
+			if pos := strings.Index(text, host); pos >= 0 && (matchedPos < 0 || pos < matchedPos) {
+				matched = p
+				matchedPos = pos
+			}
+		}
+	}
+	return matched
+}
+
+// ParseURL 解析URL，缺少协议头时补全https
+func ParseURL(raw string) (*url.URL, bool) {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return nil, false
+	}
+	return parsed, true
+}
+
+// Parse 从已解析的URL和原始链接中提取分享ID和链接自带的提取码
+func (p *Provider) Parse(u *url.URL, raw string) (shareID, password string) {
+	if p.parse != nil {
+		return p.parse(u, raw)
+	}
+
+	target := u.Path + "?" + u.RawQuery + "#" + u.Fragment
+	for _, re := range p.sharePatterns {
+		if m := re.FindStringSubmatch(target); m != nil && m[1] != "" {
+			shareID = m[1]
+			break
+		}
+	}
+	if shareID == "" {
+		return "", ""
+	}
+
+	params := p.PasswordParams
+	if len(params) == 0 {
+		params = []string{"pwd"}
+	}
+	password = queryPassword(u, params...)
+	if password == "" && p.InlinePassword {
+		password = inlinePassword(raw)
+	}
+	return shareID, password
+}
+
+// Canonical 根据分享ID和提取码生成规范URL
+func (p *Provider) Canonical(shareID, password string) string {
+	canonical := strings.ReplaceAll(p.CanonicalURL, "{id}", shareID)
+	if password == "" || p.CanonicalPasswordParam == "" {
+		return canonical
+	}
+	separator := "?"
+	if strings.Contains(canonical, "?") {
+		separator = "&"
+	}
+	return canonical + separator + p.CanonicalPasswordParam + "=" + url.QueryEscape(password)
+}
+
+// FindLinks 从文本中提取属于该网盘且能解析出分享ID的链接
+func (p *Provider) FindLinks(text string) []string {
+	if p.linkPattern == nil {
+		return nil
+	}
+
+	var links []string
+	for _, match := range p.linkPattern.FindAllString(text, -1) {
+		match = strings.TrimRight(match, ".,;:!?")
+		if u, ok := ParseURL(match); ok {
+			if shareID, _ := p.Parse(u, match); shareID != "" {
+				links = append(links, match)
+			}
+		}
+	}
+	return links
+}
+
+// queryPassword 从查询参数和锚点中读取提取码
+func queryPassword(u *url.URL, params ...string) string {
+	query := u.Query()
+	var fragment url.Values
+	if strings.Contains(u.Fragment, "=") {
+		fragment, _ = url.ParseQuery(u.Fragment)
+	}
+
+	for _, param := range params {
+		if password := query.Get(param); password != "" {
+			return password
+		}
+		if password := fragment.Get(param); password != "" {
+			return password
+		}
+	}
+	return ""
+}
+
+// inlinePassword 提取链接中以“提取码:xxxx”“（访问码：xxxx）”形式附带的提取码
+func inlinePassword(raw string) string {
+	if decoded, err := url.QueryUnescape(raw); err == nil {
+		raw = decoded
+	}
+	if m := inlinePasswordRegexp.FindStringSubmatch(raw); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -1,25 +1,14 @@
 package util
 
 import (
-	"net/url"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"pansou/model"
+	"pansou/util/linkid"
 )
 
-// normalizeUrl 标准化URL，将URL编码的中文部分解码为中文，用于去重
-func normalizeUrl(rawUrl string) string {
-	// 解码URL中的编码字符
-	decoded, err := url.QueryUnescape(rawUrl)
-	if err != nil {
-		// 如果解码失败，返回原始URL
-		return rawUrl
-	}
-	return decoded
-}
-
 // isSupportedLink 检查链接是否为支持的网盘链接
 func isSupportedLink(url string) bool {
 	lowerURL := strings.ToLower(url)
@@ -70,7 +59,13 @@ func isSupportedLink(url string) bool {
 	}
 
 	// 使用通用模式检查其他网盘链接
-	return AllPanLinksPattern.MatchString(lowerURL)
+	if AllPanLinksPattern.MatchString(lowerURL) {
+		return true
+	}
+
+	// 检查是否为网盘注册表中的其他网盘链接
+	_, ok := linkid.Parse(url)
+	return ok
 }
 
 // normalizeBaiduPanURL 标准化百度网盘URL，确保链接格式正确并且包含密码参数
@@ -292,10 +287,10 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 					}
 				} else {
 					// 非特殊处理的网盘链接直接添加
-					// 使用标准化的URL进行去重
-					normalizedHref := normalizeUrl(href)
-					if !foundLinks[normalizedHref] {
-						foundLinks[normalizedHref] = true
+					// 使用规范化的链接标识进行去重，仅域名、参数顺序不同的链接视为同一个
+					normalizedHref := linkid.Unescape(href)
+					if dedupKey := linkid.DedupKey(href); !foundLinks[dedupKey] {
+						foundLinks[dedupKey] = true
 						links = append(links, model.Link{
 							Type:     linkType,
 							URL:      normalizedHref, // 使用标准化的URL
@@ -390,10 +385,10 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 				}
 			} else {
 				// 非特殊处理的网盘链接直接添加
-				// 使用标准化的URL进行去重
-				normalizedLinkURL := normalizeUrl(linkURL)
-				if !foundLinks[normalizedLinkURL] {
-					foundLinks[normalizedLinkURL] = true
+				// 使用规范化的链接标识进行去重，仅域名、参数顺序不同的链接视为同一个
+				normalizedLinkURL := linkid.Unescape(linkURL)
+				if dedupKey := linkid.DedupKey(linkURL); !foundLinks[dedupKey] {
+					foundLinks[dedupKey] = true
 					links = append(links, model.Link{
 						Type:     linkType,
 						URL:      normalizedLinkURL, // 使用标准化的URL
@@ -408,8 +403,8 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 			normalizedURL := normalizeBaiduPanURL(baseURL, password)
 
 			// 确保链接不重复
-			if !foundLinks[normalizedURL] {
-				foundLinks[normalizedURL] = true
+			if dedupKey := linkid.DedupKey(normalizedURL); !foundLinks[dedupKey] {
+				foundLinks[dedupKey] = true
 				links = append(links, model.Link{
 					Type:     "baidu",
 					URL:      normalizedURL,
@@ -423,8 +418,8 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 			normalizedURL := normalizeTianyiPanURL(baseURL, password)
 
 			// 确保链接不重复
-			if !foundLinks[normalizedURL] {
-				foundLinks[normalizedURL] = true
+			if dedupKey := linkid.DedupKey(normalizedURL); !foundLinks[dedupKey] {
+				foundLinks[dedupKey] = true
 				links = append(links, model.Link{
 					Type:     "tianyi",
 					URL:      normalizedURL,
@@ -438,8 +433,8 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 			normalizedURL := normalizeUCPanURL(baseURL, password)
 
 			// 确保链接不重复
-			if !foundLinks[normalizedURL] {
-				foundLinks[normalizedURL] = true
+			if dedupKey := linkid.DedupKey(normalizedURL); !foundLinks[dedupKey] {
+				foundLinks[dedupKey] = true
 				links = append(links, model.Link{
 					Type:     "uc",
 					URL:      normalizedURL,
@@ -453,8 +448,8 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 			normalizedURL := normalize123PanURL(baseURL, password)
 
 			// 确保链接不重复
-			if !foundLinks[normalizedURL] {
-				foundLinks[normalizedURL] = true
+			if dedupKey := linkid.DedupKey(normalizedURL); !foundLinks[dedupKey] {
+				foundLinks[dedupKey] = true
 				links = append(links, model.Link{
 					Type:     "123",
 					URL:      normalizedURL,
@@ -468,8 +463,8 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 			normalizedURL := normalize115PanURL(baseURL, password)
 
 			// 确保链接不重复
-			if !foundLinks[normalizedURL] {
-				foundLinks[normalizedURL] = true
+			if dedupKey := linkid.DedupKey(normalizedURL); !foundLinks[dedupKey] {
+				foundLinks[dedupKey] = true
 				links = append(links, model.Link{
 					Type:     "115",
 					URL:      normalizedURL,
@@ -483,8 +478,8 @@ func ParseSearchResults(html string, channel string) ([]model.SearchResult, stri
 			normalizedURL := CleanAliyunPanURL(baseURL) // 阿里云盘URL通常不包含密码参数
 
 			// 确保链接不重复
-			if !foundLinks[normalizedURL] {
-				foundLinks[normalizedURL] = true
+			if dedupKey := linkid.DedupKey(normalizedURL); !foundLinks[dedupKey] {
+				foundLinks[dedupKey] = true
 				links = append(links, model.Link{
 					Type:     "aliyun",
 					URL:      normalizedURL,
@@ -778,16 +773,14 @@ func extractWorkTitlesFromSingleLineFormat(links []model.Link, lines []string, d
 
 		// 如果成功提取了作品名和链接，添加到映射
 		if workTitle != "" && linkURL != "" {
-			// 标准化URL用于匹配
-			normalizedURL := normalizeUrl(linkURL)
-			urlToWorkTitle[normalizedURL] = workTitle
+			// 使用规范化的链接标识匹配
+			urlToWorkTitle[linkid.DedupKey(linkURL)] = workTitle
 		}
 	}
 
 	// 为每个链接设置作品标题
 	for i := range links {
-		normalizedURL := normalizeUrl(links[i].URL)
-		if workTitle, found := urlToWorkTitle[normalizedURL]; found {
+		if workTitle, found := urlToWorkTitle[linkid.DedupKey(links[i].URL)]; found {
 			links[i].WorkTitle = workTitle
 		} else {
 			links[i].WorkTitle = defaultTitle
@@ -0,0 +1,259 @@
+package peer
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pansou/model"
+	"pansou/util/json"
+)
+
+// 搜索来源
+const (
+	SourceTG     = "tg"     // TG频道搜索
+	SourcePlugin = "plugin" // 插件搜索
+)
+
+const (
+	// FillPath 实例之间请求搜索结果的接口路径
+	FillPath = "/api/peer/fill"
+
+	// TokenHeader 实例之间请求携带令牌的请求头
+	TokenHeader = "X-Peer-Token"
+)
+
+// Request 向缓存键所属实例请求搜索结果的参数
+type Request struct {
+	Key         string                 `json:"key"`    // 缓存键，决定所属实例
+	Source      string                 `json:"source"` // tg或plugin
+	Keyword     string                 `json:"keyword"`
+	Channels    []string               `json:"channels,omitempty"`
+	Plugins     []string               `json:"plugins,omitempty"`
+	Concurrency int                    `json:"concurrency,omitempty"`
+	Ext         map[string]interface{} `json:"ext,omitempty"`
+	Refresh     bool                   `json:"refresh,omitempty"` // 强制刷新，所属实例跳过缓存重新搜索
+}
+
+// Response 所属实例返回的搜索结果
+type Response struct {
+	Results []model.SearchResult `json:"results"`
+}
+
+// Handler 在本实例执行其他实例请求的搜索（先查本实例缓存），不再转发
+type Handler func(req Request) ([]model.SearchResult, error)
+
+// call 进行中的本地搜索，同一缓存键的并发请求共享结果
+type call struct {
+	done    chan struct{}
+	results []model.SearchResult
+	err     error
+}
+
+// Group 静态实例组
+// 每个缓存键按一致性哈希确定所属实例，其他实例缓存未命中时向所属实例请求，只有所属实例执行实际搜索，
+// 所属实例上同一缓存键的并发搜索只执行一次
+type Group struct {
+	self    string
+	peers   []string
+	token   string
+	ring    *hashRing
+	client  *http.Client
+	handler Handler
+
+	mutex sync.Mutex
+	calls map[string]*call
+
+	forwarded       int64 // 从所属实例获取结果的次数
+	forwardFailures int64 // 请求所属实例失败、改为本实例搜索的次数
+	served          int64 // 为其他实例执行搜索的次数
+	localFills      int64 // 本实例执行实际搜索的次数
+	collapsed       int64 // 合并到进行中搜索的次数
+}
+
+// NewGroup 创建实例组，peers为组内所有实例的地址（如http://10.0.0.1:8888），self为本实例在其中的地址
+// 所有实例需使用相同的地址列表，timeout为等待所属实例返回结果的超时时间
+func NewGroup(self string, peers []string, token string, timeout time.Duration, handler Handler) *Group {
+	self = normalizePeer(self)
+	seen := map[string]bool{self: true}
+	members := []string{self}
+	for _, p := range peers {
+		if p = normalizePeer(p); p != "" && !seen[p] {
+			seen[p] = true
+			members = append(members, p)
+		}
+	}
+
+	return &Group{
+		self:    self,
+		peers:   members,
+		token:   token,
+		ring:    newHashRing(members),
+		client:  &http.Client{Timeout: timeout},
+		handler: handler,
+		calls:   make(map[string]*call),
+	}
+}
+
+// normalizePeer 统一实例地址格式，去掉空白和结尾的斜杠
+func normalizePeer(peer string) string {
+	return strings.TrimRight(strings.TrimSpace(peer), "/")
+}
+
+// Self 获取本实例地址
+func (g *Group) Self() string {
+	return g.self
+}
+
+// Peers 获取组内所有实例的地址
+func (g *Group) Peers() []string {
+	return g.peers
+}
+
+// Owner 获取缓存键所属的实例地址
+func (g *Group) Owner(key string) string {
+	return g.ring.owner(key)
+}
+
+// Fill 获取缓存未命中时的搜索结果：缓存键属于其他实例时向其请求，属于本实例或请求失败时执行local
+// fromPeer表示结果来自所属实例，这些结果由所属实例缓存，本实例最多在内存中短暂保存
+func (g *Group) Fill(req Request, local func() ([]model.SearchResult, error)) (results []model.SearchResult, fromPeer bool, err error) {
+	if owner := g.Owner(req.Key); owner != g.self {
+		results, err := g.fetch(owner, req)
+		if err == nil {
+			atomic.AddInt64(&g.forwarded, 1)
+			return results, true, nil
+		}
+		atomic.AddInt64(&g.forwardFailures, 1)
+		fmt.Printf("[实例组] 向 %s 请求搜索结果失败，改为本实例搜索: %v\n", owner, err)
+	}
+
+	results, err = g.Do(req.Key, local)
+	return results, false, err
+}
+
+// Do 执行本地搜索，同一缓存键同时只执行一次，并发的调用等待并共享结果
+func (g *Group) Do(key string, fn func() ([]model.SearchResult, error)) ([]model.SearchResult, error) {
+	g.mutex.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mutex.Unlock()
+		atomic.AddInt64(&g.collapsed, 1)
+		<-c.done
+		// 返回副本，调用方会对结果排序和修改
+		return append([]model.SearchResult(nil), c.results...), c.err
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mutex.Unlock()
+
+	atomic.AddInt64(&g.localFills, 1)
+	c.results, c.err = fn()
+
+	g.mutex.Lock()
+	delete(g.calls, key)
+	g.mutex.Unlock()
+	close(c.done)
+
+	return append([]model.SearchResult(nil), c.results...), c.err
+}
+
+// fetch 向所属实例请求搜索结果
+func (g *Group) fetch(owner string, req Request) ([]model.SearchResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, owner+FillPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if g.token != "" {
+		httpReq.Header.Set(TokenHeader, g.token)
+	}
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Code    int      `json:"code"`
+		Message string   `json:"message"`
+		Data    Response `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败(HTTP %d): %v", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Code != 0 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, result.Message)
+	}
+	return result.Data.Results, nil
+}
+
+// ServeHTTP 处理其他实例的搜索结果请求
+func (g *Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if g.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(TokenHeader)), []byte(g.token)) != 1 {
+		writeResponse(w, http.StatusForbidden, model.NewErrorResponse(http.StatusForbidden, "实例令牌无效"))
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	var req Request
+	if err == nil {
+		err = json.Unmarshal(data, &req)
+	}
+	if err != nil || req.Key == "" || req.Keyword == "" {
+		writeResponse(w, http.StatusBadRequest, model.NewErrorResponse(http.StatusBadRequest, "无效的请求参数"))
+		return
+	}
+
+	atomic.AddInt64(&g.served, 1)
+	results, err := g.handler(req)
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, model.NewErrorResponse(http.StatusInternalServerError, "搜索失败: "+err.Error()))
+		return
+	}
+	if results == nil {
+		results = []model.SearchResult{}
+	}
+	writeResponse(w, http.StatusOK, model.NewSuccessResponse(Response{Results: results}))
+}
+
+// writeResponse 写入JSON响应
+func writeResponse(w http.ResponseWriter, status int, resp model.Response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data = []byte(`{"code":500,"message":"响应序列化失败"}`)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// Stats 获取实例组统计信息
+func (g *Group) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"self":             g.self,
+		"peers":            g.peers,
+		"forwarded":        atomic.LoadInt64(&g.forwarded),
+		"forward_failures": atomic.LoadInt64(&g.forwardFailures),
+		"served":           atomic.LoadInt64(&g.served),
+		"local_fills":      atomic.LoadInt64(&g.localFills),
+		"collapsed":        atomic.LoadInt64(&g.collapsed),
+	}
+}
@@ -0,0 +1,174 @@
+package peer
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pansou/model"
+)
+
+// testNode 进程内的一个实例，searches记录实际执行搜索的次数
+type testNode struct {
+	group    *Group
+	server   *httptest.Server
+	searches int64
+	gate     chan struct{} // 不为nil时实际搜索等待其关闭后返回
+}
+
+// search 模拟一次实际搜索，结果标明执行搜索的实例
+func (n *testNode) search(keyword string) func() ([]model.SearchResult, error) {
+	return func() ([]model.SearchResult, error) {
+		atomic.AddInt64(&n.searches, 1)
+		if n.gate != nil {
+			<-n.gate
+		}
+		return []model.SearchResult{{Title: keyword, Channel: n.group.Self()}}, nil
+	}
+}
+
+// newTestNodes 启动n个组成实例组的进程内服务，tokens为各实例使用的令牌
+func newTestNodes(t *testing.T, tokens ...string) []*testNode {
+	nodes := make([]*testNode, len(tokens))
+	peers := make([]string, len(tokens))
+	for i := range nodes {
+		nodes[i] = &testNode{server: httptest.NewUnstartedServer(nil)}
+		peers[i] = "http://" + nodes[i].server.Listener.Addr().String()
+	}
+
+	for i, node := range nodes {
+		node := node
+		// 与搜索服务相同：为其他实例执行的搜索只在本实例执行，不再转发
+		node.group = NewGroup(peers[i], peers, tokens[i], 3*time.Second, func(req Request) ([]model.SearchResult, error) {
+			return node.group.Do(req.Key, node.search(req.Keyword))
+		})
+		node.server.Config.Handler = node.group
+		node.server.Start()
+		t.Cleanup(node.server.Close)
+	}
+	return nodes
+}
+
+// keyOwnedBy 找到一个属于指定实例的缓存键
+func keyOwnedBy(t *testing.T, nodes []*testNode, owner int) string {
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if nodes[0].group.Owner(key) == nodes[owner].group.Self() {
+			return key
+		}
+	}
+	t.Fatalf("没有找到属于实例%d的缓存键", owner)
+	return ""
+}
+
+func TestGroupOwnerAgreement(t *testing.T) {
+	nodes := newTestNodes(t, "", "", "")
+
+	owned := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		owner := nodes[0].group.Owner(key)
+		for _, node := range nodes[1:] {
+			if got := node.group.Owner(key); got != owner {
+				t.Fatalf("缓存键%s的所属实例不一致: %s != %s", key, got, owner)
+			}
+		}
+		owned[owner]++
+	}
+
+	for _, node := range nodes {
+		if owned[node.group.Self()] == 0 {
+			t.Fatalf("实例%s没有分配到缓存键: %v", node.group.Self(), owned)
+		}
+	}
+}
+
+func TestGroupFillOnlyOwnerSearches(t *testing.T) {
+	nodes := newTestNodes(t, "secret", "secret", "secret")
+	key := keyOwnedBy(t, nodes, 1)
+	req := Request{Key: key, Source: SourcePlugin, Keyword: "流浪地球"}
+	nodes[1].gate = make(chan struct{})
+
+	// 三个实例同时各收到三次相同的搜索
+	var wg sync.WaitGroup
+	var fromPeerCount int64
+	for _, node := range nodes {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(node *testNode) {
+				defer wg.Done()
+				results, fromPeer, err := node.group.Fill(req, node.search(req.Keyword))
+				if err != nil {
+					t.Errorf("Fill失败: %v", err)
+					return
+				}
+				if len(results) != 1 || results[0].Channel != nodes[1].group.Self() {
+					t.Errorf("结果不是来自所属实例: %+v", results)
+				}
+				if fromPeer {
+					atomic.AddInt64(&fromPeerCount, 1)
+				}
+			}(node)
+		}
+	}
+
+	// 所属实例的3次本地请求和6次转发请求全部合并到同一次搜索后再返回结果
+	deadline := time.Now().Add(3 * time.Second)
+	for nodes[1].group.Stats()["collapsed"].(int64) < 8 {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待请求合并超时: %v", nodes[1].group.Stats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(nodes[1].gate)
+	wg.Wait()
+
+	for i, node := range nodes {
+		want := int64(0)
+		if i == 1 {
+			want = 1
+		}
+		if got := atomic.LoadInt64(&node.searches); got != want {
+			t.Fatalf("实例%d执行搜索%d次，期望%d次", i, got, want)
+		}
+	}
+	if fromPeerCount != 6 {
+		t.Fatalf("来自所属实例的结果数 = %d，期望6", fromPeerCount)
+	}
+	if served := nodes[1].group.Stats()["served"].(int64); served != 6 {
+		t.Fatalf("所属实例处理请求%d次，期望6次", served)
+	}
+}
+
+func TestGroupFillFallsBackWhenOwnerUnavailable(t *testing.T) {
+	nodes := newTestNodes(t, "", "", "")
+	key := keyOwnedBy(t, nodes, 2)
+	nodes[2].server.Close()
+
+	results, fromPeer, err := nodes[0].group.Fill(Request{Key: key, Keyword: "kw"}, nodes[0].search("kw"))
+	if err != nil || fromPeer {
+		t.Fatalf("Fill = %v, %v，期望本实例搜索", fromPeer, err)
+	}
+	if len(results) != 1 || results[0].Channel != nodes[0].group.Self() {
+		t.Fatalf("结果不是来自本实例: %+v", results)
+	}
+	if failures := nodes[0].group.Stats()["forward_failures"].(int64); failures != 1 {
+		t.Fatalf("请求失败次数 = %d，期望1", failures)
+	}
+}
+
+func TestGroupRejectsInvalidToken(t *testing.T) {
+	nodes := newTestNodes(t, "right", "wrong")
+	key := keyOwnedBy(t, nodes, 0)
+
+	_, fromPeer, _ := nodes[1].group.Fill(Request{Key: key, Keyword: "kw"}, nodes[1].search("kw"))
+	if fromPeer {
+		t.Fatalf("令牌错误时不应从所属实例获取结果")
+	}
+	if nodes[0].searches != 0 || nodes[1].searches != 1 {
+		t.Fatalf("搜索次数 = %d, %d，期望0, 1", nodes[0].searches, nodes[1].searches)
+	}
+}
@@ -0,0 +1,48 @@
+package peer
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// ringReplicas 每个实例在哈希环上的虚拟节点数，使缓存键在实例间分布均匀
+const ringReplicas = 160
+
+// hashRing 一致性哈希环，所有实例使用相同的实例列表时对每个缓存键得到相同的所属实例
+type hashRing struct {
+	hashes []uint32
+	owners map[uint32]string
+}
+
+// newHashRing 根据实例列表创建哈希环，实例顺序不影响结果
+func newHashRing(peers []string) *hashRing {
+	ring := &hashRing{owners: make(map[uint32]string)}
+	for _, peer := range peers {
+		for i := 0; i < ringReplicas; i++ {
+			hash := crc32.ChecksumIEEE([]byte(peer + "#" + strconv.Itoa(i)))
+			// 哈希冲突时取字典序较小的实例，保证各实例结果一致
+			if existing, ok := ring.owners[hash]; ok && existing < peer {
+				continue
+			} else if !ok {
+				ring.hashes = append(ring.hashes, hash)
+			}
+			ring.owners[hash] = peer
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	return ring
+}
+
+// owner 获取缓存键所属的实例
+func (r *hashRing) owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
@@ -4,6 +4,9 @@ import (
 	netUrl "net/url"
 	"regexp"
 	"strings"
+
+	"pansou/util/linkid"
+	"pansou/util/netdisk"
 )
 
 // 通用网盘链接匹配正则表达式 - 修改为更精确的匹配模式
@@ -65,42 +68,9 @@ func GetLinkType(url string) string {
 		return "magnet"
 	}
 
-	if strings.Contains(url, "pan.baidu.com") {
-		return "baidu"
-	}
-	if strings.Contains(url, "pan.quark.cn") {
-		return "quark"
-	}
-	if strings.Contains(url, "alipan.com") || strings.Contains(url, "aliyundrive.com") {
-		return "aliyun"
-	}
-	if strings.Contains(url, "guangyapan.com") {
-		return "guangya"
-	}
-	if strings.Contains(url, "cloud.189.cn") {
-		return "tianyi"
-	}
-	if strings.Contains(url, "drive.uc.cn") {
-		return "uc"
-	}
-	if strings.Contains(url, "caiyun.139.com") || strings.Contains(url, "yun.139.com") || strings.Contains(url, "caiyun.feixin.10086.cn") {
-		return "mobile"
-	}
-	if strings.Contains(url, "115.com") || strings.Contains(url, "115cdn.com") || strings.Contains(url, "anxia.com") {
-		return "115"
-	}
-	if strings.Contains(url, "mypikpak.com") {
-		return "pikpak"
-	}
-	if strings.Contains(url, "pan.xunlei.com") {
-		return "xunlei"
-	}
-
-	// 123网盘有多个域名
-	if strings.Contains(url, "123684.com") || strings.Contains(url, "123685.com") || strings.Contains(url, "123865.com") ||
-		strings.Contains(url, "123912.com") || strings.Contains(url, "123pan.com") ||
-		strings.Contains(url, "123pan.cn") || strings.Contains(url, "123592.com") {
-		return "123"
+	// 其他链接按网盘注册表中的域名识别
+	if provider := netdisk.Detect(url); provider != nil {
+		return provider.Name
 	}
 
 	return "others"
@@ -868,6 +838,12 @@ func ExtractNetDiskLinks(text string) []string {
 		}
 	}
 
+	// 已提取链接的去重键，同一分享的不同写法只保留一个
+	seen := make(map[string]bool, len(links))
+	for _, link := range links {
+		seen[normalizeURLForComparison(link)] = true
+	}
+
 	// 使用通用模式提取其他可能的链接
 	otherLinks := AllPanLinksPattern.FindAllString(text, -1)
 	if otherLinks != nil {
@@ -878,61 +854,54 @@ func ExtractNetDiskLinks(text string) []string {
 			if strings.HasSuffix(cleanURL, "https") {
 				cleanURL = cleanURL[:len(cleanURL)-5]
 			}
-			// 跳过百度、夸克、迅雷、天翼、UC和123网盘链接，因为已经单独处理过
-			if strings.Contains(cleanURL, "pan.baidu.com") ||
-				strings.Contains(cleanURL, "pan.quark.cn") ||
-				strings.Contains(cleanURL, "pan.xunlei.com") ||
-				strings.Contains(cleanURL, "guangyapan.com") ||
-				strings.Contains(cleanURL, "cloud.189.cn") ||
-				strings.Contains(cleanURL, "drive.uc.cn") ||
-				strings.Contains(cleanURL, "yun.139.com") ||
-				strings.Contains(cleanURL, "caiyun.139.com") ||
-				strings.Contains(cleanURL, "caiyun.feixin.10086.cn") ||
-				strings.Contains(cleanURL, "123684.com") ||
-				strings.Contains(cleanURL, "123685.com") ||
-				strings.Contains(cleanURL, "123865.com") ||
-				strings.Contains(cleanURL, "123912.com") ||
-				strings.Contains(cleanURL, "123pan.com") ||
-				strings.Contains(cleanURL, "123pan.cn") ||
-				strings.Contains(cleanURL, "123592.com") {
+			// 跳过已经由专用正则单独处理过的网盘链接
+			if dedicatedLinkTypes[GetLinkType(cleanURL)] {
 				continue
 			}
 
-			isDuplicate := false
-			for _, existingLink := range links {
-				normalizedExisting := normalizeURLForComparison(existingLink)
-				normalizedNew := normalizeURLForComparison(cleanURL)
-
-				// 使用完整URL比较，包括www.前缀
-				if normalizedExisting == normalizedNew ||
-					strings.Contains(normalizedExisting, normalizedNew) ||
-					strings.Contains(normalizedNew, normalizedExisting) {
-					isDuplicate = true
-					break
-				}
+			// 按去重键整体比较，分享ID互为前缀的不同分享不会被误判为重复
+			key := normalizeURLForComparison(cleanURL)
+			if seen[key] {
+				continue
 			}
+			seen[key] = true
+			links = append(links, cleanURL)
+		}
+	}
 
-			if !isDuplicate {
-				links = append(links, cleanURL)
+	// 按网盘注册表提取其他网盘（包括配置文件中新增的网盘）的链接
+	for _, provider := range netdisk.All() {
+		if dedicatedLinkTypes[provider.Name] {
+			continue
+		}
+		for _, cleanURL := range provider.FindLinks(text) {
+			// 按去重键整体比较，分享ID互为前缀的不同分享不会被误判为重复
+			key := normalizeURLForComparison(cleanURL)
+			if seen[key] {
+				continue
 			}
+			seen[key] = true
+			links = append(links, cleanURL)
 		}
 	}
 
 	return links
 }
 
+// dedicatedLinkTypes 由ExtractNetDiskLinks中专用正则提取的网盘类型，通用提取时跳过
+var dedicatedLinkTypes = map[string]bool{
+	"baidu":   true,
+	"quark":   true,
+	"xunlei":  true,
+	"guangya": true,
+	"tianyi":  true,
+	"uc":      true,
+	"mobile":  true,
+	"123":     true,
+}
+
 // normalizeURLForComparison 标准化URL以便于比较
-// 移除协议头，标准化提取码，保留完整域名用于比较
+// 受支持的网盘链接按网盘类型和分享ID比较，域名别名、参数顺序和提取码写法不影响结果
 func normalizeURLForComparison(url string) string {
-	// 移除协议头
-	if idx := strings.Index(url, "://"); idx >= 0 {
-		url = url[idx+3:]
-	}
-
-	// 标准化URL编码的提取码，统一使用非编码形式
-	if strings.Contains(url, "%E6%8F%90%E5%8F%96%E7%A0%81") {
-		url = strings.Replace(url, "%E6%8F%90%E5%8F%96%E7%A0%81", "提取码", 1)
-	}
-
-	return url
+	return linkid.DedupKey(url)
 }
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractNetDiskLinksDistinctShares(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "微云分享ID互为前缀",
+			text: "第一部 https://share.weiyun.com/abc12 第二部 https://share.weiyun.com/abc123",
+			want: []string{"https://share.weiyun.com/abc12", "https://share.weiyun.com/abc123"},
+		},
+		{
+			name: "蓝奏云分享ID互为前缀",
+			text: "上 https://www.lanzoui.com/iab12 下 https://www.lanzoui.com/iab123",
+			want: []string{"https://www.lanzoui.com/iab12", "https://www.lanzoui.com/iab123"},
+		},
+		{
+			name: "同一分享只保留一个",
+			text: "https://share.weiyun.com/abc12 备用 https://share.weiyun.com/abc12",
+			want: []string{"https://share.weiyun.com/abc12"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractNetDiskLinks(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractNetDiskLinks(%q) = %q，期望 %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"pansou/model"
+)
+
+// 资源信息提取相关的正则表达式
+// Go正则不支持环视，边界统一用“非字母数字字符或行首行尾”表示
+var (
+	// 分辨率：2160p、1080P、1080i、720p等
+	resolutionPPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(4320|2160|1440|1080|720|576|480)[pi](?:[^a-z0-9]|$)`)
+	// 分辨率别名：8K、4K、UHD、2K、FHD
+	resolutionAliasPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(8k|4k|uhd|2k|fhd)(?:[^a-z0-9]|$)`)
+	// 分辨率尺寸写法：3840x2160、1920×1080
+	resolutionDimPattern = regexp.MustCompile(`(?i)(?:^|[^0-9])(?:3840|4096|2560|1920|1280)\s*[x×*]\s*(2160|1440|1080|720)(?:[^0-9]|$)`)
+
+	// HDR：HDR、HDR10、HDR10+、HLG
+	hdrPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(hdr(?:10\+?)?|hlg)(?:[^a-z0-9]|$)`)
+	// 杜比视界：Dolby Vision、DoVi、DV、杜比视界
+	dolbyVisionPattern = regexp.MustCompile(`(?i)(?:杜比视界|dolby[\s.]?vision|(?:^|[^a-z0-9])(?:dovi|dv)(?:[^a-z0-9]|$))`)
+
+	// 视频编码
+	codecH265Pattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:h\.?265|x265|hevc)(?:[^a-z0-9]|$)`)
+	codecH264Pattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(?:h\.?264|x264|avc)(?:[^a-z0-9]|$)`)
+	codecAV1Pattern  = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])av1(?:[^a-z0-9]|$)`)
+	codecVP9Pattern  = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])vp9(?:[^a-z0-9]|$)`)
+
+	// 季集：S01E01、S01E01-E10、S01E01-10、S01-S03、S01
+	seasonEpisodePattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])s(\d{1,2})(?:\s*-\s*s?(\d{1,2}))?(?:e(\d{1,4})(?:\s*-\s*e?(\d{1,4}))?)?(?:[^a-z0-9]|$)`)
+	// 单独的集数：EP01、E01-E12、EP01-12
+	episodeOnlyPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])ep?(\d{1,4})(?:\s*-\s*(?:ep?)?(\d{1,4}))?(?:[^a-z0-9]|$)`)
+	// 中文季：第一季、第1季、第1-3季、第一至三季
+	cnSeasonPattern = regexp.MustCompile(`第\s*([0-9零一二两三四五六七八九十]+)\s*(?:[-~至到]\s*(?:第\s*)?([0-9零一二两三四五六七八九十]+)\s*)?季`)
+	// 中文集：第1集、第1-10集、第01~12集
+	cnEpisodePattern = regexp.MustCompile(`第\s*([0-9零一二两三四五六七八九十百]+)\s*(?:[-~至到]\s*(?:第\s*)?([0-9零一二两三四五六七八九十百]+)\s*)?[集话話期]`)
+	// 全集数：全40集、共30集、40集全
+	cnTotalEpisodePattern = regexp.MustCompile(`(?:[全共]\s*([0-9零一二两三四五六七八九十百]+)\s*[集话話期]|([0-9]+)\s*[集话話期]全)`)
+	// 更新进度：更新至12集、更新至第12集、更至EP12、更新到12
+	updatedToPattern = regexp.MustCompile(`(?i)(?:更新至|更新到|更至|更到|连载至)\s*(?:第\s*)?(?:ep?)?\s*([0-9零一二两三四五六七八九十百]+)`)
+	// 完结标记
+	completedPattern = regexp.MustCompile(`(?i)(?:完结|全集|(?:^|[^a-z])complete(?:[^a-z]|$))`)
+	// 未完结标记
+	notCompletedPattern = regexp.MustCompile(`未完结|未完|连载中`)
+
+	// 年份：优先匹配括号中的年份
+	bracketYearPattern = regexp.MustCompile(`[(（\[【]\s*((?:19[3-9]|20[0-4])\d)\s*(?:年)?\s*[)）\]】]`)
+	digitsPattern      = regexp.MustCompile(`[0-9]+`)
+
+	// 文件大小：12.3GB、800MB、1.2T、45G、3.5GiB
+	sizePattern = regexp.MustCompile(`(?i)(?:^|[^0-9a-z.])(\d+(?:\.\d+)?)\s*(tb|gb|mb|kb|t|g|m)(?:i?b)?(?:[^a-z0-9]|$)`)
+)
+
+// ParseReleaseMeta 从标题或备注文本中提取结构化资源信息，未提取到任何信息时返回nil
+func ParseReleaseMeta(text string) *model.ReleaseMeta {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	meta := &model.ReleaseMeta{
+		Resolution: parseResolution(text),
+		HDR:        hdrPattern.MatchString(text),
+		Codec:      parseCodec(text),
+		Year:       parseYear(text),
+		SizeBytes:  parseSize(text),
+	}
+
+	// 杜比视界有时也被写作“杜比视界HDR”，两者可以同时存在
+	meta.DolbyVision = dolbyVisionPattern.MatchString(text)
+
+	// 更新进度中的集数不作为集数区间，解析季集前先去掉
+	parseSeasonEpisode(updatedToPattern.ReplaceAllString(text, " "), meta)
+	parseProgress(text, meta)
+
+	if *meta == (model.ReleaseMeta{}) {
+		return nil
+	}
+	return meta
+}
+
+// parseResolution 提取分辨率，多个分辨率时取最高值
+func parseResolution(text string) int {
+	best := 0
+	for _, m := range resolutionPPattern.FindAllStringSubmatch(text, -1) {
+		if v, err := strconv.Atoi(m[1]); err == nil && v > best {
+			best = v
+		}
+	}
+	for _, m := range resolutionDimPattern.FindAllStringSubmatch(text, -1) {
+		if v, err := strconv.Atoi(m[1]); err == nil && v > best {
+			best = v
+		}
+	}
+	for _, m := range resolutionAliasPattern.FindAllStringSubmatch(text, -1) {
+		v := 0
+		switch strings.ToLower(m[1]) {
+		case "8k":
+			v = 4320
+		case "4k", "uhd":
+			v = 2160
+		case "2k":
+			v = 1440
+		case "fhd":
+			v = 1080
+		}
+		if v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+// parseCodec 提取视频编码
+func parseCodec(text string) string {
+	switch {
+	case codecH265Pattern.MatchString(text):
+		return "H265"
+	case codecAV1Pattern.MatchString(text):
+		return "AV1"
+	case codecH264Pattern.MatchString(text):
+		return "H264"
+	case codecVP9Pattern.MatchString(text):
+		return "VP9"
+	}
+	return ""
+}
+
+// parseYear 提取年份，优先使用括号内的年份，否则取最后一个候选年份
+// 发布名通常是“标题.年份.分辨率”，标题里的数字（如2049）排在年份之前
+func parseYear(text string) int {
+	if m := bracketYearPattern.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		return year
+	}
+
+	year := 0
+	for _, loc := range digitsPattern.FindAllStringIndex(text, -1) {
+		if loc[1]-loc[0] != 4 {
+			continue
+		}
+		v, _ := strconv.Atoi(text[loc[0]:loc[1]])
+		if v < 1930 || v > 2049 {
+			continue
+		}
+		// 前后紧邻字母或乘号时不是年份（如x1080、1920x1080、S2024）
+		prev, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		next, _ := utf8.DecodeRuneInString(text[loc[1]:])
+		if isYearNeighbourInvalid(prev) || isYearNeighbourInvalid(next) {
+			continue
+		}
+		year = v
+	}
+	return year
+}
+
+// isYearNeighbourInvalid 判断年份前后的字符是否说明该数字不是年份
+func isYearNeighbourInvalid(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '×' || r == '*'
+}
+
+// parseSize 提取文件大小（字节），多个大小时取第一个
+func parseSize(text string) int64 {
+	m := sizePattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || value <= 0 {
+		return 0
+	}
+
+	var unit float64
+	switch strings.ToLower(m[2]) {
+	case "tb", "t":
+		unit = 1 << 40
+	case "gb", "g":
+		unit = 1 << 30
+	case "mb", "m":
+		unit = 1 << 20
+	case "kb":
+		unit = 1 << 10
+	}
+	return int64(value * unit)
+}
+
+// parseSeasonEpisode 提取季和集信息
+func parseSeasonEpisode(text string, meta *model.ReleaseMeta) {
+	if m := seasonEpisodePattern.FindStringSubmatch(text); m != nil {
+		meta.SeasonStart, meta.SeasonEnd = parseRange(m[1], m[2])
+		if m[3] != "" {
+			meta.EpisodeStart, meta.EpisodeEnd = parseRange(m[3], m[4])
+		}
+	}
+
+	if meta.SeasonStart == 0 {
+		if m := cnSeasonPattern.FindStringSubmatch(text); m != nil {
+			meta.SeasonStart, meta.SeasonEnd = parseRange(m[1], m[2])
+		}
+	}
+
+	if meta.EpisodeStart == 0 {
+		if m := cnEpisodePattern.FindStringSubmatch(text); m != nil {
+			meta.EpisodeStart, meta.EpisodeEnd = parseRange(m[1], m[2])
+		} else if m := episodeOnlyPattern.FindStringSubmatch(text); m != nil {
+			meta.EpisodeStart, meta.EpisodeEnd = parseRange(m[1], m[2])
+		}
+	}
+
+	if meta.EpisodeStart == 0 {
+		if m := cnTotalEpisodePattern.FindStringSubmatch(text); m != nil {
+			total := parseChineseNumber(coalesceString(m[1], m[2]))
+			if total > 0 {
+				meta.EpisodeStart, meta.EpisodeEnd = 1, total
+			}
+		}
+	}
+}
+
+// parseProgress 提取完结/更新进度信息
+func parseProgress(text string, meta *model.ReleaseMeta) {
+	if m := updatedToPattern.FindStringSubmatch(text); m != nil {
+		meta.UpdatedTo = parseChineseNumber(m[1])
+	}
+
+	if notCompletedPattern.MatchString(text) {
+		meta.Completed = false
+		return
+	}
+	if completedPattern.MatchString(text) || cnTotalEpisodePattern.MatchString(text) {
+		meta.Completed = true
+	}
+
+	// 有明确更新进度且未出现完结标记时，不认为已完结
+	if meta.UpdatedTo > 0 && !completedPattern.MatchString(text) {
+		meta.Completed = false
+	}
+}
+
+// parseRange 解析起止区间，结束值缺失或小于起始值时视为单值
+func parseRange(startStr, endStr string) (int, int) {
+	start := parseChineseNumber(startStr)
+	end := parseChineseNumber(endStr)
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// parseChineseNumber 解析阿拉伯数字或中文数字（支持到九百九十九）
+func parseChineseNumber(s string) int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	if v, err := strconv.Atoi(s); err == nil {
+		return v
+	}
+
+	digits := map[rune]int{
+		'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+		'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+	}
+
+	total, current := 0, 0
+	for _, r := range s {
+		switch r {
+		case '百':
+			if current == 0 {
+				current = 1
+			}
+			total += current * 100
+			current = 0
+		case '十':
+			if current == 0 {
+				current = 1
+			}
+			total += current * 10
+			current = 0
+		default:
+			d, ok := digits[r]
+			if !ok {
+				return 0
+			}
+			current = d
+		}
+	}
+	return total + current
+}
+
+// coalesceString 返回第一个非空字符串
+func coalesceString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,419 @@
+package util
+
+import (
+	"testing"
+
+	"pansou/model"
+)
+
+// sizeOf 按解析器相同的方式换算大小，避免浮点常量截断
+func sizeOf(value float64, unit int64) int64 {
+	return int64(value * float64(unit))
+}
+
+const (
+	testGB = int64(1) << 30
+	testMB = int64(1) << 20
+	testTB = int64(1) << 40
+)
+
+func TestParseReleaseMeta(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  *model.ReleaseMeta
+	}{
+		{
+			name:  "空字符串",
+			title: "",
+			want:  nil,
+		},
+		{
+			name:  "纯中文标题无信息",
+			title: "名称：流浪地球",
+			want:  nil,
+		},
+		{
+			name:  "4K HDR H265",
+			title: "流浪地球2 (2023) 4K HDR H265 国语中字",
+			want:  &model.ReleaseMeta{Resolution: 2160, HDR: true, Codec: "H265", Year: 2023},
+		},
+		{
+			name:  "2160p杜比视界",
+			title: "沙丘2.Dune.Part.Two.2024.2160p.WEB-DL.DV.HDR10+.H265.DDP5.1.Atmos",
+			want:  &model.ReleaseMeta{Resolution: 2160, HDR: true, DolbyVision: true, Codec: "H265", Year: 2024},
+		},
+		{
+			name:  "1080P蓝光带大小",
+			title: "【蓝光原盘】奥本海默 Oppenheimer 2023 1080P BluRay x264 大小：32.5G",
+			want:  &model.ReleaseMeta{Resolution: 1080, Codec: "H264", Year: 2023, SizeBytes: sizeOf(32.5, testGB)},
+		},
+		{
+			name:  "中文杜比视界",
+			title: "繁花 全30集 4K 杜比视界 2023",
+			want:  &model.ReleaseMeta{Resolution: 2160, DolbyVision: true, Year: 2023, EpisodeStart: 1, EpisodeEnd: 30, Completed: true},
+		},
+		{
+			name:  "Dolby Vision英文",
+			title: "The Last of Us S01 2160p Dolby Vision HEVC",
+			want:  &model.ReleaseMeta{Resolution: 2160, DolbyVision: true, Codec: "H265", SeasonStart: 1, SeasonEnd: 1},
+		},
+		{
+			name:  "DoVi缩写",
+			title: "Blade.Runner.2049.2017.UHD.BluRay.2160p.DoVi.HEVC",
+			want:  &model.ReleaseMeta{Resolution: 2160, DolbyVision: true, Codec: "H265", Year: 2017},
+		},
+		{
+			name:  "DVD不是杜比视界",
+			title: "霸王别姬 DVD版 1993",
+			want:  &model.ReleaseMeta{Year: 1993},
+		},
+		{
+			name:  "单集S01E01",
+			title: "Loki.S02E03.1080p.WEB.H264",
+			want:  &model.ReleaseMeta{Resolution: 1080, Codec: "H264", SeasonStart: 2, SeasonEnd: 2, EpisodeStart: 3, EpisodeEnd: 3},
+		},
+		{
+			name:  "集数区间S01E01-E10",
+			title: "三体 S01E01-E30 4K 完结",
+			want:  &model.ReleaseMeta{Resolution: 2160, SeasonStart: 1, SeasonEnd: 1, EpisodeStart: 1, EpisodeEnd: 30, Completed: true},
+		},
+		{
+			name:  "集数区间省略E",
+			title: "漫长的季节 S01E01-12 1080p",
+			want:  &model.ReleaseMeta{Resolution: 1080, SeasonStart: 1, SeasonEnd: 1, EpisodeStart: 1, EpisodeEnd: 12},
+		},
+		{
+			name:  "多季S01-S03",
+			title: "怪奇物语 S01-S04 合集 1080P",
+			want:  &model.ReleaseMeta{Resolution: 1080, SeasonStart: 1, SeasonEnd: 4},
+		},
+		{
+			name:  "中文第一季",
+			title: "庆余年 第一季 全46集",
+			want:  &model.ReleaseMeta{SeasonStart: 1, SeasonEnd: 1, EpisodeStart: 1, EpisodeEnd: 46, Completed: true},
+		},
+		{
+			name:  "中文第二季数字",
+			title: "庆余年 第2季 更新至12集",
+			want:  &model.ReleaseMeta{SeasonStart: 2, SeasonEnd: 2, UpdatedTo: 12},
+		},
+		{
+			name:  "中文季区间",
+			title: "甄嬛传 第1-3季",
+			want:  &model.ReleaseMeta{SeasonStart: 1, SeasonEnd: 3},
+		},
+		{
+			name:  "中文季区间中文数字",
+			title: "武林外传 第一至三季",
+			want:  &model.ReleaseMeta{SeasonStart: 1, SeasonEnd: 3},
+		},
+		{
+			name:  "中文第十二季",
+			title: "海贼王 第十二季",
+			want:  &model.ReleaseMeta{SeasonStart: 12, SeasonEnd: 12},
+		},
+		{
+			name:  "中文第二十季",
+			title: "名侦探柯南 第二十季",
+			want:  &model.ReleaseMeta{SeasonStart: 20, SeasonEnd: 20},
+		},
+		{
+			name:  "第N集区间",
+			title: "长相思 第1-40集 1080P",
+			want:  &model.ReleaseMeta{Resolution: 1080, EpisodeStart: 1, EpisodeEnd: 40},
+		},
+		{
+			name:  "第N集单集",
+			title: "狂飙 第05集",
+			want:  &model.ReleaseMeta{EpisodeStart: 5, EpisodeEnd: 5},
+		},
+		{
+			name:  "第N话",
+			title: "咒术回战 第23话",
+			want:  &model.ReleaseMeta{EpisodeStart: 23, EpisodeEnd: 23},
+		},
+		{
+			name:  "第N期综艺",
+			title: "奔跑吧 第12期 2024",
+			want:  &model.ReleaseMeta{EpisodeStart: 12, EpisodeEnd: 12, Year: 2024},
+		},
+		{
+			name:  "EP区间",
+			title: "莲花楼 EP01-EP40 4K",
+			want:  &model.ReleaseMeta{Resolution: 2160, EpisodeStart: 1, EpisodeEnd: 40},
+		},
+		{
+			name:  "EP单集",
+			title: "与凤行 EP12 1080p",
+			want:  &model.ReleaseMeta{Resolution: 1080, EpisodeStart: 12, EpisodeEnd: 12},
+		},
+		{
+			name:  "更新至第N集",
+			title: "墨雨云间 更新至第18集",
+			want:  &model.ReleaseMeta{UpdatedTo: 18},
+		},
+		{
+			name:  "更新至EP",
+			title: "【更新至EP08】我的阿勒泰 4K",
+			want:  &model.ReleaseMeta{Resolution: 2160, UpdatedTo: 8},
+		},
+		{
+			name:  "更至缩写",
+			title: "玫瑰的故事 更至20",
+			want:  &model.ReleaseMeta{UpdatedTo: 20},
+		},
+		{
+			name:  "更新到中文数字",
+			title: "凡人修仙传 更新到一百二十集",
+			want:  &model.ReleaseMeta{UpdatedTo: 120},
+		},
+		{
+			name:  "连载中不是完结",
+			title: "斗破苍穹 年番 连载中 1080P",
+			want:  &model.ReleaseMeta{Resolution: 1080},
+		},
+		{
+			name:  "未完结不是完结",
+			title: "仙逆 未完结 更新至50集",
+			want:  &model.ReleaseMeta{UpdatedTo: 50},
+		},
+		{
+			name:  "已完结",
+			title: "狂飙 已完结 4K高码率",
+			want:  &model.ReleaseMeta{Resolution: 2160, Completed: true},
+		},
+		{
+			name:  "完结加更新进度",
+			title: "人世间 更新至58集 完结",
+			want:  &model.ReleaseMeta{UpdatedTo: 58, Completed: true},
+		},
+		{
+			name:  "全集标记",
+			title: "西游记 1986 全集 1080P",
+			want:  &model.ReleaseMeta{Resolution: 1080, Year: 1986, Completed: true},
+		},
+		{
+			name:  "N集全",
+			title: "琅琊榜 54集全 1080P",
+			want:  &model.ReleaseMeta{Resolution: 1080, EpisodeStart: 1, EpisodeEnd: 54, Completed: true},
+		},
+		{
+			name:  "共N集",
+			title: "大明王朝1566 共46集",
+			want:  &model.ReleaseMeta{EpisodeStart: 1, EpisodeEnd: 46, Completed: true},
+		},
+		{
+			name:  "英文Complete",
+			title: "Breaking Bad Complete Series 1080p BluRay",
+			want:  &model.ReleaseMeta{Resolution: 1080, Completed: true},
+		},
+		{
+			name:  "括号年份优先",
+			title: "银翼杀手2049 (2017) 1080P",
+			want:  &model.ReleaseMeta{Resolution: 1080, Year: 2017},
+		},
+		{
+			name:  "中文括号年份",
+			title: "让子弹飞（2010）4K",
+			want:  &model.ReleaseMeta{Resolution: 2160, Year: 2010},
+		},
+		{
+			name:  "方括号年份",
+			title: "[2019] 寄生虫 Parasite 1080p",
+			want:  &model.ReleaseMeta{Resolution: 1080, Year: 2019},
+		},
+		{
+			name:  "年份带年字",
+			title: "2024年最新电影合集",
+			want:  &model.ReleaseMeta{Year: 2024},
+		},
+		{
+			name:  "尺寸写法不是年份",
+			title: "演示视频 1920x1080 60帧",
+			want:  &model.ReleaseMeta{Resolution: 1080},
+		},
+		{
+			name:  "尺寸写法4K",
+			title: "风景纪录片 3840×2160 HDR",
+			want:  &model.ReleaseMeta{Resolution: 2160, HDR: true},
+		},
+		{
+			name:  "720p",
+			title: "老友记 S05 720p",
+			want:  &model.ReleaseMeta{Resolution: 720, SeasonStart: 5, SeasonEnd: 5},
+		},
+		{
+			name:  "1080i",
+			title: "春晚 2024 1080i TS",
+			want:  &model.ReleaseMeta{Resolution: 1080, Year: 2024},
+		},
+		{
+			name:  "480p",
+			title: "射雕英雄传 1983 480P",
+			want:  &model.ReleaseMeta{Resolution: 480, Year: 1983},
+		},
+		{
+			name:  "2K",
+			title: "宇宙探索编辑部 2K 高码",
+			want:  &model.ReleaseMeta{Resolution: 1440},
+		},
+		{
+			name:  "8K",
+			title: "8K 演示片",
+			want:  &model.ReleaseMeta{Resolution: 4320},
+		},
+		{
+			name:  "UHD",
+			title: "阿凡达 水之道 UHD BluRay",
+			want:  &model.ReleaseMeta{Resolution: 2160},
+		},
+		{
+			name:  "多分辨率取最高",
+			title: "流浪地球 1080P+4K 双版本",
+			want:  &model.ReleaseMeta{Resolution: 2160},
+		},
+		{
+			name:  "GB大小",
+			title: "绝命毒师 合集 [120GB]",
+			want:  &model.ReleaseMeta{SizeBytes: 120 * testGB},
+		},
+		{
+			name:  "MB大小",
+			title: "周杰伦 无损音乐 850MB",
+			want:  &model.ReleaseMeta{SizeBytes: 850 * testMB},
+		},
+		{
+			name:  "TB大小",
+			title: "漫威全系列 1.5T 4K",
+			want:  &model.ReleaseMeta{Resolution: 2160, SizeBytes: sizeOf(1.5, testTB)},
+		},
+		{
+			name:  "GiB大小",
+			title: "The.Bear.S03.1080p 23.4GiB",
+			want:  &model.ReleaseMeta{Resolution: 1080, SeasonStart: 3, SeasonEnd: 3, SizeBytes: sizeOf(23.4, testGB)},
+		},
+		{
+			name:  "4K不是大小",
+			title: "4K 修复版",
+			want:  &model.ReleaseMeta{Resolution: 2160},
+		},
+		{
+			name:  "码率不是大小",
+			title: "1080P 20Mbps",
+			want:  &model.ReleaseMeta{Resolution: 1080},
+		},
+		{
+			name:  "H.265带点",
+			title: "三大队 2023 H.265 1080P",
+			want:  &model.ReleaseMeta{Resolution: 1080, Codec: "H265", Year: 2023},
+		},
+		{
+			name:  "HEVC",
+			title: "封神第一部 HEVC 10bit",
+			want:  &model.ReleaseMeta{Codec: "H265"},
+		},
+		{
+			name:  "AV1",
+			title: "纪录片 AV1 2160p",
+			want:  &model.ReleaseMeta{Resolution: 2160, Codec: "AV1"},
+		},
+		{
+			name:  "AVC",
+			title: "Top.Gun.Maverick.2022.1080p.BluRay.AVC.DTS-HD",
+			want:  &model.ReleaseMeta{Resolution: 1080, Codec: "H264", Year: 2022},
+		},
+		{
+			name:  "VP9",
+			title: "油管搬运 VP9 4K",
+			want:  &model.ReleaseMeta{Resolution: 2160, Codec: "VP9"},
+		},
+		{
+			name:  "HLG",
+			title: "央视 4K HLG 纪录片",
+			want:  &model.ReleaseMeta{Resolution: 2160, HDR: true},
+		},
+		{
+			name:  "HDR10",
+			title: "Dune.2021.2160p.HDR10.x265",
+			want:  &model.ReleaseMeta{Resolution: 2160, HDR: true, Codec: "H265", Year: 2021},
+		},
+		{
+			name:  "TG消息多行",
+			title: "名称：漫长的季节 (2023)\n描述：4K 杜比视界 全12集\n大小：45.6G\n标签：#国剧 #悬疑",
+			want: &model.ReleaseMeta{
+				Resolution:   2160,
+				DolbyVision:  true,
+				Year:         2023,
+				EpisodeStart: 1,
+				EpisodeEnd:   12,
+				SizeBytes:    sizeOf(45.6, testGB),
+				Completed:    true,
+			},
+		},
+		{
+			name:  "TG消息更新进度",
+			title: "名称：庆余年2 (2024) 更新至第10集\n1080P 高码 国语中字\n链接：https://pan.quark.cn/s/abcdef",
+			want:  &model.ReleaseMeta{Resolution: 1080, Year: 2024, UpdatedTo: 10},
+		},
+		{
+			name:  "点分隔英文发布名",
+			title: "House.of.the.Dragon.S02E08.2160p.MAX.WEB-DL.DDP5.1.DV.HDR.H.265",
+			want:  &model.ReleaseMeta{Resolution: 2160, HDR: true, DolbyVision: true, Codec: "H265", SeasonStart: 2, SeasonEnd: 2, EpisodeStart: 8, EpisodeEnd: 8},
+		},
+		{
+			name:  "标题数字不是年份",
+			title: "大明王朝1566",
+			want:  nil,
+		},
+		{
+			name:  "PS5不是季",
+			title: "PS5 游戏合集",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseReleaseMeta(tt.title)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("ParseReleaseMeta(%q) = %+v, want nil", tt.title, *got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("ParseReleaseMeta(%q) = nil, want %+v", tt.title, *tt.want)
+			}
+			if *got != *tt.want {
+				t.Errorf("ParseReleaseMeta(%q)\n got  %+v\n want %+v", tt.title, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChineseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"12", 12},
+		{"一", 1},
+		{"两", 2},
+		{"十", 10},
+		{"十二", 12},
+		{"二十", 20},
+		{"二十三", 23},
+		{"一百", 100},
+		{"一百二十", 120},
+		{"一百零五", 105},
+		{"abc", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseChineseNumber(tt.in); got != tt.want {
+			t.Errorf("parseChineseNumber(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package util
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25相关参数
+const (
+	bm25K1 = 1.2  // 词频饱和参数
+	bm25B  = 0.75 // 长度归一化参数
+
+	// 各字段权重：标题最重要，作品标题次之，正文最低
+	titleFieldWeight     = 3.0
+	workTitleFieldWeight = 2.0
+	contentFieldWeight   = 1.0
+
+	// 模糊匹配（拼写错误、前缀）命中时的词频折扣
+	fuzzyMatchDiscount = 0.7
+)
+
+// RelevanceDoc 参与相关度计算的文档
+type RelevanceDoc struct {
+	Title     string // 标题
+	WorkTitle string // 作品标题（多个时用空格拼接）
+	Content   string // 正文
+}
+
+// RelevanceScorer 基于分词和BM25的相关度评分器
+// 同一次搜索的所有结果共用一个评分器，IDF和平均长度基于这批结果统计
+type RelevanceScorer struct {
+	normalizedQuery string
+	queryTokens     []string           // 去重后的查询词元
+	segments        []relevanceSegment // 按空格切分的查询片段
+	idf             map[string]float64
+	avgLen          [3]float64 // 标题、作品标题、正文的平均词元数
+	maxScore        float64    // 理论最高得分，用于归一化到0-100
+}
+
+// relevanceSegment 查询中按空格切分的一个片段
+type relevanceSegment struct {
+	text   string   // 已标准化的片段文本
+	tokens []string // 片段的词元
+}
+
+// relevanceField 文档中单个字段的词元统计
+type relevanceField struct {
+	text   string
+	tokens map[string]int
+	length int
+}
+
+// Tokenize 将文本切分为词元
+// 文本先经过NormalizeText标准化；连续汉字按二元组切分（单个汉字保留为单字），字母数字按单词切分
+func Tokenize(text string) []string {
+	return tokenizeNormalized(NormalizeText(text))
+}
+
+// tokenizeNormalized 对已标准化的文本分词
+func tokenizeNormalized(normalized string) []string {
+	if normalized == "" {
+		return nil
+	}
+
+	tokens := make([]string, 0, len(normalized)/2)
+	var han []rune
+	var word []rune
+
+	flushHan := func() {
+		switch len(han) {
+		case 0:
+		case 1:
+			tokens = append(tokens, string(han))
+		default:
+			for i := 0; i+1 < len(han); i++ {
+				tokens = append(tokens, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range normalized {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+
+	return tokens
+}
+
+// NewRelevanceScorer 根据查询和本次搜索的全部结果创建评分器
+func NewRelevanceScorer(query string, docs []RelevanceDoc) *RelevanceScorer {
+	scorer := &RelevanceScorer{
+		normalizedQuery: NormalizeText(query),
+		idf:             make(map[string]float64),
+	}
+
+	seen := make(map[string]bool)
+	for _, segment := range strings.Fields(scorer.normalizedQuery) {
+		segmentTokens := tokenizeNormalized(segment)
+		if len(segmentTokens) == 0 {
+			continue
+		}
+		scorer.segments = append(scorer.segments, relevanceSegment{text: segment, tokens: segmentTokens})
+		for _, token := range segmentTokens {
+			if !seen[token] {
+				seen[token] = true
+				scorer.queryTokens = append(scorer.queryTokens, token)
+			}
+		}
+	}
+
+	if len(scorer.queryTokens) == 0 {
+		return scorer
+	}
+
+	// 统计文档频率和字段平均长度
+	docFreq := make(map[string]int, len(scorer.queryTokens))
+	var totalLen [3]int
+	for _, doc := range docs {
+		fields := buildRelevanceFields(doc)
+		for i, field := range fields {
+			totalLen[i] += field.length
+		}
+		for _, token := range scorer.queryTokens {
+			for _, field := range fields {
+				if field.termFrequency(token) > 0 {
+					docFreq[token]++
+					break
+				}
+			}
+		}
+	}
+
+	n := float64(len(docs))
+	for i := range scorer.avgLen {
+		if len(docs) > 0 {
+			scorer.avgLen[i] = float64(totalLen[i]) / n
+		}
+		if scorer.avgLen[i] <= 0 {
+			scorer.avgLen[i] = 1
+		}
+	}
+
+	for _, token := range scorer.queryTokens {
+		df := float64(docFreq[token])
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		scorer.idf[token] = idf
+		scorer.maxScore += idf * (bm25K1 + 1)
+	}
+
+	return scorer
+}
+
+// Score 计算文档的相关度得分，范围0-100，保留两位小数
+// 查询为空时返回0
+func (s *RelevanceScorer) Score(doc RelevanceDoc) float64 {
+	if s == nil || len(s.queryTokens) == 0 || s.maxScore <= 0 {
+		return 0
+	}
+
+	fields := buildRelevanceFields(doc)
+	weights := [3]float64{titleFieldWeight, workTitleFieldWeight, contentFieldWeight}
+
+	raw := 0.0
+	for _, token := range s.queryTokens {
+		weightedTF := 0.0
+		for i, field := range fields {
+			tf := field.termFrequency(token)
+			if tf == 0 {
+				continue
+			}
+			norm := 1 - bm25B + bm25B*float64(field.length)/s.avgLen[i]
+			weightedTF += weights[i] * tf / norm
+		}
+		if weightedTF > 0 {
+			raw += s.idf[token] * weightedTF * (bm25K1 + 1) / (weightedTF + bm25K1)
+		}
+	}
+
+	score := raw / s.maxScore * 100
+	if score > 100 {
+		score = 100
+	}
+	return math.Round(score*100) / 100
+}
+
+// Match 判断文本是否与查询匹配
+// 文本包含完整查询时直接匹配；否则要求每个查询片段都命中：文本包含该片段，或包含片段的全部词元（顺序不限）
+// 汉字词元必须完全命中，只有字母数字词元允许前缀匹配和少量拼写错误
+func (s *RelevanceScorer) Match(text string) bool {
+	if s == nil || s.normalizedQuery == "" {
+		return true
+	}
+
+	normalized := NormalizeText(text)
+	if ContainsNormalized(normalized, s.normalizedQuery) {
+		return true
+	}
+
+	field := newRelevanceField(normalized)
+	for _, segment := range s.segments {
+		if ContainsNormalized(normalized, segment.text) {
+			continue
+		}
+		for _, token := range segment.tokens {
+			if field.termFrequency(token) == 0 {
+				return false
+			}
+		}
+	}
+
+	return len(s.segments) > 0
+}
+
+// buildRelevanceFields 构建文档各字段的词元统计
+func buildRelevanceFields(doc RelevanceDoc) [3]relevanceField {
+	return [3]relevanceField{
+		newRelevanceField(NormalizeText(doc.Title)),
+		newRelevanceField(NormalizeText(doc.WorkTitle)),
+		newRelevanceField(NormalizeText(doc.Content)),
+	}
+}
+
+// newRelevanceField 根据已标准化的文本创建字段统计
+func newRelevanceField(normalized string) relevanceField {
+	tokens := tokenizeNormalized(normalized)
+	counts := make(map[string]int, len(tokens))
+	for _, token := range tokens {
+		counts[token]++
+	}
+	return relevanceField{text: normalized, tokens: counts, length: len(tokens)}
+}
+
+// termFrequency 获取查询词元在字段中的词频，模糊命中按折扣计算
+func (f relevanceField) termFrequency(token string) float64 {
+	if count, ok := f.tokens[token]; ok {
+		return float64(count)
+	}
+
+	runes := []rune(token)
+
+	// 单个汉字：二元组切分后不会单独出现，直接统计字符出现次数
+	if len(runes) == 1 && unicode.Is(unicode.Han, runes[0]) {
+		return float64(strings.Count(f.text, token))
+	}
+
+	if !isASCIIWord(token) || len(token) < 3 {
+		return 0
+	}
+
+	// 字母数字词元：允许前缀匹配（如s01匹配s01e01）和少量拼写错误
+	maxEdits := 0
+	if len(token) >= 4 {
+		maxEdits = 1
+	}
+	if len(token) >= 8 {
+		maxEdits = 2
+	}
+
+	fuzzy := 0
+	for candidate, count := range f.tokens {
+		if !isASCIIWord(candidate) {
+			continue
+		}
+		if strings.HasPrefix(candidate, token) ||
+			(maxEdits > 0 && withinEditDistance(token, candidate, maxEdits)) {
+			fuzzy += count
+		}
+	}
+	return float64(fuzzy) * fuzzyMatchDiscount
+}
+
+// isASCIIWord 判断词元是否只包含ASCII字母和数字
+func isASCIIWord(token string) bool {
+	for i := 0; i < len(token); i++ {
+		c := token[i]
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return token != ""
+}
+
+// withinEditDistance 判断两个ASCII字符串的编辑距离是否不超过maxEdits
+func withinEditDistance(a, b string, maxEdits int) bool {
+	if diff := len(a) - len(b); diff > maxEdits || -diff > maxEdits {
+		return false
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		// 整行都超过阈值时提前结束
+		if rowMin > maxEdits {
+			return false
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)] <= maxEdits
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "空字符串", text: "", want: nil},
+		{name: "单个汉字", text: "剑", want: []string{"剑"}},
+		{name: "汉字二元组", text: "流浪地球", want: []string{"流浪", "浪地", "地球"}},
+		{name: "中英混合", text: "流浪地球2 4K", want: []string{"流浪", "浪地", "地球", "2", "4k"}},
+		{name: "标点分隔", text: "沙丘.Dune.Part.Two", want: []string{"沙丘", "dune", "part", "two"}},
+		{name: "字母数字连续", text: "S01E02", want: []string{"s01e02"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Tokenize(%q) = %q，期望 %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelevanceScorerMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		want  bool
+	}{
+		{name: "包含完整查询", query: "流浪地球", text: "流浪地球2 4K HDR", want: true},
+		{name: "汉字错一个字不匹配", query: "流浪地球", text: "流浪地图 全集", want: false},
+		{name: "汉字缺少一个二元组不匹配", query: "三体问题", text: "三体 问答", want: false},
+		{name: "多个片段顺序不限", query: "4K 沙丘", text: "沙丘2 2024 4K HDR", want: true},
+		{name: "任一片段未命中不匹配", query: "沙丘 4K", text: "沙丘2 1080P", want: false},
+		{name: "单个汉字", query: "剑", text: "倚天屠龙记 剑", want: true},
+		{name: "字母词元容忍拼写错误", query: "oppenheimer", text: "Oppenhiemer 2023 1080P", want: true},
+		{name: "字母词元前缀匹配", query: "s01", text: "Loki S01E02", want: true},
+		{name: "短字母词元不容忍拼写错误", query: "hdr", text: "HDX 2024", want: false},
+		{name: "空查询总是匹配", query: "", text: "任意文本", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer := NewRelevanceScorer(tt.query, nil)
+			if got := scorer.Match(tt.text); got != tt.want {
+				t.Fatalf("Match(%q, %q) = %v，期望 %v", tt.query, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		maxEdits int
+		want     bool
+	}{
+		{a: "dune", b: "dune", maxEdits: 0, want: true},
+		{a: "dune", b: "june", maxEdits: 0, want: false},
+		{a: "dune", b: "june", maxEdits: 1, want: true},
+		{a: "dune", b: "dunes", maxEdits: 1, want: true},
+		{a: "dune", b: "un", maxEdits: 1, want: false},
+		{a: "oppenheimer", b: "oppenhiemer", maxEdits: 1, want: false},
+		{a: "oppenheimer", b: "oppenhiemer", maxEdits: 2, want: true},
+		{a: "abc", b: "xyz", maxEdits: 2, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := withinEditDistance(tt.a, tt.b, tt.maxEdits); got != tt.want {
+			t.Errorf("withinEditDistance(%q, %q, %d) = %v，期望 %v", tt.a, tt.b, tt.maxEdits, got, tt.want)
+		}
+	}
+}
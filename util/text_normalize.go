@@ -0,0 +1,147 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"pansou/config"
+)
+
+// NormalizeText 标准化文本用于关键词匹配
+// 依次处理：全角转半角、转小写、繁体转简体、标点符号统一为空格并合并连续空白
+// 关键词和待匹配文本都需要经过同样的处理，匹配结果才一致
+func NormalizeText(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(text))
+
+	lastSpace := true // 用于去掉开头空白和合并连续空白
+	for _, r := range text {
+		r = foldWidth(r)
+		r = unicode.ToLower(r)
+		if simplified, ok := traditionalToSimplified[r]; ok {
+			r = simplified
+		}
+
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			if !lastSpace {
+				builder.WriteByte(' ')
+				lastSpace = true
+			}
+			continue
+		}
+
+		builder.WriteRune(r)
+		lastSpace = false
+	}
+
+	return strings.TrimRight(builder.String(), " ")
+}
+
+// foldWidth 将全角字符转换为半角字符
+func foldWidth(r rune) rune {
+	switch {
+	case r == '　': // 全角空格
+		return ' '
+	case r >= '！' && r <= '～': // 全角ASCII字符
+		return r - 0xFEE0
+	}
+	return r
+}
+
+// ContainsNormalized 判断标准化后的文本是否包含标准化后的关键词
+// 启用拼音匹配时，纯字母关键词还会与文本的拼音首字母比较（如"dldl"匹配"斗罗大陆"）
+func ContainsNormalized(normalizedText, normalizedKeyword string) bool {
+	if normalizedKeyword == "" {
+		return true
+	}
+	if strings.Contains(normalizedText, normalizedKeyword) {
+		return true
+	}
+
+	if config.AppConfig != nil && config.AppConfig.PinyinMatchEnabled && isPinyinKeyword(normalizedKeyword) {
+		initials := PinyinInitials(normalizedText)
+		return initials != "" && strings.Contains(initials, strings.ReplaceAll(normalizedKeyword, " ", ""))
+	}
+
+	return false
+}
+
+// MatchKeyword 判断文本是否包含关键词，两者都会先经过NormalizeText处理
+// 在循环中使用时建议预先标准化关键词并调用ContainsNormalized
+func MatchKeyword(text, keyword string) bool {
+	return ContainsNormalized(NormalizeText(text), NormalizeText(keyword))
+}
+
+// isPinyinKeyword 判断关键词是否可能是拼音首字母（至少2个字母，且只包含字母和空格）
+func isPinyinKeyword(keyword string) bool {
+	letters := 0
+	for _, r := range keyword {
+		switch {
+		case r >= 'a' && r <= 'z':
+			letters++
+		case r == ' ':
+		default:
+			return false
+		}
+	}
+	return letters >= 2
+}
+
+// gb2312InitialBoundaries GB2312一级汉字按拼音排序，每个声母对应的起始编码
+var gb2312InitialBoundaries = []struct {
+	code    int
+	initial byte
+}{
+	{0xB0A1, 'a'}, {0xB0C5, 'b'}, {0xB2C1, 'c'}, {0xB4EE, 'd'}, {0xB6EA, 'e'},
+	{0xB7A2, 'f'}, {0xB8C1, 'g'}, {0xB9FE, 'h'}, {0xBBF7, 'j'}, {0xBFA6, 'k'},
+	{0xC0AC, 'l'}, {0xC2E8, 'm'}, {0xC4C3, 'n'}, {0xC5B6, 'o'}, {0xC5BE, 'p'},
+	{0xC6DA, 'q'}, {0xC8BB, 'r'}, {0xC8F6, 's'}, {0xCBFA, 't'}, {0xCDDA, 'w'},
+	{0xCEF4, 'x'}, {0xD1B9, 'y'}, {0xD4D1, 'z'},
+}
+
+// gb2312Level1End GB2312一级汉字的结束编码（之后的二级汉字按部首排序，无法推算拼音）
+const gb2312Level1End = 0xD7F9
+
+// PinyinInitials 获取文本的拼音首字母，字母和数字原样保留，其他字符忽略
+// 仅支持GB2312一级汉字（约3755个常用字），其余汉字会被忽略
+func PinyinInitials(text string) string {
+	encoder := simplifiedchinese.GBK.NewEncoder()
+
+	var builder strings.Builder
+	for _, r := range text {
+		if r < 0x80 {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				builder.WriteRune(unicode.ToLower(r))
+			}
+			continue
+		}
+		if !unicode.Is(unicode.Han, r) {
+			continue
+		}
+
+		encoded, err := encoder.String(string(r))
+		if err != nil || len(encoded) != 2 {
+			continue
+		}
+		code := int(encoded[0])<<8 | int(encoded[1])
+		if code < gb2312InitialBoundaries[0].code || code > gb2312Level1End {
+			continue
+		}
+
+		initial := gb2312InitialBoundaries[0].initial
+		for _, boundary := range gb2312InitialBoundaries {
+			if code < boundary.code {
+				break
+			}
+			initial = boundary.initial
+		}
+		builder.WriteByte(initial)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,119 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "空字符串", text: "", want: ""},
+		{name: "繁体转简体", text: "鬥羅大陸", want: "斗罗大陆"},
+		{name: "繁简混排", text: "進擊的巨人 最终季", want: "进击的巨人 最终季"},
+		{name: "一简对多繁", text: "頭髮 發現 乾杯 幹部", want: "头发 发现 干杯 干部"},
+		{name: "全角字母数字", text: "ＡＢＣ１２３", want: "abc123"},
+		{name: "全角空格", text: "流浪地球　２", want: "流浪地球 2"},
+		{name: "大写转小写", text: "Dune Part Two", want: "dune part two"},
+		{name: "中文标点", text: "《流浪地球》【4K】", want: "流浪地球 4k"},
+		{name: "全角标点", text: "沙丘！（２０２４）", want: "沙丘 2024"},
+		{name: "英文标点与符号", text: "S01.E02-[1080P]+HDR", want: "s01 e02 1080p hdr"},
+		{name: "合并连续空白并去掉首尾", text: "  三体 ,,  问题 。 ", want: "三体 问题"},
+		{name: "只有标点", text: "【】！？", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeText(tt.text); got != tt.want {
+				t.Fatalf("NormalizeText(%q) = %q，期望 %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldWidth(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want rune
+	}{
+		{r: '　', want: ' '},
+		{r: '！', want: '!'},
+		{r: 'Ａ', want: 'A'},
+		{r: 'ｚ', want: 'z'},
+		{r: '０', want: '0'},
+		{r: '～', want: '~'},
+		{r: '中', want: '中'},
+		{r: 'a', want: 'a'},
+		{r: '。', want: '。'},
+	}
+
+	for _, tt := range tests {
+		if got := foldWidth(tt.r); got != tt.want {
+			t.Errorf("foldWidth(%q) = %q，期望 %q", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTraditionalToSimplified(t *testing.T) {
+	got := buildTraditionalToSimplified(`
+萬万 與与
+格式 錯誤的 項 簡简简 同同
+`)
+	want := map[rune]rune{'萬': '万', '與': '与', '格': '式'}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("buildTraditionalToSimplified() = %q，期望 %q", got, want)
+	}
+}
+
+func TestTraditionalToSimplifiedTable(t *testing.T) {
+	for traditional, simplified := range traditionalToSimplified {
+		// 转换结果不应再被转换，否则标准化结果取决于转换次数
+		if again, ok := traditionalToSimplified[simplified]; ok {
+			t.Errorf("%q 转换为 %q 后还会转换为 %q", traditional, simplified, again)
+		}
+	}
+}
+
+func TestMatchKeyword(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		keyword string
+		want    bool
+	}{
+		{name: "繁体标题匹配简体关键词", text: "【鬥羅大陸】第二季 4K", keyword: "斗罗大陆", want: true},
+		{name: "简体标题匹配繁体关键词", text: "斗罗大陆 第二季", keyword: "鬥羅大陸", want: true},
+		{name: "全角标题匹配半角关键词", text: "ＤＵＮＥ　Ｐａｒｔ　Ｔｗｏ", keyword: "dune part", want: true},
+		{name: "标点不影响匹配", text: "流浪地球2.4K.HDR", keyword: "流浪地球2 4k", want: true},
+		{name: "空关键词总是匹配", text: "任意文本", keyword: "", want: true},
+		{name: "不包含关键词", text: "斗罗大陆", keyword: "斗破苍穹", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchKeyword(tt.text, tt.keyword); got != tt.want {
+				t.Fatalf("MatchKeyword(%q, %q) = %v，期望 %v", tt.text, tt.keyword, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelevanceScorerMatchVariants(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+	}{
+		{query: "斗罗大陆", text: "鬥羅大陸 第二季"},
+		{query: "進擊的巨人", text: "进击的巨人 最终季"},
+		{query: "dune 4k", text: "ＤＵＮＥ．Ｐａｒｔ．Ｔｗｏ【４Ｋ】"},
+	}
+
+	for _, tt := range tests {
+		if !NewRelevanceScorer(tt.query, nil).Match(tt.text) {
+			t.Errorf("Match(%q, %q) = false，期望 true", tt.query, tt.text)
+		}
+	}
+}
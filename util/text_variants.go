@@ -0,0 +1,94 @@
+package util
+
+import "strings"
+
+// traditionalSimplifiedPairs 常用繁体字到简体字的对照表，每项为“繁简”两个字符
+// 只收录影视、动漫、音乐、软件等资源标题中常见的字，生僻字不做转换
+const traditionalSimplifiedPairs = `
+萬万 與与 醜丑 專专 業业 叢丛 東东 絲丝 兩两 嚴严 喪丧 個个 豐丰 臨临 為为 麗丽 舉举 麼么 義义 烏乌
+樂乐 喬乔 習习 鄉乡 書书 買买 亂乱 爭争 於于 虧亏 雲云 亞亚 產产 畝亩 親亲 億亿 僅仅 從从 侖仑 倉仓
+儀仪 們们 價价 眾众 優优 夥伙 會会 傘伞 偉伟 傳传 傷伤 倫伦 偽伪 體体 餘余 傭佣 俠侠 侶侣 僥侥 偵侦
+側侧 僑侨 儂侬 儔俦 儼俨 倆俩 儷俪 儉俭 債债 傾倾 償偿 儲储 兒儿 兌兑 黨党 蘭兰 關关 興兴 養养 獸兽
+內内 岡冈 冊册 寫写 軍军 農农 馮冯 衝冲 決决 況况 凍冻 淨净 淒凄 涼凉 減减 湊凑 凜凛 幾几 鳳凤 憑凭
+凱凯 擊击 鑿凿 劃划 劉刘 則则 剛刚 創创 刪删 別别 劑剂 劍剑 剝剥 劇剧 勸劝 辦办 務务 動动 勵励 勁劲
+勞劳 勢势 勳勋 勻匀 區区 醫医 華华 協协 單单 賣卖 盧卢 鹵卤 臥卧 衛卫 卻却 廠厂 廳厅 曆历 歷历 厲厉
+壓压 厭厌 廁厕 廂厢 廈厦 廚厨 廝厮 縣县 參参 雙双 發发 髮发 變变 敘叙 疊叠 葉叶 號号 嘆叹 籲吁 後后
+嚇吓 呂吕 嗎吗 噸吨 聽听 啟启 吳吴 嘔呕 唄呗 員员 嗆呛 嗚呜 詠咏 嚨咙 響响 啞哑 嘩哗 喲哟 嘮唠 喚唤
+嘖啧 嘯啸 噴喷 嘍喽 噓嘘 囑嘱 嚕噜 囂嚣 團团 糰团 園园 圍围 國国 圖图 圓圆 聖圣 場场 壞坏 塊块 堅坚
+壇坛 壩坝 墳坟 墜坠 壟垄 壘垒 墾垦 墊垫 塹堑 墮堕 壯壮 聲声 殼壳 壺壶 處处 備备 復复 複复 夠够 頭头
+誇夸 夾夹 奪夺 奮奋 獎奖 妝妆 婦妇 媽妈 嫵妩 嬌娇 孃娘 婁娄 嬰婴 學学 孫孙 寧宁 寶宝 實实 寵宠 審审
+憲宪 宮宫 寬宽 賓宾 對对 尋寻 導导 壽寿 將将 爾尔 塵尘 嘗尝 堯尧 尷尴 屍尸 盡尽 層层 屬属 屢屡 嶼屿
+歲岁 豈岂 嶇岖 崗岗 峽峡 嶺岭 巒峦 嶽岳 幣币 帥帅 師师 帳帐 帶带 幫帮 幹干 乾干 庫库 廬庐 應应 廟庙
+龐庞 廢废 開开 異异 棄弃 張张 彌弥 彎弯 彈弹 強强 歸归 當当 錄录 彙汇 彥彦 徹彻 徑径 徠徕 憶忆 懺忏
+憂忧 懷怀 態态 慫怂 憐怜 總总 懟怼 戀恋 懇恳 惡恶 惱恼 悅悦 懸悬 驚惊 懼惧 慘惨 慚惭 慣惯 願愿 懶懒
+戇戆 戔戋 戲戏 戰战 戶户 紮扎 撲扑 執执 擴扩 掃扫 揚扬 擾扰 撫抚 拋抛 摶抟 搶抢 護护 報报 擔担 擬拟
+攏拢 揀拣 擁拥 攔拦 擰拧 撥拨 擇择 掛挂 摯挚 攣挛 撓挠 擋挡 擠挤 揮挥 撈捞 損损 撿捡 換换 搗捣 據据
+擄掳 擲掷 撣掸 摻掺 摜掼 攪搅 攜携 搖摇 攝摄 擺摆 攤摊 撐撑 攆撵 擷撷 敵敌 數数 齋斋 斕斓 鬥斗 鬬斗
+斬斩 斷断 無无 舊旧 時时 曠旷 曇昙 晝昼 顯显 晉晋 曬晒 曉晓 暈晕 暉晖 暫暂 曖暧 術术 樸朴 機机 殺杀
+雜杂 權权 條条 來来 楊杨 榪杩 傑杰 極极 構构 樅枞 樞枢 棗枣 櫪枥 梘枧 棖枨 槍枪 楓枫 梟枭 櫃柜 檸柠
+檉柽 梔栀 柵栅 標标 棧栈 櫛栉 櫳栊 棟栋 櫨栌 櫟栎 欄栏 樹树 棲栖 樣样 欒栾 桿杆 椏桠 橈桡 楨桢 檔档
+榿桤 橋桥 樺桦 檜桧 槳桨 樁桩 夢梦 檢检 櫺棂 槨椁 櫝椟 槧椠 欏椤 橢椭 樓楼 欖榄 櫬榇 櫚榈 櫸榉 檯台
+臺台 颱台 槓杠 歡欢 歐欧 殲歼 殘残 殞殒 殤殇 殯殡 毆殴 毀毁 轂毂 畢毕 斃毙 氈毡 氣气 氫氢 氬氩 漢汉
+湯汤 溝沟 沒没 灃沣 漚沤 瀝沥 淪沦 滄沧 潙沩 滬沪 濘泞 淚泪 澤泽 潑泼 潔洁 灑洒 窪洼 濁浊 測测 濟济
+瀏浏 渾浑 滸浒 濃浓 潯浔 濤涛 澇涝 湧涌 滌涤 潤润 澗涧 漲涨 澀涩 澱淀 淵渊 漬渍 漸渐 瀆渎 漁渔 滲渗
+溫温 灣湾 濕湿 潰溃 濺溅 滯滞 滿满 濾滤 濫滥 灤滦 濱滨 灘滩 瀟潇 瀾澜 瀲潋 災灾 燈灯 靈灵 爐炉 燉炖
+煉炼 爍烁 爛烂 燭烛 煙烟 煩烦 燒烧 燴烩 燙烫 燼烬 熱热 煥焕 燜焖 營营 燦灿 爺爷 牆墙 犧牺 狀状 猶犹
+狹狭 獅狮 獨独 獄狱 猻狲 獰狞 獲获 獵猎 貓猫 獻献 瑪玛 環环 現现 瑲玱 璽玺 琺珐 琿珲 璉琏 瑣琐 瓊琼
+甌瓯 電电 畫画 暢畅 疇畴 癤疖 療疗 瘧疟 癘疠 瘍疡 瘡疮 瘋疯 皰疱 癢痒 瘂痖 癆痨 痙痉 癒愈 瘓痪 癡痴
+瘞瘗 癱瘫 癮瘾 癟瘪 皚皑 皺皱 盞盏 鹽盐 監监 蓋盖 盜盗 盤盘 瞘眍 眥眦 矚瞩 睜睁 瞞瞒 瞭了 矯矫 礬矾
+礦矿 碼码 磚砖 硯砚 碩硕 確确 礙碍 禮礼 禍祸 禎祯 離离 禿秃 種种 積积 稱称 穢秽 穩稳 穀谷 窮穷 竊窃
+竅窍 窯窑 竄窜 窩窝 窺窥 競竞 筆笔 筍笋 箋笺 籠笼 築筑 籌筹 簽签 簡简 籃篮 籬篱 類类 糧粮 糾纠 紀纪
+紂纣 約约 紅红 紆纡 紇纥 紈纨 紉纫 緯纬 紜纭 純纯 紗纱 納纳 紛纷 紙纸 級级 紋纹 紡纺 紐纽 紓纾 線线
+練练 組组 紳绅 細细 織织 終终 縐绉 絆绊 紹绍 繹绎 經经 綁绑 絨绒 結结 繞绕 繪绘 給给 絢绚 絳绛 絡络
+絕绝 絞绞 統统 綆绠 綃绡 絹绢 綉绣 繡绣 綏绥 繼继 續续 綺绮 緋绯 綽绰 緄绲 綱纲 網网 維维 綿绵 綸纶
+綬绶 綢绸 綜综 綻绽 綠绿 綴缀 緒绪 緙缂 緞缎 締缔 縷缕 編编 緣缘 緩缓 緬缅 縛缚 緻致 縫缝 縮缩 縱纵
+纖纤 繩绳 纜缆 缽钵 罰罚 羅罗 罷罢 羥羟 翹翘 耬耧 聳耸 恥耻 聶聂 職职 聯联 聰聪 肅肃 腸肠 膚肤 腎肾
+腫肿 脹胀 脅胁 膽胆 勝胜 脈脉 朧胧 臉脸 膠胶 腦脑 膿脓 臍脐 膩腻 騰腾 臘腊 臟脏 髒脏 艦舰 艙舱 艱艰
+豔艳 艷艳 藝艺 節节 蕪芜 蘆芦 蘇苏 蘋苹 範范 莖茎 蘢茏 萊莱 蓮莲 莊庄 薈荟 藥药 萵莴 蒼苍 蓀荪 蔭荫
+藍蓝 薦荐 薩萨 蕭萧 薑姜 蘿萝 螢萤 縈萦 蕩荡 蟲虫 蝦虾 雖虽 螞蚂 蠶蚕 蠻蛮 蝸蜗 蠟蜡 衊蔑 銜衔 補补
+襯衬 裝装 襖袄 褲裤 襲袭 見见 觀观 規规 覓觅 視视 覽览 覺觉 覬觊 覡觋 覦觎 覲觐 觸触 訂订 計计 訊讯
+討讨 訓训 記记 講讲 許许 論论 訟讼 設设 訪访 證证 評评 識识 詐诈 訴诉 診诊 詞词 譯译 試试 詩诗 誠诚
+話话 誕诞 詭诡 詢询 該该 詳详 誤误 說说 語语 誘诱 認认 請请 諸诸 讀读 課课 誰谁 調调 諒谅 談谈 謀谋
+謎谜 謊谎 謝谢 謠谣 謙谦 謹谨 譜谱 讓让 讚赞 贊赞 貝贝 負负 貞贞 財财 貢贡 貧贫 貨货 販贩 貪贪 貫贯
+責责 貯贮 貳贰 貴贵 貸贷 費费 賀贺 貿贸 資资 賈贾 賊贼 賄贿 賠赔 賞赏 賜赐 質质 賬账 賭赌 賴赖 賺赚
+購购 賽赛 贈赠 贏赢 趕赶 趙赵 躍跃 蹤踪 踐践 蹺跷 軀躯 車车 軌轨 軒轩 軟软 轉转 輪轮 軸轴 輕轻 載载
+較较 輔辅 輛辆 輝辉 輩辈 輸输 轄辖 辭辞 辯辩 邊边 遼辽 達达 遷迁 過过 邁迈 運运 還还 這这 進进 遠远
+違违 連连 遲迟 適适 選选 遺遗 遙遥 鄧邓 鄭郑 鄰邻 郵邮 醞酝 醬酱 釀酿 釋释 裡里 裏里 鑑鉴 鑒鉴 針针
+釘钉 釣钓 鈔钞 鈍钝 鈴铃 鉛铅 銀银 銅铜 鋁铝 銘铭 鋒锋 鋼钢 錢钱 錦锦 鍵键 鍋锅 鎖锁 鏡镜 鐘钟 鍾钟
+鐵铁 鑰钥 長长 門门 閃闪 閉闭 問问 闖闯 閒闲 閑闲 間间 閘闸 閣阁 閥阀 閱阅 闊阔 闆板 闡阐 闢辟 隊队
+陽阳 陰阴 陣阵 階阶 際际 陸陆 陳陈 險险 隨随 隱隐 隸隶 難难 雛雏 雞鸡 霧雾 靜静 韋韦 韓韩 頁页 頂顶
+項项 順顺 須须 預预 頑顽 頓顿 頒颁 領领 頻频 題题 額额 顏颜 顧顾 風风 颳刮 飄飘 飛飞 飯饭 飲饮 餅饼
+餓饿 館馆 饅馒 馬马 馳驰 駐驻 駕驾 騎骑 騙骗 驅驱 驗验 骯肮 鬆松 魚鱼 魯鲁 鮮鲜 鯨鲸 鳥鸟 鳴鸣 鴨鸭
+鴻鸿 鵝鹅 鷹鹰 麥麦 黃黄 點点 齊齐 齒齿 龍龙 龜龟 穌稣 諜谍 鏈链 遊游 鑽钻 隻只 係系 繫系 麵面 寢寝
+奧奥 噹当 陝陕 黴霉 兇凶 僕仆 鬧闹 蟬蝉 趨趋 飾饰 鏢镖 襪袜 轟轰 淺浅 驢驴 鵬鹏 鶴鹤 鸞鸾 屆届 滅灭
+滷卤 劊刽 啓启 鎮镇 鋪铺 錯错 鍛锻 鏽锈 鑄铸 靂雳 靄霭 韻韵 頸颈 顆颗 顛颠 餵喂 饑饥 驕骄 驛驿 驟骤
+鬍胡 鬚须 魎魉 魘魇 鯊鲨 鱷鳄 鶯莺 鷗鸥 黷黩 鼴鼹 齡龄 龕龛 嬤嬷 孌娈 慶庆 廣广 懲惩 憤愤 蘊蕴 衆众
+裊袅 讎仇 諾诺 謬谬 譚谭 豬猪 貍狸 賢贤 蹟迹 跡迹 遞递 邏逻 鈣钙 鋸锯 錘锤 鍊炼 閩闽 闕阙 隕陨 雋隽
+霽霁 靚靓 鞦秋 韆千 颯飒 餛馄 飩饨 餃饺 餡馅 饒饶 驪骊 騷骚 鬱郁 鴉鸦 鵲鹊 鷄鸡 齣出 僞伪 劄札 囪囱
+塗涂 墻墙 奐奂 奬奖 媧娲 嫻娴 孿孪 屜屉 幟帜 弒弑 彆别 徵征 恆恒 悵怅 惻恻 愛爱 愜惬 愴怆 慮虑 慾欲
+憊惫 懨恹 戧戗 戩戬 捨舍 掄抡 採采 揹背 摑掴 撚捻 擻擞 斂敛 暘旸 曄晔 榮荣 灕漓 滾滚 漿浆 潛潜 澆浇
+濛蒙 瀕濒 煬炀 熒荧 燁烨 犢犊 猙狰 瑤瑶 甕瓮 癲癫 盃杯 矇蒙 硃朱 碭砀 禪禅 禦御 稅税 穎颖 籤签 粵粤
+糞粪 緊紧 罈坛 羨羡 聞闻 脫脱 莧苋 蔔卜 蕎荞 薺荠 藹蔼 蘚藓 虛虚 虜虏 蠍蝎 衚胡 衹只 袞衮 褸褛 襤褴
+覇霸 訝讶 註注 詛诅 詼诙 誌志 誦诵 諷讽 謁谒 謳讴 譏讥 譴谴 讒谗 豎竖 貼贴 賦赋 賤贱 賸剩 贓赃 贖赎
+踴踊 躊踌 躋跻 躪躏 軋轧 軻轲 輒辄 輿舆 轎轿 迴回 逕迳 週周 郟郏 酈郦 醃腌 釐厘 鈕钮 鉤钩 銷销 鋤锄
+鋭锐 銳锐 錨锚 鍍镀 鎔熔 鏗铿 鐮镰 鑲镶 闌阑 闔阖 陘陉 隴陇 靦腼 頌颂 頰颊 顫颤 飆飙 饞馋 駁驳 駛驶
+駭骇 騁骋 騫骞 驍骁 髏髅 鬢鬓 魷鱿 鮑鲍 鯉鲤 鯽鲫 鰻鳗 鱗鳞 鳩鸠 鴿鸽 鵡鹉 鸚鹦 鹼碱 麩麸 齜龇 龔龚
+鎧铠 閻阎 鵰雕 獃呆 傢家 僱雇 剋克 尅克 佈布 佔占 併并 並并 倖幸 偺咱 傚效 凈净 勦剿 喫吃 囉啰 嚮向
+噁恶 牠它 甦苏 痲麻 皁皂 祕秘 稟禀 箇个 絃弦 綑捆 蒐搜 託托 讌宴 鍼针 鬨哄 鬪斗 麪面 麯曲 麴曲
+`
+
+// traditionalToSimplified 繁体字到简体字的映射
+var traditionalToSimplified = buildTraditionalToSimplified(traditionalSimplifiedPairs)
+
+// buildTraditionalToSimplified 解析对照表，格式不正确的项直接跳过
+func buildTraditionalToSimplified(pairs string) map[rune]rune {
+	mapping := make(map[rune]rune, 1024)
+	for _, pair := range strings.Fields(pairs) {
+		runes := []rune(pair)
+		if len(runes) != 2 || runes[0] == runes[1] {
+			continue
+		}
+		mapping[runes[0]] = runes[1]
+	}
+	return mapping
+}
@@ -0,0 +1,122 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"pansou/util/linkid"
+)
+
+// 视图令牌绑定一组链接，只允许检测这组链接，用于匿名访客在分享页检测搜索结果中的链接
+// 格式：base64url(载荷).base64url(HMAC-SHA256签名)
+// 载荷：8字节过期时间（Unix秒，大端序）+ 每个链接8字节的哈希（按linkid去重键和提取码计算）
+const (
+	viewTokenHashSize = 8
+	viewTokenKeyLabel = "pansou-view-token"
+)
+
+// ViewToken 解析后的视图令牌
+type ViewToken struct {
+	ExpiresAt time.Time
+	links     map[uint64]struct{}
+}
+
+// ViewTokenLink 视图令牌绑定的链接及搜索结果中的提取码
+type ViewTokenLink struct {
+	URL      string
+	Password string
+}
+
+// GenerateViewToken 为一组链接生成视图令牌，返回令牌和过期时间
+func GenerateViewToken(links []ViewTokenLink, secret string, expiry time.Duration) (string, time.Time, error) {
+	if secret == "" {
+		return "", time.Time{}, errors.New("secret cannot be empty")
+	}
+
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+	seen := make(map[uint64]struct{}, len(links))
+	payload := make([]byte, 8, 8+len(links)*viewTokenHashSize)
+	binary.BigEndian.PutUint64(payload, uint64(expiresAt.Unix()))
+	for _, link := range links {
+		hash := viewTokenLinkHash(link.URL, link.Password)
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		payload = binary.BigEndian.AppendUint64(payload, hash)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signViewToken(payload, secret))
+	return token, expiresAt, nil
+}
+
+// ParseViewToken 校验视图令牌的签名和有效期
+func ParseViewToken(token string, secret string) (*ViewToken, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+	if secret == "" {
+		return nil, errors.New("secret cannot be empty")
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errors.New("invalid token format")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) < 8 || (len(payload)-8)%viewTokenHashSize != 0 {
+		return nil, errors.New("invalid token payload")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, signViewToken(payload, secret)) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+	if time.Now().After(expiresAt) {
+		return nil, errors.New("token expired")
+	}
+
+	viewToken := &ViewToken{
+		ExpiresAt: expiresAt,
+		links:     make(map[uint64]struct{}, (len(payload)-8)/viewTokenHashSize),
+	}
+	for offset := 8; offset < len(payload); offset += viewTokenHashSize {
+		viewToken.links[binary.BigEndian.Uint64(payload[offset:])] = struct{}{}
+	}
+	return viewToken, nil
+}
+
+// Allows 判断链接和提取码是否属于令牌绑定的链接集合
+// 提取码也参与校验，持有令牌只能使用搜索结果中的提取码检测，不能借此尝试其他提取码
+func (t *ViewToken) Allows(url, password string) bool {
+	_, ok := t.links[viewTokenLinkHash(url, password)]
+	return ok
+}
+
+// viewTokenLinkHash 计算链接和提取码的哈希，同一分享的不同写法得到相同结果
+// 链接中携带提取码时优先使用链接中的提取码，与链接检测使用的提取码一致
+func viewTokenLinkHash(url, password string) uint64 {
+	key := linkid.DedupKey(url) + "|" + strings.TrimSpace(password)
+	if id, ok := linkid.ParseWithPassword(url, password); ok {
+		key = id.Key() + "|" + id.Password
+	}
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:viewTokenHashSize])
+}
+
+// signViewToken 计算载荷签名，签名密钥由JWT密钥派生，避免与JWT签名混用
+func signViewToken(payload []byte, secret string) []byte {
+	keyMac := hmac.New(sha256.New, []byte(secret))
+	keyMac.Write([]byte(viewTokenKeyLabel))
+
+	mac := hmac.New(sha256.New, keyMac.Sum(nil))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}